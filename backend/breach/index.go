@@ -0,0 +1,265 @@
+// Lokaler Index kompromittierter Passwörter (Pwned-Passwords-Format)
+// Beantwortet k-Anonymity-Bereichsabfragen über die ersten fünf Hex-Zeichen des SHA-1-Hashes,
+// ohne dass Hashes das Netzwerk verlassen
+//
+// Aufbau der Indexdatei (Big Endian):
+//
+//	Kopf:     "TMBR" | Version (uint32) | Anzahl Einträge (uint64)
+//	Fan-out:  2^20+1 × uint64, Nummer des ersten Eintrags je Präfix (letzter Wert = Anzahl)
+//	Einträge: je 18 Byte SHA-1 ab Byte 2 (das erste Halbbyte gehört zum Präfix) + uint32 Häufigkeit
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Kenndaten des Indexformats
+const (
+	indexMagic   = "TMBR"
+	indexVersion = 1
+	headerSize   = 16
+	prefixBits   = 20
+	prefixCount  = 1 << prefixBits
+	fanoutSize   = (prefixCount + 1) * 8
+	recordSize   = 18 + 4
+	hashHexLen   = sha1.Size * 2
+	// PrefixLength ist die Länge des Hash-Präfixes einer Bereichsabfrage in Hex-Zeichen
+	PrefixLength = prefixBits / 4
+)
+
+// Fehler beim Erstellen und Lesen des Index
+var (
+	ErrInvalidPrefix = errors.New("präfix muss aus fünf Hex-Zeichen bestehen")
+	ErrInvalidIndex  = errors.New("ungültige Indexdatei")
+	ErrInvalidInput  = errors.New("ungültige Hash-Liste")
+)
+
+// Entry ist ein Treffer einer Bereichsabfrage.
+type Entry struct {
+	Suffix string // Restliche 35 Hex-Zeichen des SHA-1-Hashes (Großbuchstaben)
+	Count  uint32 // Häufigkeit in bekannten Datenlecks
+}
+
+// Index ist ein geöffneter Index; Abfragen sind nebenläufig nutzbar.
+type Index struct {
+	file  *os.File
+	count uint64
+}
+
+// Open öffnet eine mit Build erstellte Indexdatei.
+func Open(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(file, header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIndex, err)
+	}
+	if string(header[:4]) != indexMagic || binary.BigEndian.Uint32(header[4:8]) != indexVersion {
+		file.Close()
+		return nil, fmt.Errorf("%w: unbekanntes Format", ErrInvalidIndex)
+	}
+	count := binary.BigEndian.Uint64(header[8:16])
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() != int64(headerSize+fanoutSize)+int64(count)*recordSize {
+		file.Close()
+		return nil, fmt.Errorf("%w: Dateigröße passt nicht zur Anzahl der Einträge", ErrInvalidIndex)
+	}
+
+	return &Index{file: file, count: count}, nil
+}
+
+// Close schließt die Indexdatei.
+func (i *Index) Close() error {
+	return i.file.Close()
+}
+
+// Count liefert die Anzahl der Hashes im Index.
+func (i *Index) Count() uint64 {
+	return i.count
+}
+
+// Range liefert alle Hashes mit dem angegebenen fünfstelligen Hex-Präfix (Groß-/Kleinschreibung egal).
+func (i *Index) Range(prefix string) ([]Entry, error) {
+	value, err := parsePrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := make([]byte, 16)
+	if _, err := i.file.ReadAt(bounds, int64(headerSize)+int64(value)*8); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIndex, err)
+	}
+	first, end := binary.BigEndian.Uint64(bounds[:8]), binary.BigEndian.Uint64(bounds[8:])
+	if first > end || end > i.count {
+		return nil, fmt.Errorf("%w: beschädigte Verteilungstabelle", ErrInvalidIndex)
+	}
+
+	records := make([]byte, (end-first)*recordSize)
+	if _, err := i.file.ReadAt(records, int64(headerSize+fanoutSize)+int64(first)*recordSize); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIndex, err)
+	}
+
+	entries := make([]Entry, 0, end-first)
+	for offset := 0; offset < len(records); offset += recordSize {
+		record := records[offset : offset+recordSize]
+		entries = append(entries, Entry{
+			Suffix: strings.ToUpper(hex.EncodeToString(record[:18]))[1:],
+			Count:  binary.BigEndian.Uint32(record[18:]),
+		})
+	}
+	return entries, nil
+}
+
+// PasswordCount liefert, wie oft ein Passwort in bekannten Datenlecks vorkommt (0 = nicht gefunden).
+func (i *Index) PasswordCount(password string) (uint32, error) {
+	hash := strings.ToUpper(hex.EncodeToString(sha1Sum(password)))
+	entries, err := i.Range(hash[:PrefixLength])
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		if entry.Suffix == hash[PrefixLength:] {
+			return entry.Count, nil
+		}
+	}
+	return 0, nil
+}
+
+// Build erstellt aus einer nach Hash sortierten Liste im Format "SHA1:Häufigkeit" (eine Zeile je Hash,
+// wie im Download "ordered by hash" von Pwned Passwords) eine Indexdatei. Die Datei wird zunächst
+// unter path.tmp geschrieben und erst nach Abschluss umbenannt, ein laufender Server liest also nie
+// einen halbfertigen Index. Gibt die Anzahl der übernommenen Hashes zurück.
+func Build(r io.Reader, path string) (uint64, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	// Platz für Kopf und Verteilungstabelle freihalten, sie werden am Ende geschrieben
+	if _, err := file.Seek(int64(headerSize+fanoutSize), io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	counts := make([]uint64, prefixCount)
+	writer := bufio.NewWriterSize(file, 1<<20)
+	scanner := bufio.NewScanner(r)
+	var previous []byte
+	var total uint64
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		hash, count, err := parseLine(text)
+		if err != nil {
+			return 0, fmt.Errorf("%w: Zeile %d: %v", ErrInvalidInput, line, err)
+		}
+		if previous != nil && string(hash) <= string(previous) {
+			return 0, fmt.Errorf("%w: Zeile %d: Hashes müssen aufsteigend sortiert und eindeutig sein", ErrInvalidInput, line)
+		}
+		previous = hash
+
+		counts[int(hash[0])<<12|int(hash[1])<<4|int(hash[2]>>4)]++
+		if _, err := writer.Write(hash[2:]); err != nil {
+			return 0, err
+		}
+		if err := binary.Write(writer, binary.BigEndian, count); err != nil {
+			return 0, err
+		}
+		total++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if err := writer.Flush(); err != nil {
+		return 0, err
+	}
+
+	// Kopf und kumulierte Verteilungstabelle schreiben
+	head := make([]byte, headerSize+fanoutSize)
+	copy(head, indexMagic)
+	binary.BigEndian.PutUint32(head[4:8], indexVersion)
+	binary.BigEndian.PutUint64(head[8:16], total)
+	var position uint64
+	for prefix, count := range counts {
+		binary.BigEndian.PutUint64(head[headerSize+prefix*8:], position)
+		position += count
+	}
+	binary.BigEndian.PutUint64(head[headerSize+prefixCount*8:], position)
+	if _, err := file.WriteAt(head, 0); err != nil {
+		return 0, err
+	}
+
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// parseLine zerlegt eine Zeile "SHA1:Häufigkeit"; fehlt die Häufigkeit, wird 1 angenommen.
+func parseLine(text string) ([]byte, uint32, error) {
+	hashHex, countText, hasCount := strings.Cut(text, ":")
+	if len(hashHex) != hashHexLen {
+		return nil, 0, errors.New("SHA-1-Hash mit 40 Hex-Zeichen erwartet")
+	}
+	hash, err := hex.DecodeString(hashHex)
+	if err != nil {
+		return nil, 0, errors.New("SHA-1-Hash mit 40 Hex-Zeichen erwartet")
+	}
+	if !hasCount {
+		return hash, 1, nil
+	}
+	count, err := strconv.ParseUint(strings.TrimSpace(countText), 10, 64)
+	if err != nil {
+		return nil, 0, errors.New("ungültige Häufigkeit")
+	}
+	// Sehr große Häufigkeiten werden auf den Höchstwert begrenzt
+	return hash, uint32(min(count, uint64(^uint32(0)))), nil
+}
+
+// parsePrefix wandelt ein fünfstelliges Hex-Präfix in seine Nummer in der Verteilungstabelle um.
+func parsePrefix(prefix string) (int, error) {
+	if len(prefix) != PrefixLength {
+		return 0, ErrInvalidPrefix
+	}
+	value, err := strconv.ParseUint(prefix, 16, prefixBits)
+	if err != nil {
+		return 0, ErrInvalidPrefix
+	}
+	return int(value), nil
+}
+
+// sha1Sum berechnet den SHA-1-Hash eines Passworts (wie in Pwned Passwords).
+func sha1Sum(password string) []byte {
+	sum := sha1.Sum([]byte(password))
+	return sum[:]
+}
@@ -0,0 +1,172 @@
+package breach
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testHashes ist eine kleine, nach Hash sortierte Liste im Pwned-Passwords-Format. Sie deckt das
+// erste und letzte Präfix ab, mehrere Hashes je Präfix und Präfixe, die sich erst im fünften
+// Hex-Zeichen (oberes Halbbyte von hash[2]) unterscheiden.
+var testHashes = []string{
+	"0000000000000000000000000000000000000001:3",
+	"0000000000000000000000000000000000000002:1",
+	"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:52256179", // SHA-1 von "password"
+	"ABCDE00000000000000000000000000000000000:7",
+	"ABCDEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:8",
+	"ABCDF00000000000000000000000000000000000",
+	"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:99999999999",
+}
+
+// buildIndex erstellt einen Index aus den Zeilen in t.TempDir() und öffnet ihn.
+func buildIndex(t *testing.T, lines []string) *Index {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "breach.idx")
+	total, err := Build(strings.NewReader(strings.Join(lines, "\n")), path)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if total != uint64(len(lines)) {
+		t.Fatalf("Build lieferte %d Hashes, erwartet %d", total, len(lines))
+	}
+	index, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { index.Close() })
+	return index
+}
+
+func TestRange(t *testing.T) {
+	index := buildIndex(t, testHashes)
+	if index.Count() != uint64(len(testHashes)) {
+		t.Fatalf("Count = %d, erwartet %d", index.Count(), len(testHashes))
+	}
+
+	tests := []struct {
+		prefix string
+		want   []Entry
+	}{
+		{"00000", []Entry{
+			{Suffix: "00000000000000000000000000000000001", Count: 3},
+			{Suffix: "00000000000000000000000000000000002", Count: 1},
+		}},
+		{"5BAA6", []Entry{{Suffix: "1E4C9B93F3F0682250B6CF8331B7EE68FD8", Count: 52256179}}},
+		{"abcde", []Entry{
+			{Suffix: "00000000000000000000000000000000000", Count: 7},
+			{Suffix: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", Count: 8},
+		}},
+		{"ABCDF", []Entry{{Suffix: "00000000000000000000000000000000000", Count: 1}}}, // Ohne Häufigkeit gilt 1
+		{"ABCD0", []Entry{}},
+		{"ABCE0", []Entry{}},
+		{"FFFFF", []Entry{{Suffix: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", Count: ^uint32(0)}}}, // Auf uint32 begrenzt
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := index.Range(tt.prefix)
+			if err != nil {
+				t.Fatalf("Range(%q): %v", tt.prefix, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Range(%q) = %v, erwartet %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeInvalidPrefix(t *testing.T) {
+	index := buildIndex(t, testHashes)
+	for _, prefix := range []string{"", "ABCD", "ABCDEF", "ABCDG", "-ABCD", "+ABCD", " ABCD"} {
+		if _, err := index.Range(prefix); !errors.Is(err, ErrInvalidPrefix) {
+			t.Errorf("Range(%q) Fehler = %v, erwartet ErrInvalidPrefix", prefix, err)
+		}
+	}
+}
+
+func TestPasswordCount(t *testing.T) {
+	index := buildIndex(t, testHashes)
+	tests := []struct {
+		password string
+		want     uint32
+	}{
+		{"password", 52256179},
+		{"Password", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := index.PasswordCount(tt.password)
+		if err != nil {
+			t.Fatalf("PasswordCount(%q): %v", tt.password, err)
+		}
+		if got != tt.want {
+			t.Errorf("PasswordCount(%q) = %d, erwartet %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestBuildRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"unsortiert", []string{"ABCDE00000000000000000000000000000000000:1", "0000000000000000000000000000000000000001:1"}},
+		{"doppelt", []string{"ABCDE00000000000000000000000000000000000:1", "abcde00000000000000000000000000000000000:2"}},
+		{"Hash zu kurz", []string{"ABCDE0000000000000000000000000000000000:1"}},
+		{"kein Hex", []string{"ABCDEX0000000000000000000000000000000000:1"}},
+		{"ungültige Häufigkeit", []string{"ABCDE00000000000000000000000000000000000:viele"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "breach.idx")
+			_, err := Build(strings.NewReader(strings.Join(tt.lines, "\n")), path)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("Build Fehler = %v, erwartet ErrInvalidInput", err)
+			}
+			// Weder Index noch temporäre Datei dürfen zurückbleiben
+			for _, leftover := range []string{path, path + ".tmp"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s existiert nach fehlgeschlagenem Build", filepath.Base(leftover))
+				}
+			}
+		})
+	}
+}
+
+func TestOpenRejectsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(data []byte) []byte
+	}{
+		{"Datei zu lang", func(data []byte) []byte { return append(data, 0) }},
+		{"Datei zu kurz", func(data []byte) []byte { return data[:len(data)-1] }},
+		{"nur Kopf", func(data []byte) []byte { return data[:headerSize] }},
+		{"Kopf unvollständig", func(data []byte) []byte { return data[:headerSize-1] }},
+		{"falsche Kennung", func(data []byte) []byte { copy(data, "XXXX"); return data }},
+		{"falsche Version", func(data []byte) []byte { data[7] = indexVersion + 1; return data }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "breach.idx")
+			if _, err := Build(strings.NewReader(strings.Join(testHashes, "\n")), path); err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, tt.modify(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if index, err := Open(path); !errors.Is(err, ErrInvalidIndex) {
+				if index != nil {
+					index.Close()
+				}
+				t.Fatalf("Open Fehler = %v, erwartet ErrInvalidIndex", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"backend/services"
+	"bytes"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AttachmentHandler behandelt Anfragen zu Dateianhängen von Passwort-Einträgen.
+type AttachmentHandler struct {
+	AttachmentService *services.AttachmentService // Dienst für Anhangoperationen
+}
+
+// NewAttachmentHandler erstellt eine neue AttachmentHandler-Instanz.
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{AttachmentService: attachmentService}
+}
+
+// UploadAttachment verarbeitet den Upload eines verschlüsselten Anhangs.
+// Der Chiffretext wird als Anfragekörper (Content-Type: application/octet-stream) gestreamt,
+// die Metadaten kommen aus den Headern.
+func (h *AttachmentHandler) UploadAttachment(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	// Ohne Content-Length kann weder Kontingent noch Größe vorab geprüft werden
+	size := int64(c.Request().Header.ContentLength())
+	if size <= 0 {
+		return Problem(c, errLengthRequired, "")
+	}
+
+	req := schemas.AttachmentUploadRequest{
+		EncryptedFileName: c.Get("X-Attachment-Name"),
+		FileNameIV:        c.Get("X-Attachment-Name-IV"),
+		FileNameTag:       c.Get("X-Attachment-Name-Tag"),
+		Size:              size,
+		SHA256:            c.Get("X-Content-SHA256"),
+	}
+
+	// Anfragekörper streamen statt ihn vollständig in den Speicher zu laden
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	attachment, err := h.AttachmentService.Upload(c.UserContext(), userID, uint(passwordID), &req, body)
+	if err != nil {
+		// Zu große Anhänge → 413, überschrittenes Kontingent → 507
+		return Problem(c, err, "Fehler beim Hochladen des Anhangs")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toAttachmentResponse(attachment))
+}
+
+// GetAttachments verarbeitet das Abrufen der Anhang-Metadaten eines Passwort-Eintrags.
+func (h *AttachmentHandler) GetAttachments(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	attachments, err := h.AttachmentService.GetAttachments(userID, uint(passwordID))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen der Anhänge")
+	}
+
+	response := []schemas.AttachmentResponse{}
+	for i := range attachments {
+		response = append(response, toAttachmentResponse(&attachments[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// DownloadAttachment streamt den Chiffretext eines Anhangs zum Client.
+// Der Client prüft die Integrität anhand des Headers X-Content-SHA256.
+func (h *AttachmentHandler) DownloadAttachment(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	passwordID, attachmentID, err := parseAttachmentParams(c)
+	if err != nil {
+		return Problem(c, errInvalidAttachmentID, "")
+	}
+
+	attachment, reader, err := h.AttachmentService.OpenAttachment(c.UserContext(), userID, passwordID, attachmentID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen des Anhangs")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Set("X-Content-SHA256", attachment.SHA256)
+	// Fasthttp schließt den Reader nach dem Senden selbst
+	return c.SendStream(reader, int(attachment.Size))
+}
+
+// DeleteAttachment verarbeitet das Löschen eines Anhangs.
+func (h *AttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	passwordID, attachmentID, err := parseAttachmentParams(c)
+	if err != nil {
+		return Problem(c, errInvalidAttachmentID, "")
+	}
+
+	if err := h.AttachmentService.DeleteAttachment(c.UserContext(), userID, passwordID, attachmentID); err != nil {
+		return Problem(c, err, "Fehler beim Löschen des Anhangs")
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// GetUsage verarbeitet das Abrufen des Speicherverbrauchs für Anhänge.
+func (h *AttachmentHandler) GetUsage(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	used, err := h.AttachmentService.GetUsage(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Berechnen des Speicherverbrauchs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schemas.AttachmentUsageResponse{
+		UsedBytes:    used,
+		QuotaBytes:   h.AttachmentService.QuotaBytes,
+		MaxFileBytes: h.AttachmentService.MaxFileBytes,
+	})
+}
+
+// parseAttachmentParams liest Passwort- und Anhang-ID aus den Routenparametern.
+func parseAttachmentParams(c *fiber.Ctx) (uint, uint, error) {
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	attachmentID, err := strconv.ParseUint(c.Params("attachmentId"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(passwordID), uint(attachmentID), nil
+}
+
+// toAttachmentResponse konvertiert ein Attachment-Modell in das Antwort-Schema.
+func toAttachmentResponse(attachment *models.Attachment) schemas.AttachmentResponse {
+	return schemas.AttachmentResponse{
+		ID:                attachment.ID,
+		PasswordID:        attachment.PasswordID,
+		EncryptedFileName: attachment.EncryptedFileName,
+		FileNameIV:        attachment.FileNameIV,
+		FileNameTag:       attachment.FileNameTag,
+		Size:              attachment.Size,
+		SHA256:            attachment.SHA256,
+		CreatedAt:         attachment.CreatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/i18n"
 	"backend/schemas"
 	"backend/services"
 
@@ -24,33 +25,29 @@ func NewAuthHandler(authService *services.AuthService, emailService *services.Em
 // Register handles user registration requests.
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req schemas.RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+	// Without an explicit locale the new user keeps the language of the registration request
+	if req.Locale == "" {
+		req.Locale = locale(c)
 	}
 
 	user, err := h.AuthService.RegisterUser(&req)
 	if err != nil {
-		// More specific error handling could be added here based on the error type
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		// Duplicates map to 409, policy violations to 400 with every violated rule
+		return Problem(c, err, "Fehler bei der Registrierung")
 	}
 
 	// Verifizierungstoken generieren
 	token, err := h.EmailService.GenerateVerificationToken()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Generieren des Verifizierungstokens",
-		})
+		return Problem(c, err, "Fehler beim Generieren des Verifizierungstokens")
 	}
 
 	// Token in Neon DB speichern
 	if err := h.EmailService.SetVerificationToken(user.ID, token); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Speichern des Verifizierungstokens in der Datenbank",
-		})
+		return Problem(c, err, "Fehler beim Speichern des Verifizierungstokens in der Datenbank")
 	}
 
 	// E-Mail senden (nur wenn DB-Speicherung erfolgreich)
@@ -58,31 +55,63 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		// E-Mail-Fehler ist nicht kritisch - User ist registriert und Token ist in DB
 		// Log den Fehler, aber blockiere die Registrierung nicht
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-			"message": "Registrierung erfolgreich! E-Mail-Versand fehlgeschlagen - bitte verwenden Sie 'E-Mail erneut senden'.",
+			"message":     i18n.T(locale(c), "auth.registered_email_error"),
 			"email_error": true,
 		})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Registrierung erfolgreich! Bitte überprüfen Sie Ihre E-Mails zur Bestätigung.",
+		"message": i18n.T(locale(c), "auth.registered"),
+	})
+}
+
+// BeginMasterPasswordChange handles requests to start a master password change.
+// It snapshots the vault before the client re-encrypts it with the new key.
+func (h *AuthHandler) BeginMasterPasswordChange(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var req schemas.BeginMasterPasswordChangeRequest
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	// Wrong current password maps to 401, policy violations to 400 before anything is changed
+	snapshot, err := h.AuthService.BeginMasterPasswordChange(userID, &req)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Beginn der Änderung des Master-Passworts")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schemas.BeginMasterPasswordChangeResponse{SnapshotID: snapshot.ID})
+}
+
+// ChangeMasterPassword handles requests to complete a master password change after re-encryption.
+func (h *AuthHandler) ChangeMasterPassword(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var req schemas.ChangeMasterPasswordRequest
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	// Wrong current password maps to 401, policy violations to 400 with every violated rule
+	if err := h.AuthService.ChangeMasterPassword(userID, &req); err != nil {
+		return Problem(c, err, "Fehler beim Ändern des Master-Passworts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": i18n.T(locale(c), "auth.master_password_changed"),
 	})
 }
 
 // Login handles user login requests.
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req schemas.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	loginRes, err := h.AuthService.LoginUser(&req)
 	if err != nil {
-		// More specific error handling for invalid credentials vs internal errors
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		// Invalid credentials map to 401, an unverified email address to 403
+		return Problem(c, err, "Fehler bei der Anmeldung")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(loginRes)
@@ -94,7 +123,7 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// For JWT, invalidation often happens on the client by discarding the token.
 	// If a server-side session or token blacklist is used, implement that here.
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Successfully logged out",
+		"message": i18n.T(locale(c), "auth.logged_out"),
 	})
 }
 
@@ -103,13 +132,11 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	if err := h.AuthService.DeleteAccount(userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return Problem(c, err, "Fehler beim Löschen des Kontos")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Account deleted successfully",
+		"message": i18n.T(locale(c), "auth.account_deleted"),
 	})
 }
 
@@ -119,7 +146,7 @@ func (h *AuthHandler) ValidateToken(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Token is valid",
+		"message": i18n.T(locale(c), "auth.token_valid"),
 		"user_id": userID,
 	})
 }
@@ -127,39 +154,31 @@ func (h *AuthHandler) ValidateToken(c *fiber.Ctx) error {
 // VerifyEmail handles email verification requests.
 func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
 	var req schemas.EmailVerificationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	if err := h.EmailService.VerifyEmail(req.Token); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return Problem(c, err, "Fehler beim Verifizieren der E-Mail")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "E-Mail erfolgreich verifiziert",
+		"message": i18n.T(locale(c), "auth.email_verified"),
 	})
 }
 
 // ResendVerificationEmail handles requests to resend verification emails.
 func (h *AuthHandler) ResendVerificationEmail(c *fiber.Ctx) error {
 	var req schemas.ResendVerificationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	if err := h.EmailService.ResendVerificationEmail(req.Email); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return Problem(c, err, "Fehler beim Senden der Verifizierungs-E-Mail")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Verifizierungs-E-Mail wurde erneut gesendet",
+		"message": i18n.T(locale(c), "auth.verification_resent"),
 	})
 }
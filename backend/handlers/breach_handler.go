@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"backend/services"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Mindestanzahl an Zeilen einer aufgefüllten Antwort (Header Add-Padding), plus zufälliger Aufschlag
+const (
+	breachPaddingMin    = 800
+	breachPaddingJitter = 200
+)
+
+// BreachHandler verarbeitet k-Anonymity-Abfragen gegen den lokalen Index kompromittierter Passwörter.
+type BreachHandler struct {
+	BreachService *services.BreachService
+}
+
+// NewBreachHandler erstellt eine neue BreachHandler-Instanz.
+func NewBreachHandler(breachService *services.BreachService) *BreachHandler {
+	return &BreachHandler{BreachService: breachService}
+}
+
+// GetRange verarbeitet die Abfrage aller bekannten Hashes zu einem fünfstelligen SHA-1-Präfix.
+// Die Antwort entspricht der Pwned-Passwords-Range-API ("SUFFIX:HÄUFIGKEIT" je Zeile), damit
+// Web-App, Erweiterung und CLI vorhandene Clients weiterverwenden können. Mit "Add-Padding: true"
+// wird die Antwort mit Zufallseinträgen der Häufigkeit 0 aufgefüllt, um ihre Größe zu verschleiern.
+func (h *BreachHandler) GetRange(c *fiber.Ctx) error {
+	entries, err := h.BreachService.Range(c.Params("sha1prefix"))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abfragen kompromittierter Passwörter")
+	}
+
+	var body strings.Builder
+	for _, entry := range entries {
+		body.WriteString(entry.Suffix)
+		body.WriteByte(':')
+		body.WriteString(strconv.FormatUint(uint64(entry.Count), 10))
+		body.WriteString("\r\n")
+	}
+	if strings.EqualFold(c.Get("Add-Padding"), "true") {
+		if err := writeBreachPadding(&body, len(entries)); err != nil {
+			return Problem(c, err, "Fehler beim Abfragen kompromittierter Passwörter")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.Status(fiber.StatusOK).SendString(body.String())
+}
+
+// writeBreachPadding ergänzt eine Antwort um zufällige Einträge mit Häufigkeit 0.
+func writeBreachPadding(body *strings.Builder, existing int) error {
+	jitter, err := rand.Int(rand.Reader, big.NewInt(breachPaddingJitter))
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 18)
+	for i := existing; i < breachPaddingMin+int(jitter.Int64()); i++ {
+		if _, err := rand.Read(suffix); err != nil {
+			return err
+		}
+		body.WriteString(strings.ToUpper(hex.EncodeToString(suffix))[1:])
+		body.WriteString(":0\r\n")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"backend/services"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header, mit denen Clients Anfragen idempotent machen bzw. eine wiederholte Antwort erkennen
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// errIdempotencyKeyTooLong meldet einen Idempotency-Key über maxIdempotencyKeyLength Zeichen.
+var errIdempotencyKeyTooLong = services.NewError(services.ErrValidation, "idempotency_key_too_long", "Idempotency-Key ist zu lang")
+
+// IdempotencyHandler macht nicht-idempotente Anfragen mit dem Header Idempotency-Key wiederholbar.
+// Er wird als Middleware vor dem eigentlichen Handler registriert und setzt AuthRequired voraus.
+type IdempotencyHandler struct {
+	IdempotencyService *services.IdempotencyService // Dienst für gespeicherte Antworten
+}
+
+// NewIdempotencyHandler erstellt eine neue IdempotencyHandler-Instanz.
+func NewIdempotencyHandler(idempotencyService *services.IdempotencyService) *IdempotencyHandler {
+	return &IdempotencyHandler{IdempotencyService: idempotencyService}
+}
+
+// Handle führt die Anfrage beim ersten Aufruf mit einem Schlüssel aus und speichert die Antwort.
+// Wiederholungen mit demselben Schlüssel und derselben Anfrage erhalten die gespeicherte Antwort
+// (Header Idempotent-Replayed: true). Anfragen ohne Idempotency-Key werden unverändert weitergereicht.
+func (h *IdempotencyHandler) Handle(c *fiber.Ctx) error {
+	key := strings.TrimSpace(c.Get(headerIdempotencyKey))
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return Problem(c, errIdempotencyKeyTooLong, "")
+	}
+
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Schlüssel an die konkrete Anfrage binden, damit er nicht für eine andere wiederverwendet wird
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	hash.Write(c.Body())
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	stored, err := h.IdempotencyService.Begin(userID, key, requestHash)
+	if err != nil {
+		// Wiederverwendeter Key → 422, noch laufende Anfrage → 409
+		return Problem(c, err, "Fehler beim Prüfen des Idempotency-Keys")
+	}
+	if stored != nil {
+		c.Set(headerIdempotentReplayed, "true")
+		if stored.ContentType != "" {
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+		}
+		return c.Status(stored.StatusCode).Send(stored.Body)
+	}
+
+	// Bei Serverfehlern wird der Schlüssel freigegeben, damit der Client es erneut versuchen kann
+	if err := c.Next(); err != nil {
+		h.release(userID, key)
+		return err
+	}
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusInternalServerError {
+		h.release(userID, key)
+		return nil
+	}
+
+	if err := h.IdempotencyService.Complete(userID, key, status, string(c.Response().Header.ContentType()), c.Response().Body()); err != nil {
+		log.Printf("Antwort zum Idempotency-Key konnte nicht gespeichert werden: %v", err)
+		h.release(userID, key)
+	}
+	return nil
+}
+
+// release gibt einen reservierten Schlüssel frei und protokolliert Fehler dabei.
+func (h *IdempotencyHandler) release(userID uint, key string) {
+	if err := h.IdempotencyService.Release(userID, key); err != nil {
+		log.Printf("Idempotency-Key konnte nicht freigegeben werden: %v", err)
+	}
+}
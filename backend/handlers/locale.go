@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"backend/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocaleLoader liest die gespeicherte Sprache des Benutzers. AuthRequired legt ihn in c.Locals("locale") ab,
+// damit die Datenbank nur gefragt wird, wenn eine Antwort ohne unterstützte Accept-Language übersetzt wird.
+type LocaleLoader func() string
+
+// locale ermittelt die Sprache der Antwort: bevorzugte unterstützte Sprache laut Accept-Language,
+// sonst die gespeicherte Sprache des Benutzers (über c.Locals("locale")), sonst die Standardsprache.
+func locale(c *fiber.Ctx) string {
+	if negotiated := i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage)); negotiated != "" {
+		return negotiated
+	}
+	switch stored := c.Locals("locale").(type) {
+	case string:
+		return i18n.Resolve(stored)
+	case LocaleLoader:
+		// Ergebnis merken, damit die Sprache pro Anfrage höchstens einmal gelesen wird
+		loaded := stored()
+		c.Locals("locale", loaded)
+		return i18n.Resolve(loaded)
+	}
+	return i18n.Default
+}
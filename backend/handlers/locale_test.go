@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLocaleLoadsStoredLocaleLazily(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		stored         string
+		want           string
+		wantLoads      int
+	}{
+		{"Accept-Language", "en-US,en;q=0.9", "de", "en", 0},
+		{"gespeicherte Sprache", "", "en", "en", 1},
+		{"nicht unterstützte Accept-Language", "fr-FR", "en", "en", 1},
+		{"gewichtete Accept-Language", "fr, de;q=0.5", "en", "de", 0},
+		{"keine gespeicherte Sprache", "", "", "de", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loads := 0
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				c.Locals("locale", LocaleLoader(func() string {
+					loads++
+					return tt.stored
+				}))
+				// Mehrfache Aufrufe innerhalb einer Anfrage lesen die Sprache höchstens einmal
+				first, second := locale(c), locale(c)
+				if first != second {
+					t.Errorf("locale() = %q, dann %q", first, second)
+				}
+				return c.SendString(first)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("locale() = %q, erwartet %q", body, tt.want)
+			}
+			if loads != tt.wantLoads {
+				t.Errorf("Sprache %d-mal gelesen, erwartet %d", loads, tt.wantLoads)
+			}
+		})
+	}
+}
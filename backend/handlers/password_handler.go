@@ -1,10 +1,19 @@
 package handlers
 
 import (
+	"backend/models"
 	"backend/schemas"
 	"backend/services"
+	"backend/validation"
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -27,124 +36,149 @@ func (h *PasswordHandler) CreatePassword(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	var req schemas.CreatePasswordRequest
-	// Anfragekörper parsen
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültiger Anfragekörper",
-		})
+	// Anfragekörper parsen und prüfen
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	// Passwort-Dienst aufrufen, um das Passwort zu erstellen
-	password, err := h.PasswordService.CreatePassword(userID, &req)
+	password, err := h.PasswordService.CreatePassword(userID, &req, clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Erstellen des Passwort-Eintrags",
-		})
+		return Problem(c, err, "Fehler beim Erstellen des Passwort-Eintrags")
 	}
 
 	// Antwort erstellen
-	response := schemas.PasswordResponse{
-		ID:                password.ID,
-		UserID:            password.UserID,
-		WebsiteURL:        password.WebsiteURL,
-		EncryptedUsername: password.EncryptedUsername,
-		UsernameIV:        password.UsernameIV,
-		UsernameTag:       password.UsernameTag,
-		EncryptedPassword: password.EncryptedPassword,
-		PasswordIV:        password.PasswordIV,
-		PasswordTag:       password.PasswordTag,
-		EncryptedNotes:    password.EncryptedNotes,
-		NotesIV:           password.NotesIV,
-		NotesTag:          password.NotesTag,
-		CreatedAt:         password.CreatedAt,
-		UpdatedAt:         password.UpdatedAt,
-	}
+	response := toPasswordResponse(password)
 
+	setRevisionETag(c, password)
 	return c.Status(fiber.StatusCreated).JSON(response)
 }
 
 // BatchCreatePasswords verarbeitet die Erstellung mehrerer Passwort-Einträge in einem Batch.
+// Die Antwort ordnet jedem Index (und client_ref) die neue Server-ID bzw. einen Fehler zu.
+// Mit dem Header Idempotency-Key kann die Anfrage gefahrlos wiederholt werden.
 func (h *PasswordHandler) BatchCreatePasswords(c *fiber.Ctx) error {
 	// Benutzer-ID aus dem Kontext abrufen
 	userID := c.Locals("userID").(uint)
 
 	var req schemas.BatchCreatePasswordRequest
-	// Anfragekörper parsen
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültiger Anfragekörper für Batch-Erstellung",
-		})
+	// Anfragekörper parsen und prüfen
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	// Batch-Passwörter über den Dienst erstellen
-	passwords, err := h.PasswordService.BatchCreatePasswords(userID, &req)
+	outcomes, applied, err := h.PasswordService.BatchCreatePasswords(userID, &req, clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Batch-Erstellen der Passwörter",
-		})
+		return Problem(c, err, "Fehler beim Batch-Erstellen der Passwörter")
+	}
+
+	return batchResponse(c, req.Mode, outcomes, applied, fiber.StatusCreated, fiber.StatusCreated)
+}
+
+// GetPasswords verarbeitet das Abrufen der Passwort-Einträge für den authentifizierten Benutzer.
+// Die Liste ist paginiert; die Gesamtanzahl steht im Header X-Total-Count, der Cursor der nächsten
+// Seite in X-Next-Cursor. Mit all=true wird wie bisher die vollständige Liste geliefert.
+func (h *PasswordHandler) GetPasswords(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	var query schemas.PasswordListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return Problem(c, errInvalidQuery, "")
+	}
+
+	var passwords []models.Password
+	if query.All {
+		// Ungepaginierte Liste für ältere Clients (optional nach Eintragstyp gefiltert)
+		all, err := h.PasswordService.GetPasswordsByUserID(userID, query.Type)
+		if err != nil {
+			return Problem(c, err, "Fehler beim Abrufen der Passwort-Einträge")
+		}
+		passwords = all
+		c.Set("X-Total-Count", strconv.Itoa(len(passwords)))
+	} else {
+		page, err := h.PasswordService.ListPasswords(userID, &query)
+		if err != nil {
+			return Problem(c, err, "Fehler beim Abrufen der Passwort-Einträge")
+		}
+		passwords = page.Items
+		c.Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+		if page.NextCursor != "" {
+			c.Set("X-Next-Cursor", page.NextCursor)
+		}
 	}
 
 	// Modelle in Antwort-Schemata konvertieren
 	response := []schemas.PasswordResponse{}
 	for _, password := range passwords {
-		response = append(response, schemas.PasswordResponse{
-			ID:                password.ID,
-			UserID:            password.UserID,
-			WebsiteURL:        password.WebsiteURL,
-			EncryptedUsername: password.EncryptedUsername,
-			UsernameIV:        password.UsernameIV,
-			UsernameTag:       password.UsernameTag,
-			EncryptedPassword: password.EncryptedPassword,
-			PasswordIV:        password.PasswordIV,
-			PasswordTag:       password.PasswordTag,
-			EncryptedNotes:    password.EncryptedNotes,
-			NotesIV:           password.NotesIV,
-			NotesTag:          password.NotesTag,
-			CreatedAt:         password.CreatedAt,
-			UpdatedAt:         password.UpdatedAt,
-		})
+		response = append(response, toPasswordResponse(&password))
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(response)
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// GetPasswords verarbeitet das Abrufen aller Passwort-Einträge für den authentifizierten Benutzer.
-func (h *PasswordHandler) GetPasswords(c *fiber.Ctx) error {
+// MatchPasswords verarbeitet die Suche nach Einträgen, die zu einer aufgerufenen URL passen.
+// Die Browser-Erweiterung muss dafür nicht mehr alle Einträge laden und selbst abgleichen.
+// Für verschlüsselt gespeicherte URLs übergibt der Client statt url die Blind-Indizes (domain_index).
+func (h *PasswordHandler) MatchPasswords(c *fiber.Ctx) error {
 	// Benutzer-ID aus dem Kontext abrufen
 	userID := c.Locals("userID").(uint)
 
-	// Passwörter über den Dienst abrufen
-	passwords, err := h.PasswordService.GetPasswordsByUserID(userID)
+	target := &services.NormalizedURL{}
+	var matches []services.PasswordMatch
+	var err error
+	if value := c.Query("domain_index"); value != "" {
+		var indexes []string
+		if indexes, err = services.ParseDomainIndexes(value); err == nil {
+			matches, err = h.PasswordService.MatchPasswordsByIndex(userID, indexes)
+		}
+	} else {
+		target, matches, err = h.PasswordService.MatchPasswords(userID, c.Query("url"))
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Abrufen der Passwort-Einträge",
-		})
+		return Problem(c, err, "Fehler beim Abgleichen der Passwort-Einträge")
 	}
 
-	// Modelle in Antwort-Schemata konvertieren
-	response := []schemas.PasswordResponse{}
-	for _, password := range passwords {
-		response = append(response, schemas.PasswordResponse{
-			ID:                password.ID,
-			UserID:            password.UserID,
-			WebsiteURL:        password.WebsiteURL,
-			EncryptedUsername: password.EncryptedUsername,
-			UsernameIV:        password.UsernameIV,
-			UsernameTag:       password.UsernameTag,
-			EncryptedPassword: password.EncryptedPassword,
-			PasswordIV:        password.PasswordIV,
-			PasswordTag:       password.PasswordTag,
-			EncryptedNotes:    password.EncryptedNotes,
-			NotesIV:           password.NotesIV,
-			NotesTag:          password.NotesTag,
-			CreatedAt:         password.CreatedAt,
-			UpdatedAt:         password.UpdatedAt,
+	response := schemas.PasswordMatchResponse{
+		URL:     target.URL,
+		Host:    target.Host,
+		Domain:  target.Domain,
+		Matches: []schemas.PasswordMatchItem{},
+	}
+	for _, match := range matches {
+		response.Matches = append(response.Matches, schemas.PasswordMatchItem{
+			URI:         match.URI,
+			Match:       match.Match,
+			Specificity: match.Specificity,
+			Item:        toPasswordResponse(&match.Password),
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// MarkPasswordUsed verarbeitet die Meldung, dass ein Eintrag verwendet wurde.
+// Der Zeitpunkt fließt in die Reihenfolge der URL-Abfrage ein.
+func (h *PasswordHandler) MarkPasswordUsed(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID in uint64 konvertieren
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	password, err := h.PasswordService.MarkPasswordUsed(uint(passwordID), userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Speichern der Verwendung")
+	}
+
+	setRevisionETag(c, password)
+	return c.Status(fiber.StatusOK).JSON(toPasswordResponse(password))
+}
+
 // GetPassword verarbeitet das Abrufen eines einzelnen Passwort-Eintrags nach ID.
 func (h *PasswordHandler) GetPassword(c *fiber.Ctx) error {
 	// Benutzer-ID aus dem Kontext abrufen
@@ -155,43 +189,19 @@ func (h *PasswordHandler) GetPassword(c *fiber.Ctx) error {
 	// Passwort-ID in uint64 konvertieren
 	passwordID, err := strconv.ParseUint(passwordIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültige Passwort-ID",
-		})
+		return Problem(c, errInvalidPasswordID, "")
 	}
 
 	// Passwort über den Dienst abrufen
 	password, err := h.PasswordService.GetPasswordByID(uint(passwordID), userID)
 	if err != nil {
-		// Fehlerbehandlung für nicht gefundenen Eintrag
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Passwort-Eintrag nicht gefunden",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Abrufen des Passwort-Eintrags",
-		})
+		return Problem(c, err, "Fehler beim Abrufen des Passwort-Eintrags")
 	}
 
 	// Antwort erstellen
-	response := schemas.PasswordResponse{
-		ID:                password.ID,
-		UserID:            password.UserID,
-		WebsiteURL:        password.WebsiteURL,
-		EncryptedUsername: password.EncryptedUsername,
-		UsernameIV:        password.UsernameIV,
-		UsernameTag:       password.UsernameTag,
-		EncryptedPassword: password.EncryptedPassword,
-		PasswordIV:        password.PasswordIV,
-		PasswordTag:       password.PasswordTag,
-		EncryptedNotes:    password.EncryptedNotes,
-		NotesIV:           password.NotesIV,
-		NotesTag:          password.NotesTag,
-		CreatedAt:         password.CreatedAt,
-		UpdatedAt:         password.UpdatedAt,
-	}
+	response := toPasswordResponse(password)
 
+	setRevisionETag(c, password)
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
@@ -205,54 +215,124 @@ func (h *PasswordHandler) UpdatePassword(c *fiber.Ctx) error {
 	// Passwort-ID in uint64 konvertieren
 	passwordID, err := strconv.ParseUint(passwordIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültige Passwort-ID",
-		})
+		return Problem(c, errInvalidPasswordID, "")
 	}
 
 	var req schemas.UpdatePasswordRequest
-	// Anfragekörper parsen
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültiger Anfragekörper",
-		})
+	// Anfragekörper parsen und prüfen
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	// If-Match hat Vorrang vor expected_revision im Anfragekörper
+	if req.ExpectedRevision, err = expectedRevision(c, req.ExpectedRevision); err != nil {
+		return Problem(c, errInvalidIfMatch, "")
 	}
 
 	// Passwort über den Dienst aktualisieren
-	updatedPassword, err := h.PasswordService.UpdatePassword(uint(passwordID), userID, &req)
+	updatedPassword, err := h.PasswordService.UpdatePassword(uint(passwordID), userID, &req, clientInfo(c))
 	if err != nil {
-		// Fehlerbehandlung für nicht gefundenen Eintrag
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Passwort-Eintrag nicht gefunden",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Aktualisieren des Passwort-Eintrags",
-		})
+		return Problem(c, err, "Fehler beim Aktualisieren des Passwort-Eintrags")
 	}
 
 	// Antwort erstellen
-	response := schemas.PasswordResponse{
-		ID:                updatedPassword.ID,
-		UserID:            updatedPassword.UserID,
-		WebsiteURL:        updatedPassword.WebsiteURL,
-		EncryptedUsername: updatedPassword.EncryptedUsername,
-		UsernameIV:        updatedPassword.UsernameIV,
-		UsernameTag:       updatedPassword.UsernameTag,
-		EncryptedPassword: updatedPassword.EncryptedPassword,
-		PasswordIV:        updatedPassword.PasswordIV,
-		PasswordTag:       updatedPassword.PasswordTag,
-		EncryptedNotes:    updatedPassword.EncryptedNotes,
-		NotesIV:           updatedPassword.NotesIV,
-		NotesTag:          updatedPassword.NotesTag,
-		CreatedAt:         updatedPassword.CreatedAt,
-		UpdatedAt:         updatedPassword.UpdatedAt,
+	response := toPasswordResponse(updatedPassword)
+
+	setRevisionETag(c, updatedPassword)
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetPasswordHistory verarbeitet das Abrufen der früheren Passwörter eines Eintrags.
+func (h *PasswordHandler) GetPasswordHistory(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID in uint64 konvertieren
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	history, err := h.PasswordService.GetPasswordHistory(uint(passwordID), userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen des Passwort-Verlaufs")
+	}
+
+	response := []schemas.PasswordHistoryResponse{}
+	for _, entry := range history {
+		response = append(response, schemas.PasswordHistoryResponse{
+			ID:                entry.ID,
+			EncryptedPassword: entry.EncryptedPassword,
+			PasswordIV:        entry.PasswordIV,
+			PasswordTag:       entry.PasswordTag,
+			ValidFrom:         entry.ValidFrom,
+			ReplacedAt:        entry.CreatedAt,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetPasswordRevisions verarbeitet das Abrufen aller Revisionen eines Eintrags.
+func (h *PasswordHandler) GetPasswordRevisions(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID in uint64 konvertieren
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	revisions, err := h.PasswordService.GetPasswordRevisions(uint(passwordID), userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen der Revisionen")
+	}
+
+	response := []schemas.PasswordRevisionResponse{}
+	for i := range revisions {
+		snapshot, err := services.DecodeRevisionSnapshot(&revisions[i])
+		if err != nil {
+			return Problem(c, err, "Fehler beim Lesen der Revisionen")
+		}
+		response = append(response, schemas.PasswordRevisionResponse{
+			Revision:  revisions[i].Revision,
+			Action:    revisions[i].Action,
+			DeviceID:  revisions[i].DeviceID,
+			UserAgent: revisions[i].UserAgent,
+			IPAddress: revisions[i].IPAddress,
+			CreatedAt: revisions[i].CreatedAt,
+			Item:      *snapshot,
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// RestorePasswordRevision verarbeitet das Zurücksetzen eines Eintrags auf eine frühere Revision.
+func (h *PasswordHandler) RestorePasswordRevision(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID und Revisionsnummer aus den Parametern lesen
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+	revision, err := strconv.Atoi(c.Params("rev"))
+	if err != nil || revision < 1 {
+		return Problem(c, errInvalidRevisionNumber, "")
+	}
+
+	password, err := h.PasswordService.RestorePasswordRevision(uint(passwordID), userID, revision, clientInfo(c))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Wiederherstellen der Revision")
+	}
+
+	setRevisionETag(c, password)
+	return c.Status(fiber.StatusOK).JSON(toPasswordResponse(password))
+}
+
 // DeletePassword verarbeitet das Löschen eines Passwort-Eintrags.
 func (h *PasswordHandler) DeletePassword(c *fiber.Ctx) error {
 	// Benutzer-ID aus dem Kontext abrufen
@@ -263,23 +343,498 @@ func (h *PasswordHandler) DeletePassword(c *fiber.Ctx) error {
 	// Passwort-ID in uint64 konvertieren
 	passwordID, err := strconv.ParseUint(passwordIDStr, 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Ungültige Passwort-ID",
-		})
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	// Erwartete Revision aus If-Match oder dem Query-Parameter expected_revision lesen
+	var fromQuery *int
+	if value := c.Query("expected_revision"); value != "" {
+		revision, err := strconv.Atoi(value)
+		if err != nil {
+			return Problem(c, errInvalidExpectedRevision, "")
+		}
+		fromQuery = &revision
+	}
+	expected, err := expectedRevision(c, fromQuery)
+	if err != nil {
+		return Problem(c, errInvalidIfMatch, "")
 	}
 
 	// Passwort über den Dienst löschen
-	if err := h.PasswordService.DeletePassword(uint(passwordID), userID); err != nil {
-		// Fehlerbehandlung für nicht gefundenen Eintrag
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Passwort-Eintrag nicht gefunden",
-			})
+	if err := h.PasswordService.DeletePassword(uint(passwordID), userID, expected); err != nil {
+		return Problem(c, err, "Fehler beim Löschen des Passwort-Eintrags")
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// BatchUpdatePasswords verarbeitet die Aktualisierung mehrerer Passwort-Einträge in einem Batch.
+// Die Antwort enthält pro Eintrag Status, Fehler bzw. neuen Stand in der Reihenfolge der Anfrage.
+func (h *PasswordHandler) BatchUpdatePasswords(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	var req schemas.BatchUpdatePasswordRequest
+	// Anfragekörper parsen und prüfen
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	outcomes, applied, err := h.PasswordService.BatchUpdatePasswords(userID, &req, clientInfo(c))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Batch-Aktualisieren der Passwörter")
+	}
+
+	return batchResponse(c, req.Mode, outcomes, applied, fiber.StatusOK, fiber.StatusOK)
+}
+
+// BatchDeletePasswords verarbeitet das Löschen mehrerer Passwort-Einträge in einem Batch.
+func (h *PasswordHandler) BatchDeletePasswords(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	var req schemas.BatchDeletePasswordRequest
+	// Anfragekörper parsen und prüfen
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	outcomes, applied, err := h.PasswordService.BatchDeletePasswords(userID, &req)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Batch-Löschen der Passwörter")
+	}
+
+	return batchResponse(c, req.Mode, outcomes, applied, fiber.StatusNoContent, fiber.StatusOK)
+}
+
+// batchResponse erstellt die Antwort einer Batch-Operation. Wurde nichts übernommen (z.B. ein
+// atomarer Batch verworfen), trägt die Antwort den Status des ersten fehlgeschlagenen Eintrags,
+// sonst successStatus. itemStatus ist der Status erfolgreicher Einträge.
+func batchResponse(c *fiber.Ctx, mode string, outcomes []services.BatchItemOutcome, applied bool, itemStatus, successStatus int) error {
+	if mode == "" {
+		mode = services.BatchModeAtomic
+	}
+
+	response := schemas.BatchResultResponse{
+		Mode:    mode,
+		Results: make([]schemas.BatchItemResult, 0, len(outcomes)),
+	}
+	failureStatus := 0
+	lang := locale(c)
+	for i, outcome := range outcomes {
+		result := toBatchItemResult(outcome, itemStatus, lang)
+		result.Index = i
+		if outcome.Err == nil {
+			response.Succeeded++
+		} else {
+			response.Failed++
+			if failureStatus == 0 && !errors.Is(outcome.Err, services.ErrBatchAborted) {
+				failureStatus = result.Status
+			}
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Fehler beim Löschen des Passwort-Eintrags",
+		response.Results = append(response.Results, result)
+	}
+	response.Applied = applied && response.Succeeded > 0
+
+	status := successStatus
+	if !response.Applied && failureStatus != 0 {
+		status = failureStatus
+	}
+	return c.Status(status).JSON(response)
+}
+
+// toBatchItemResult bildet das Ergebnis eines Batch-Eintrags wie eine Einzelanfrage
+// auf Status, Fehlercode und Fehlermeldung (in der Sprache lang) ab.
+func toBatchItemResult(outcome services.BatchItemOutcome, successStatus int, lang string) schemas.BatchItemResult {
+	result := schemas.BatchItemResult{ID: outcome.ID, ClientRef: outcome.ClientRef, Status: successStatus}
+
+	if outcome.Err == nil {
+		if outcome.Password != nil {
+			item := toPasswordResponse(outcome.Password)
+			result.Item = &item
+		}
+		return result
+	}
+
+	result.Status, result.Code, result.Error = classifyError(outcome.Err, "Fehler beim Verarbeiten des Eintrags", lang)
+	var validationErr *validation.Error
+	if errors.As(outcome.Err, &validationErr) {
+		result.Fields = validationErr.Localized(lang)
+	}
+	var conflict *services.RevisionConflictError
+	if errors.As(outcome.Err, &conflict) {
+		current := toPasswordResponse(conflict.Current)
+		result.Current = &current
+	}
+	return result
+}
+
+// ImportPasswords verarbeitet einen Streaming-Import im NDJSON-Format (Content-Type application/x-ndjson,
+// ein Eintrag pro Zeile). Der Anfragekörper wird blockweise gelesen und übernommen; ungültige Zeilen
+// werden mit Zeilennummer gemeldet. Der Fortschritt kann währenddessen über GetImportJobs abgefragt werden.
+func (h *PasswordHandler) ImportPasswords(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), mimeNDJSON) {
+		return Problem(c, errNDJSONRequired, "")
+	}
+
+	// Anfragekörper streamen; die Lese-Frist wird bei jedem Block erneuert
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	} else {
+		body = &deadlineReader{reader: body, conn: c.Context().Conn()}
+	}
+
+	result, err := h.PasswordService.ImportPasswords(userID, body, clientInfo(c))
+	if err != nil && result == nil {
+		return Problem(c, err, "Fehler beim Importieren der Passwörter")
+	}
+
+	response := schemas.ImportResponse{
+		Job:             toImportJobResponse(result.Job),
+		Errors:          []schemas.ImportLineError{},
+		ErrorsTruncated: result.ErrorsTruncated,
+	}
+	lang := locale(c)
+	for _, lineErr := range result.Errors {
+		_, code, detail := classifyError(lineErr.Err, "Fehler beim Importieren der Zeile", lang)
+		response.Errors = append(response.Errors, schemas.ImportLineError{
+			Line:      lineErr.Line,
+			ClientRef: lineErr.ClientRef,
+			Error:     detail,
+			Code:      code,
+		})
+	}
+
+	// Abgebrochene Importe melden den Endstand, damit der Client fortsetzen kann
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, bufio.ErrTooLong) {
+			status = fiber.StatusRequestEntityTooLarge
+		}
+		return c.Status(status).JSON(response)
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetImportJobs verarbeitet das Abrufen der letzten Importe des Benutzers samt Fortschritt.
+func (h *PasswordHandler) GetImportJobs(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	jobs, err := h.PasswordService.GetImportJobs(userID, 20)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen der Importe")
+	}
+
+	response := []schemas.ImportJobResponse{}
+	for i := range jobs {
+		response = append(response, toImportJobResponse(&jobs[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetImportJob verarbeitet das Abrufen des Fortschritts eines einzelnen Imports.
+func (h *PasswordHandler) GetImportJob(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	jobID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidImportID, "")
+	}
+
+	job, err := h.PasswordService.GetImportJob(uint(jobID), userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen des Imports")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toImportJobResponse(job))
+}
+
+// ExportPasswords streamt alle aktiven Einträge als NDJSON (ein PasswordResponse pro Zeile).
+// Die Zeilen können unverändert wieder importiert werden. X-Total-Count enthält die Anzahl der
+// Einträge zu Beginn des Exports; bricht der Export ab, wird die Verbindung geschlossen.
+func (h *PasswordHandler) ExportPasswords(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	total, err := h.PasswordService.CountPasswords(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Exportieren der Passwörter")
+	}
+
+	c.Set(fiber.HeaderContentType, mimeNDJSON)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="vault-export.ndjson"`)
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	conn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder := json.NewEncoder(w)
+		err := h.PasswordService.ExportPasswords(userID, func(page []models.Password) error {
+			// Schreib-Frist pro Seite erneuern, statt den gesamten Export an WriteTimeout zu binden
+			if err := conn.SetWriteDeadline(time.Now().Add(streamIdleTimeout)); err != nil {
+				return err
+			}
+			for i := range page {
+				if err := encoder.Encode(toPasswordResponse(&page[i])); err != nil {
+					return err
+				}
+			}
+			return w.Flush()
+		})
+		if err != nil {
+			// Verbindung abbrechen, damit der Client den unvollständigen Export nicht für vollständig hält
+			log.Printf("Export für Benutzer %d abgebrochen: %v", userID, err)
+			conn.Close()
+		}
+	})
+	return nil
+}
+
+// toImportJobResponse konvertiert ein ImportJob-Modell in das Antwort-Schema.
+func toImportJobResponse(job *models.ImportJob) schemas.ImportJobResponse {
+	return schemas.ImportJobResponse{
+		ID:        job.ID,
+		Status:    job.Status,
+		Processed: job.Processed,
+		Created:   job.Created,
+		Failed:    job.Failed,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
+
+// Sync verarbeitet die Delta-Synchronisation: Liefert die Änderungen seit der Tresor-Revision
+// im Query-Parameter since (ohne since bzw. since=0 den kompletten Tresor) seitenweise (limit).
+// Solange has_more gesetzt ist, ruft der Client die nächste Seite mit next_cursor (Query-Parameter
+// cursor) ab; danach ist cursor das since des nächsten Aufrufs.
+func (h *PasswordHandler) Sync(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	since, err := strconv.ParseInt(c.Query("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		return Problem(c, services.ErrInvalidSyncCursor, "")
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "0"))
+	if err != nil || limit < 0 {
+		return Problem(c, errInvalidQuery, "")
+	}
+
+	changes, err := h.PasswordService.GetChangesSince(userID, since, c.Query("cursor"), limit)
+	if err != nil {
+		return Problem(c, err, "Fehler bei der Synchronisation")
+	}
+
+	response := schemas.SyncResponse{
+		Cursor:     changes.Revision,
+		HasMore:    changes.HasMore,
+		NextCursor: changes.NextCursor,
+		Changed:    []schemas.PasswordResponse{},
+		Deleted:    []schemas.PasswordTombstoneResult{},
+	}
+	for i := range changes.Items {
+		response.Changed = append(response.Changed, toPasswordResponse(&changes.Items[i]))
+	}
+	for _, tombstone := range changes.Tombstones {
+		response.Deleted = append(response.Deleted, schemas.PasswordTombstoneResult{
+			ID:        tombstone.PasswordID,
+			Revision:  tombstone.SyncRevision,
+			DeletedAt: tombstone.CreatedAt,
 		})
 	}
 
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// toPasswordResponse konvertiert ein Password-Modell in das Antwort-Schema.
+func toPasswordResponse(password *models.Password) schemas.PasswordResponse {
+	return schemas.PasswordResponse{
+		ID:                password.ID,
+		UserID:            password.UserID,
+		WebsiteURL:        password.WebsiteURL,
+		EncryptedURL:      password.EncryptedURL,
+		URLIV:             password.URLIV,
+		URLTag:            password.URLTag,
+		DomainIndex:       password.DomainIndex,
+		EncryptedUsername: password.EncryptedUsername,
+		UsernameIV:        password.UsernameIV,
+		UsernameTag:       password.UsernameTag,
+		EncryptedPassword: password.EncryptedPassword,
+		PasswordIV:        password.PasswordIV,
+		PasswordTag:       password.PasswordTag,
+		Fingerprint:       password.Fingerprint,
+		EncryptedNotes:    password.EncryptedNotes,
+		NotesIV:           password.NotesIV,
+		NotesTag:          password.NotesTag,
+		Type:              password.Type,
+		EncryptedName:     password.EncryptedName,
+		NameIV:            password.NameIV,
+		NameTag:           password.NameTag,
+		EncryptedData:     password.EncryptedData,
+		DataIV:            password.DataIV,
+		DataTag:           password.DataTag,
+		DataVersion:       password.DataVersion,
+		Fields:            toCustomFieldResponses(password.Fields),
+		URIs:              toURIResponses(password.URIs),
+		PasswordChangedAt: password.PasswordChangedAt,
+		LastUsedAt:        password.LastUsedAt,
+		Revision:          password.Revision,
+		DeletedAt:         deletedAt(password.DeletedAt),
+		CreatedAt:         password.CreatedAt,
+		UpdatedAt:         password.UpdatedAt,
+	}
+}
+
+// expectedRevision liest die vom Client erwartete Revision aus dem If-Match-Header
+// (z.B. "5" oder W/"5"). Ohne Header bzw. bei "*" wird fallback verwendet.
+func expectedRevision(c *fiber.Ctx, fallback *int) (*int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return fallback, nil
+	}
+	revision, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// setRevisionETag setzt die Revision eines Eintrags als ETag der Antwort.
+func setRevisionETag(c *fiber.Ctx, password *models.Password) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.Itoa(password.Revision)))
+}
+
+// clientInfo ermittelt Gerät und Sitzung einer Anfrage für die Revisionshistorie.
+// Die Geräte-ID wird vom Client im Header X-Device-ID übermittelt.
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	return services.ClientInfo{
+		DeviceID:  c.Get("X-Device-ID"),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
+// deletedAt liefert den Löschzeitpunkt eines Eintrags im Papierkorb oder nil.
+func deletedAt(value gorm.DeletedAt) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// toCustomFieldResponses konvertiert benutzerdefinierte Felder in Antwort-Schemata.
+func toCustomFieldResponses(fields []models.PasswordField) []schemas.CustomFieldResponse {
+	response := []schemas.CustomFieldResponse{}
+	for _, field := range fields {
+		response = append(response, schemas.CustomFieldResponse{
+			ID:             field.ID,
+			Position:       field.Position,
+			Type:           field.Type,
+			EncryptedName:  field.EncryptedName,
+			NameIV:         field.NameIV,
+			NameTag:        field.NameTag,
+			EncryptedValue: field.EncryptedValue,
+			ValueIV:        field.ValueIV,
+			ValueTag:       field.ValueTag,
+			LinkedTo:       field.LinkedTo,
+		})
+	}
+	return response
+}
+
+// GetTrash verarbeitet das Abrufen aller Einträge im Papierkorb.
+func (h *PasswordHandler) GetTrash(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	passwords, err := h.PasswordService.GetTrash(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen des Papierkorbs")
+	}
+
+	// Modelle in Antwort-Schemata konvertieren
+	response := []schemas.PasswordResponse{}
+	for _, password := range passwords {
+		response = append(response, toPasswordResponse(&password))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// RestorePassword verarbeitet das Wiederherstellen eines Eintrags aus dem Papierkorb.
+func (h *PasswordHandler) RestorePassword(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID in uint64 konvertieren
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	password, err := h.PasswordService.RestorePassword(uint(passwordID), userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Wiederherstellen des Passwort-Eintrags")
+	}
+
+	setRevisionETag(c, password)
+	return c.Status(fiber.StatusOK).JSON(toPasswordResponse(password))
+}
+
+// DeletePasswordPermanently verarbeitet das endgültige Löschen eines Eintrags aus dem Papierkorb.
+func (h *PasswordHandler) DeletePasswordPermanently(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	// Passwort-ID in uint64 konvertieren
+	passwordID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidPasswordID, "")
+	}
+
+	if err := h.PasswordService.DeletePasswordPermanently(uint(passwordID), userID); err != nil {
+		return Problem(c, err, "Fehler beim endgültigen Löschen des Passwort-Eintrags")
+	}
+
 	return c.Status(fiber.StatusNoContent).Send(nil)
 }
+
+// EmptyTrash verarbeitet das endgültige Löschen aller Einträge im Papierkorb.
+func (h *PasswordHandler) EmptyTrash(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	deleted, err := h.PasswordService.EmptyTrash(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Leeren des Papierkorbs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"deleted": deleted,
+	})
+}
+
+// toURIResponses konvertiert die URIs eines Eintrags in das Antwort-Schema.
+func toURIResponses(uris []models.PasswordURI) []schemas.URIResponse {
+	response := []schemas.URIResponse{}
+	for _, uri := range uris {
+		response = append(response, schemas.URIResponse{
+			ID:           uri.ID,
+			Position:     uri.Position,
+			URI:          uri.URI,
+			Match:        uri.Match,
+			EncryptedURI: uri.EncryptedURI,
+			URIIV:        uri.URIIV,
+			URITag:       uri.URITag,
+			DomainIndex:  uri.DomainIndex,
+		})
+	}
+	return response
+}
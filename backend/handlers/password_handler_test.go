@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/schemas"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBatchResponse(t *testing.T) {
+	notFound := fmt.Errorf("Passwort mit ID 2 nicht gefunden: %w", services.ErrPasswordNotFound)
+	conflict := &services.RevisionConflictError{Current: &models.Password{ID: 3, Revision: 5}}
+
+	tests := []struct {
+		name          string
+		mode          string
+		outcomes      []services.BatchItemOutcome
+		applied       bool
+		itemStatus    int
+		wantStatus    int
+		wantApplied   bool
+		wantItems     []int
+		wantSucceeded int
+	}{
+		{
+			// Ein verworfener atomarer Batch trägt den Status des fehlgeschlagenen Eintrags
+			name: "atomic verworfen",
+			outcomes: []services.BatchItemOutcome{
+				{ID: 1, Err: services.ErrBatchAborted},
+				{ID: 2, Err: notFound},
+				{ID: 3, Err: services.ErrBatchAborted},
+			},
+			itemStatus: fiber.StatusOK,
+			wantStatus: fiber.StatusNotFound,
+			wantItems:  []int{fiber.StatusFailedDependency, fiber.StatusNotFound, fiber.StatusFailedDependency},
+		},
+		{
+			name:          "atomic übernommen",
+			mode:          services.BatchModeAtomic,
+			outcomes:      []services.BatchItemOutcome{{ID: 1, Password: &models.Password{ID: 1}}, {ID: 2, Password: &models.Password{ID: 2}}},
+			applied:       true,
+			itemStatus:    fiber.StatusOK,
+			wantStatus:    fiber.StatusOK,
+			wantApplied:   true,
+			wantItems:     []int{fiber.StatusOK, fiber.StatusOK},
+			wantSucceeded: 2,
+		},
+		{
+			// Teilweise erfolgreiche Batches gelten als übernommen und melden Fehler nur pro Eintrag
+			name:          "best_effort teilweise",
+			mode:          services.BatchModeBestEffort,
+			outcomes:      []services.BatchItemOutcome{{ID: 1, Password: &models.Password{ID: 1}}, {ID: 2, Err: notFound}, {ID: 3, Err: conflict}},
+			applied:       true,
+			itemStatus:    fiber.StatusOK,
+			wantStatus:    fiber.StatusOK,
+			wantApplied:   true,
+			wantItems:     []int{fiber.StatusOK, fiber.StatusNotFound, fiber.StatusConflict},
+			wantSucceeded: 1,
+		},
+		{
+			name:       "best_effort ohne Erfolg",
+			mode:       services.BatchModeBestEffort,
+			outcomes:   []services.BatchItemOutcome{{ID: 2, Err: notFound}, {ID: 3, Err: conflict}},
+			applied:    true,
+			itemStatus: fiber.StatusOK,
+			wantStatus: fiber.StatusNotFound,
+			wantItems:  []int{fiber.StatusNotFound, fiber.StatusConflict},
+		},
+		{
+			name:          "Löschen",
+			mode:          services.BatchModeBestEffort,
+			outcomes:      []services.BatchItemOutcome{{ID: 1}, {ID: 2}},
+			applied:       true,
+			itemStatus:    fiber.StatusNoContent,
+			wantStatus:    fiber.StatusOK,
+			wantApplied:   true,
+			wantItems:     []int{fiber.StatusNoContent, fiber.StatusNoContent},
+			wantSucceeded: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return batchResponse(c, tt.mode, tt.outcomes, tt.applied, tt.itemStatus, fiber.StatusOK)
+			})
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			var got schemas.BatchResultResponse
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("Antwort %s: %v", body, err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Status = %d, erwartet %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got.Applied != tt.wantApplied || got.Succeeded != tt.wantSucceeded || got.Failed != len(tt.outcomes)-tt.wantSucceeded {
+				t.Errorf("applied/succeeded/failed = %v/%d/%d, erwartet %v/%d/%d",
+					got.Applied, got.Succeeded, got.Failed, tt.wantApplied, tt.wantSucceeded, len(tt.outcomes)-tt.wantSucceeded)
+			}
+			if got.Mode == "" {
+				t.Errorf("mode fehlt")
+			}
+			if len(got.Results) != len(tt.wantItems) {
+				t.Fatalf("%d Ergebnisse, erwartet %d", len(got.Results), len(tt.wantItems))
+			}
+			for i, result := range got.Results {
+				if result.Index != i || result.ID != tt.outcomes[i].ID || result.Status != tt.wantItems[i] {
+					t.Errorf("Ergebnis %d = {index: %d, id: %d, status: %d}, erwartet {%d, %d, %d}",
+						i, result.Index, result.ID, result.Status, i, tt.outcomes[i].ID, tt.wantItems[i])
+				}
+				if failed := tt.outcomes[i].Err != nil; failed != (result.Code != "") {
+					t.Errorf("Ergebnis %d: code = %q bei Fehler %v", i, result.Code, tt.outcomes[i].Err)
+				}
+				if tt.outcomes[i].Err == conflict && (result.Current == nil || result.Current.Revision != 5) {
+					t.Errorf("Ergebnis %d: aktueller Stand fehlt beim Revisionskonflikt", i)
+				}
+			}
+		})
+	}
+}
+
+func TestTrashRejectsInvalidID(t *testing.T) {
+	// Ungültige IDs werden abgelehnt, bevor der Service aufgerufen wird
+	h := &PasswordHandler{}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uint(1))
+		return c.Next()
+	})
+	app.Post("/trash/:id/restore", h.RestorePassword)
+	app.Delete("/trash/:id", h.DeletePasswordPermanently)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{fiber.MethodPost, "/trash/abc/restore"},
+		{fiber.MethodPost, "/trash/-1/restore"},
+		{fiber.MethodDelete, "/trash/abc"},
+		{fiber.MethodDelete, "/trash/1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var problem schemas.Problem
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest || problem.Code != "invalid_password_id" {
+				t.Errorf("Antwort = %d %q, erwartet 400 \"invalid_password_id\"", resp.StatusCode, problem.Code)
+			}
+		})
+	}
+}
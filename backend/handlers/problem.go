@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"backend/i18n"
+	"backend/schemas"
+	"backend/services"
+	"backend/validation"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+)
+
+// Content-Type von Fehlerantworten nach RFC 7807
+const mimeProblemJSON = "application/problem+json"
+
+// Fehlercodes, die keinem Domänenfehler der Dienste entsprechen
+const (
+	codeValidationFailed = "validation_failed"
+	codeNotFound         = "not_found"
+	codeInternalError    = "internal_error"
+)
+
+// Fehler beim Lesen von Pfad-, Query- und Header-Parametern (jeweils mit eigenem Code, siehe services.Error.Is)
+var (
+	errInvalidPasswordID       = services.NewError(services.ErrValidation, "invalid_password_id", "Ungültige Passwort-ID")
+	errInvalidAttachmentID     = services.NewError(services.ErrValidation, "invalid_attachment_id", "Ungültige Passwort- oder Anhang-ID")
+	errInvalidSnapshotID       = services.NewError(services.ErrValidation, "invalid_snapshot_id", "Ungültige Snapshot-ID")
+	errInvalidImportID         = services.NewError(services.ErrValidation, "invalid_import_id", "Ungültige Import-ID")
+	errInvalidRevisionNumber   = services.NewError(services.ErrValidation, "invalid_revision_number", "Ungültige Revisionsnummer")
+	errInvalidExpectedRevision = services.NewError(services.ErrValidation, "invalid_expected_revision", "Ungültige erwartete Revision")
+	errInvalidIfMatch          = services.NewError(services.ErrValidation, "invalid_if_match", "Ungültiger If-Match-Header")
+	errInvalidQuery            = services.NewError(services.ErrValidation, "invalid_query", "Ungültige Query-Parameter")
+)
+
+// kindStatus ordnet den Fehlerarten der Dienste ihren HTTP-Status zu.
+var kindStatus = []struct {
+	kind   error
+	status int
+}{
+	{services.ErrNotFound, fiber.StatusNotFound},
+	{services.ErrConflict, fiber.StatusConflict},
+	{services.ErrUnauthorized, fiber.StatusUnauthorized},
+	{services.ErrValidation, fiber.StatusBadRequest},
+	{services.ErrRateLimited, fiber.StatusTooManyRequests},
+	{services.ErrUnavailable, fiber.StatusServiceUnavailable},
+}
+
+// statusOverrides sind Fehler, deren HTTP-Status genauer ist als der Status ihrer Fehlerart.
+var statusOverrides = []struct {
+	err    error
+	status int
+}{
+	{errUnsupportedContentType, fiber.StatusUnsupportedMediaType},
+	{ErrBodyTooLarge, fiber.StatusRequestEntityTooLarge},
+	{errLengthRequired, fiber.StatusLengthRequired},
+	{services.ErrAttachmentTooLarge, fiber.StatusRequestEntityTooLarge},
+	{services.ErrAttachmentQuotaExceeded, fiber.StatusInsufficientStorage},
+	{services.ErrBackupSignature, fiber.StatusUnprocessableEntity},
+	{services.ErrIdempotencyKeyReused, fiber.StatusUnprocessableEntity},
+	{services.ErrBatchAborted, fiber.StatusFailedDependency},
+	{services.ErrEmailNotVerified, fiber.StatusForbidden},
+	{services.ErrFullResyncRequired, fiber.StatusGone},
+}
+
+// Problem bildet einen Fehler zentral auf eine application/problem+json-Antwort ab.
+// Domänenfehler der Dienste liefern Status (über ihre Fehlerart) und Code; unbekannte Fehler
+// werden protokolliert und als 500 mit der Beschreibung fallback gemeldet, ohne interne Details.
+// Beschreibungen werden in die Sprache der Anfrage übersetzt (siehe locale).
+func Problem(c *fiber.Ctx, err error, fallback string) error {
+	lang := locale(c)
+	status, code, detail := classifyError(err, fallback, lang)
+	if status >= fiber.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+	}
+
+	problem := schemas.Problem{
+		Type:     "about:blank",
+		Title:    utils.StatusMessage(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Path(),
+		Code:     code,
+		Error:    detail,
+	}
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		problem.Fields = validationErr.Localized(lang)
+	}
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		problem.Violations = policyErr.Localized(lang)
+	}
+	var conflict *services.RevisionConflictError
+	if errors.As(err, &conflict) {
+		// Aktuellen Stand mitsenden, damit der Client zusammenführen und erneut senden kann
+		current := toPasswordResponse(conflict.Current)
+		problem.Current = &current
+		setRevisionETag(c, conflict.Current)
+	}
+
+	c.Set(fiber.HeaderContentLanguage, lang)
+	return c.Status(status).JSON(problem, mimeProblemJSON)
+}
+
+// classifyError ermittelt HTTP-Status, Fehlercode und Beschreibung eines Fehlers in der Sprache lang.
+// Bei Eingabefehlern enthält die deutsche Beschreibung den vollständigen Fehlertext samt Kontext,
+// sonst nur die Beschreibung des Domänenfehlers.
+func classifyError(err error, fallback, lang string) (int, string, string) {
+	var domainErr *services.Error
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &domainErr):
+		status := fiber.StatusInternalServerError
+		for _, entry := range kindStatus {
+			if errors.Is(domainErr.Kind, entry.kind) {
+				status = entry.status
+				break
+			}
+		}
+		for _, override := range statusOverrides {
+			if errors.Is(err, override.err) {
+				status = override.status
+				break
+			}
+		}
+		detail := domainErr.Message
+		if errors.Is(err, services.ErrValidation) {
+			detail = err.Error()
+		}
+		return status, domainErr.Code, localizeDetail(lang, domainErr.Code, detail)
+	case errors.Is(err, validation.ErrInvalidRequest):
+		var validationErr *validation.Error
+		if errors.As(err, &validationErr) {
+			return fiber.StatusBadRequest, codeValidationFailed, validationErr.In(lang)
+		}
+		return fiber.StatusBadRequest, codeValidationFailed, localizeDetail(lang, codeValidationFailed, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound, codeNotFound, localizeDetail(lang, codeNotFound, "Ressource nicht gefunden")
+	case errors.As(err, &fiberErr):
+		// Fehler von Fiber selbst (z.B. unbekannte Route, Methode nicht erlaubt)
+		code := statusCode(fiberErr.Code)
+		return fiberErr.Code, code, localizeDetail(lang, code, fiberErr.Message)
+	}
+	if fallback == "" {
+		fallback = utils.StatusMessage(fiber.StatusInternalServerError)
+	}
+	return fiber.StatusInternalServerError, codeInternalError, localizeDetail(lang, codeInternalError, fallback)
+}
+
+// localizeDetail übersetzt die Beschreibung eines Fehlers anhand seines Codes. Die deutschen Texte im
+// Code sind die Quelle; ohne Übersetzung im Katalog bleibt source (samt Kontext) erhalten.
+func localizeDetail(lang, code, source string) string {
+	if text, ok := i18n.Lookup(lang, "error."+code); ok {
+		return text
+	}
+	return source
+}
+
+// statusCode leitet einen Fehlercode aus dem HTTP-Status ab ("Method Not Allowed" → "method_not_allowed").
+func statusCode(status int) string {
+	if status >= fiber.StatusInternalServerError {
+		return codeInternalError
+	}
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"backend/schemas"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReportHandler verarbeitet Anfragen zu Berichten über den Zustand des Tresors.
+type ReportHandler struct {
+	ReportService *services.ReportService
+}
+
+// NewReportHandler erstellt eine neue ReportHandler-Instanz.
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{ReportService: reportService}
+}
+
+// GetReuseReport verarbeitet das Abrufen der Gruppen von Einträgen mit wiederverwendetem Passwort.
+func (h *ReportHandler) GetReuseReport(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	report, err := h.ReportService.GetReuseReport(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Erstellen des Berichts")
+	}
+
+	response := schemas.ReuseReportResponse{
+		Groups:         []schemas.ReuseGroupResponse{},
+		CheckedItems:   report.Checked,
+		UncheckedItems: report.Unchecked,
+	}
+	for _, group := range report.Groups {
+		response.Groups = append(response.Groups, schemas.ReuseGroupResponse{
+			ItemIDs: group,
+			Count:   len(group),
+		})
+		response.ReusedItems += len(group)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetAgeReport verarbeitet das Abrufen des Passwort-Altersberichts.
+// Optionaler Query-Parameter max_age_days (Standard: 365) legt fest, ab wann ein Passwort veraltet ist.
+func (h *ReportHandler) GetAgeReport(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	report, err := h.ReportService.GetAgeReport(userID, c.QueryInt("max_age_days"))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Erstellen des Berichts")
+	}
+
+	response := schemas.AgeReportResponse{
+		MaxAgeDays:   report.MaxAgeDays,
+		Buckets:      []schemas.AgeBucketResponse{},
+		UnknownItems: report.Unknown,
+		StaleItems:   []schemas.StaleItemResponse{},
+	}
+	for _, bucket := range report.Buckets {
+		response.Buckets = append(response.Buckets, schemas.AgeBucketResponse{
+			Label:   bucket.Label,
+			MinDays: bucket.MinDays,
+			Count:   bucket.Count,
+		})
+	}
+	for _, item := range report.Stale {
+		response.StaleItems = append(response.StaleItems, schemas.StaleItemResponse{
+			ID:                item.ID,
+			PasswordChangedAt: item.PasswordChangedAt,
+			AgeDays:           item.AgeDays,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
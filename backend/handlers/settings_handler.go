@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"backend/schemas"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SettingsHandler verarbeitet Anfragen zu Einstellungen des URL-Abgleichs.
+type SettingsHandler struct {
+	DomainService *services.DomainService
+}
+
+// NewSettingsHandler erstellt eine neue SettingsHandler-Instanz.
+func NewSettingsHandler(domainService *services.DomainService) *SettingsHandler {
+	return &SettingsHandler{DomainService: domainService}
+}
+
+// GetDomains verarbeitet das Abrufen der globalen und eigenen Gruppen gleichwertiger Domains.
+func (h *SettingsHandler) GetDomains(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	settings, err := h.DomainService.GetDomainSettings(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen der Domain-Gruppen")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toDomainSettingsResponse(settings))
+}
+
+// UpdateDomains verarbeitet das Ersetzen der eigenen Gruppen bzw. der ausgeschlossenen globalen Gruppen.
+func (h *SettingsHandler) UpdateDomains(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	var req schemas.UpdateDomainSettingsRequest
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
+	}
+
+	settings, err := h.DomainService.UpdateDomainSettings(userID, &req)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Speichern der Domain-Gruppen")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toDomainSettingsResponse(settings))
+}
+
+// toDomainSettingsResponse konvertiert die Domain-Einstellungen in das Antwort-Schema.
+func toDomainSettingsResponse(settings *services.DomainSettings) schemas.DomainSettingsResponse {
+	response := schemas.DomainSettingsResponse{
+		GlobalGroups: []schemas.GlobalDomainGroupResponse{},
+		CustomGroups: []schemas.CustomDomainGroupResponse{},
+	}
+	for _, group := range settings.Global {
+		response.GlobalGroups = append(response.GlobalGroups, schemas.GlobalDomainGroupResponse{
+			Key:      group.Key,
+			Domains:  group.Domains,
+			Excluded: settings.Excluded[group.Key],
+		})
+	}
+	for _, group := range settings.Custom {
+		response.CustomGroups = append(response.CustomGroups, schemas.CustomDomainGroupResponse{
+			ID:      group.ID,
+			Domains: group.Domains,
+		})
+	}
+	return response
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"backend/services"
+	"io"
+	"net"
+	"time"
+)
+
+// Inaktivitäts-Timeout für Streaming-Endpunkte. Statt des festen ReadTimeout/WriteTimeout des Servers
+// wird die Frist bei jedem gelesenen bzw. geschriebenen Block erneuert, sodass große Importe und
+// Exporte beliebig lange laufen dürfen, solange Daten fließen
+const streamIdleTimeout = 30 * time.Second
+
+// MIME-Typ für zeilenweise JSON-Daten (ein Objekt pro Zeile)
+const mimeNDJSON = "application/x-ndjson"
+
+// errNDJSONRequired meldet Importe mit anderem Content-Type (415 wie bei JSON-Anfragen).
+var errNDJSONRequired = services.NewError(services.ErrValidation, "unsupported_content_type", "Import erwartet Content-Type "+mimeNDJSON)
+
+// deadlineReader verlängert vor jedem Lesen die Lese-Frist der Verbindung.
+type deadlineReader struct {
+	reader io.Reader
+	conn   net.Conn
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(streamIdleTimeout)); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
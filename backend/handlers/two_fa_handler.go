@@ -1,14 +1,12 @@
 package handlers
 
 import (
+	"backend/i18n"
 	"backend/schemas"
 	"backend/security"
 	"backend/services"
-	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/pquerna/otp/totp"
-	"gorm.io/gorm"
 )
 
 // TwoFAHandler handles 2FA related requests.
@@ -28,11 +26,7 @@ func (h *TwoFAHandler) InitiateSetup(c *fiber.Ctx) error {
 
 	secret, qrCodeBase64, err := h.TwoFAService.GenerateTwoFASecret(userID)
 	if err != nil {
-		// Check if user not found error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to initiate 2FA setup"})
+		return Problem(c, err, "Fehler beim Starten der 2FA-Einrichtung")
 	}
 
 	// Note: Returning the secret and QR code as Base64 image.
@@ -48,33 +42,28 @@ func (h *TwoFAHandler) VerifySetupCode(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	var req schemas.TwoFactorSetupRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	// Verify the code using the secret stored temporarily during InitiateSetup
+	// A setup that was never initiated maps to 400 via the typed service error
 	valid, err := h.TwoFAService.VerifyTwoFACode(userID, req.Code)
 	if err != nil {
-		// Check specific errors like 2FA not enabled
-		if err.Error() == "2FA is not enabled for this user" { // This check relies on the error string from service
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify 2FA code"})
+		return Problem(c, err, "Fehler beim Prüfen des 2FA-Codes")
 	}
 
 	if !valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid 2FA code"})
+		return Problem(c, services.ErrInvalidTwoFACode, "")
 	}
 
 	// If valid, enable 2FA for the user
 	if err := h.TwoFAService.EnableTwoFA(userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to enable 2FA"})
+		return Problem(c, err, "Fehler beim Aktivieren der 2FA")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "2FA enabled successfully",
+		"message": i18n.T(locale(c), "two_fa.enabled"),
 	})
 }
 
@@ -82,38 +71,20 @@ func (h *TwoFAHandler) VerifySetupCode(c *fiber.Ctx) error {
 // This endpoint is called after successful password verification if 2FA is enabled.
 func (h *TwoFAHandler) VerifyLoginCode(c *fiber.Ctx) error {
 	var req schemas.TwoFactorVerifyRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
-	// Retrieve the user by username to get their ID and 2FA secret
-	user, err := h.UserService.GetUserByUsername(req.Username)
+	// Unknown users, users without 2FA and wrong codes are indistinguishable (401) to avoid revealing which users exist
+	user, err := h.TwoFAService.VerifyLoginCode(req.Username, req.Code)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Avoid revealing if user exists or not for security reasons
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid username or code"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error retrieving user"})
-	}
-
-	// Check if 2FA is enabled for this user
-	if !user.TwoFAEnabled || user.TwoFASecret == "" {
-		// This should ideally not happen if frontend logic is correct, but good to handle defensively
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "2FA is not enabled for this user"})
-	}
-
-	// Verify the provided 2FA code
-	valid := totp.Validate(req.Code, user.TwoFASecret)
-	if !valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid username or code"})
+		return Problem(c, err, "Fehler beim Abrufen des Benutzers")
 	}
 
 	// If code is valid, generate and return a JWT token
 	token, err := security.GenerateJWTToken(user.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate token after 2FA"})
+		return Problem(c, err, "Fehler beim Generieren des Tokens nach 2FA")
 	}
 
 	// Return login success response including the token and salt
@@ -123,6 +94,7 @@ func (h *TwoFAHandler) VerifyLoginCode(c *fiber.Ctx) error {
 		Username:     user.Username,
 		TwoFAEnabled: user.TwoFAEnabled,
 		Salt:         user.Salt, // Include salt for client-side decryption
+		Locale:       i18n.Resolve(user.Locale),
 	})
 }
 
@@ -131,12 +103,10 @@ func (h *TwoFAHandler) DisableTwoFA(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	if err := h.TwoFAService.DisableTwoFA(userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to disable 2FA",
-		})
+		return Problem(c, err, "Fehler beim Deaktivieren der 2FA")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "2FA disabled successfully",
+		"message": i18n.T(locale(c), "two_fa.disabled"),
 	})
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/i18n"
 	"backend/schemas"
 	"backend/services"
 
@@ -23,16 +24,21 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 
 	user, err := h.UserService.GetUserByID(userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user profile"})
+		return Problem(c, err, "Fehler beim Abrufen des Benutzerprofils")
+	}
+	if user == nil {
+		return Problem(c, services.ErrUserNotFound, "")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(schemas.UserProfileResponse{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
-		TwoFAEnabled: user.TwoFAEnabled,
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+		TwoFAEnabled:       user.TwoFAEnabled,
+		TrashRetentionDays: user.TrashRetentionDays,
+		Locale:             user.Locale,
 	})
 }
 
@@ -40,22 +46,25 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	var req schemas.UpdateProfileRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if err := bindJSON(c, &req); err != nil {
+		return invalidRequest(c, err)
 	}
 
 	updatedUser, err := h.UserService.UpdateUserProfile(userID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user profile"})
+		// Taken usernames or email addresses map to 409, an invalid retention period to 400
+		return Problem(c, err, "Fehler beim Aktualisieren des Benutzerprofils")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(schemas.UserProfileResponse{
-		ID:           updatedUser.ID,
-		Username:     updatedUser.Username,
-		Email:        updatedUser.Email,
-		CreatedAt:    updatedUser.CreatedAt,
-		UpdatedAt:    updatedUser.UpdatedAt,
-		TwoFAEnabled: updatedUser.TwoFAEnabled,
+		ID:                 updatedUser.ID,
+		Username:           updatedUser.Username,
+		Email:              updatedUser.Email,
+		CreatedAt:          updatedUser.CreatedAt,
+		UpdatedAt:          updatedUser.UpdatedAt,
+		TwoFAEnabled:       updatedUser.TwoFAEnabled,
+		TrashRetentionDays: updatedUser.TrashRetentionDays,
+		Locale:             updatedUser.Locale,
 	})
 }
 
@@ -64,8 +73,8 @@ func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	if err := h.UserService.DeleteUserAccount(userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete account"})
+		return Problem(c, err, "Fehler beim Löschen des Kontos")
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Account deleted successfully"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": i18n.T(locale(c), "auth.account_deleted")})
 }
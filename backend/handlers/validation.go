@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"backend/services"
+	"backend/validation"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Höchstgröße von JSON-Anfragekörpern, entspricht dem Limit der Middleware RequestBodyLimit
+const maxJSONBodyBytes = 4 * 1024 * 1024
+
+// Fehler beim Einlesen von Anfragekörpern. ErrBodyTooLarge und ErrBodyUnreadable werden auch
+// von der Middleware RequestBodyLimit verwendet, damit beide Wege dieselbe Antwort liefern
+var (
+	errUnsupportedContentType = services.NewError(services.ErrValidation, "unsupported_content_type", "Content-Type application/json erforderlich")
+	ErrBodyTooLarge           = services.NewError(services.ErrValidation, "body_too_large", "Anfragekörper ist zu groß")
+	ErrBodyUnreadable         = services.NewError(services.ErrValidation, "unreadable_body", "Anfragekörper konnte nicht gelesen werden")
+	errLengthRequired         = services.NewError(services.ErrValidation, "length_required", "Content-Length ist erforderlich")
+)
+
+// bindJSON liest einen JSON-Anfragekörper streng in req ein (unbekannte Felder werden abgelehnt)
+// und prüft dessen validate-Tags. Nur application/json wird angenommen, damit kein anderer
+// Content-Type die Größenbegrenzung gepufferter Anfragekörper umgeht.
+func bindJSON(c *fiber.Ctx, req interface{}) error {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return errUnsupportedContentType
+	}
+	if c.Request().Header.ContentLength() > maxJSONBodyBytes {
+		return ErrBodyTooLarge
+	}
+	body := c.Body()
+	if len(body) > maxJSONBodyBytes {
+		return ErrBodyTooLarge
+	}
+	if err := validation.DecodeJSON(body, req); err != nil {
+		return err
+	}
+	return validation.Struct(req)
+}
+
+// invalidRequest bildet Fehler von bindJSON auf Problem-Antworten ab: 415 bzw. 413 für
+// Content-Type und Größe, sonst 400 mit den feldbezogenen Fehlern unter "fields".
+func invalidRequest(c *fiber.Ctx, err error) error {
+	return Problem(c, err, "Anfragekörper konnte nicht verarbeitet werden")
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"backend/services"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VaultHandler behandelt Anfragen, die den gesamten Tresor eines Benutzers betreffen.
+type VaultHandler struct {
+	SnapshotService *services.SnapshotService // Dienst für Tresor-Snapshots
+	PasswordService *services.PasswordService // Dienst für das Zurücksetzen der Einträge und Backups
+	MaxBackupBytes  int64                     // Maximale Größe einer hochgeladenen Backup-Datei
+}
+
+// MIME-Typ von Backup-Dateien. Uploads mit diesem Typ werden gestreamt und vom Handler selbst begrenzt
+const MIMEAccountBackup = "application/vnd.trustme-backup+json"
+
+// Header mit dem vom Client abgeleiteten Signaturschlüssel (Base64) für Backups
+const headerBackupKey = "X-Backup-Key"
+
+// Header mit dem Master-Passwort (Base64), unter dem ein wiederherzustellendes Backup erstellt wurde
+const headerBackupMasterPassword = "X-Backup-Master-Password"
+
+// Fehler beim Einlesen hochgeladener Backup-Dateien
+var (
+	errBackupUnreadable = services.NewError(services.ErrValidation, "unreadable_body", "Backup-Datei konnte nicht gelesen werden")
+	errBackupTooLarge   = services.NewError(services.ErrValidation, "body_too_large", "Backup-Datei ist zu groß")
+)
+
+// NewVaultHandler erstellt eine neue VaultHandler-Instanz.
+func NewVaultHandler(snapshotService *services.SnapshotService, passwordService *services.PasswordService, maxBackupBytes int64) *VaultHandler {
+	return &VaultHandler{SnapshotService: snapshotService, PasswordService: passwordService, MaxBackupBytes: maxBackupBytes}
+}
+
+// GetSnapshots verarbeitet das Abrufen aller Snapshots des authentifizierten Benutzers.
+func (h *VaultHandler) GetSnapshots(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	snapshots, err := h.SnapshotService.GetSnapshots(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Abrufen der Snapshots")
+	}
+
+	response := []schemas.VaultSnapshotResponse{}
+	for i := range snapshots {
+		response = append(response, toVaultSnapshotResponse(&snapshots[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// CreateSnapshot verarbeitet das Anlegen eines Snapshots auf Anfrage des Benutzers.
+func (h *VaultHandler) CreateSnapshot(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	snapshot, err := h.SnapshotService.CreateSnapshot(userID)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Anlegen des Snapshots")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toVaultSnapshotResponse(snapshot))
+}
+
+// RollbackToSnapshot verarbeitet das atomare Zurücksetzen des Tresors auf einen Snapshot.
+func (h *VaultHandler) RollbackToSnapshot(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	snapshotID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return Problem(c, errInvalidSnapshotID, "")
+	}
+
+	restored, backup, err := h.PasswordService.RollbackVault(userID, uint(snapshotID), clientInfo(c))
+	if err != nil {
+		return Problem(c, err, "Fehler beim Zurücksetzen des Tresors")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schemas.VaultRollbackResponse{
+		RestoredItems:  restored,
+		BackupSnapshot: toVaultSnapshotResponse(backup),
+	})
+}
+
+// ExportBackup verarbeitet den Download eines signierten Backups des gesamten Kontos.
+// Der Signaturschlüssel wird im Header X-Backup-Key übermittelt und nicht gespeichert.
+func (h *VaultHandler) ExportBackup(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	key, err := backupKey(c)
+	if err != nil {
+		return Problem(c, err, "")
+	}
+
+	backup, err := h.PasswordService.ExportAccountBackup(userID, key)
+	if err != nil {
+		return Problem(c, err, "Fehler beim Erstellen des Backups")
+	}
+
+	filename := "trustme-backup-" + time.Now().UTC().Format("2006-01-02") + ".json"
+	c.Set(fiber.HeaderContentType, MIMEAccountBackup)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Status(fiber.StatusOK).Send(backup)
+}
+
+// RestoreBackup verarbeitet die Wiederherstellung eines Backups in das (leere) Konto des Benutzers.
+// Die Datei wird als Anfragekörper gesendet, der Signaturschlüssel im Header X-Backup-Key und das
+// Master-Passwort des Backups im Header X-Backup-Master-Password. Danach gilt dieses Master-Passwort.
+func (h *VaultHandler) RestoreBackup(c *fiber.Ctx) error {
+	// Benutzer-ID aus dem Kontext abrufen
+	userID := c.Locals("userID").(uint)
+
+	key, err := backupKey(c)
+	if err != nil {
+		return Problem(c, err, "")
+	}
+
+	masterPassword, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(c.Get(headerBackupMasterPassword)), "="))
+	if err != nil {
+		return Problem(c, services.ErrBackupMasterPassword.Wrap(err), "")
+	}
+
+	// Backups können größer als das allgemeine Limit sein und werden daher selbst begrenzt gelesen
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	data, err := io.ReadAll(io.LimitReader(body, h.MaxBackupBytes+1))
+	if err != nil {
+		return Problem(c, errBackupUnreadable, "")
+	}
+	if int64(len(data)) > h.MaxBackupBytes {
+		return Problem(c, errBackupTooLarge, "")
+	}
+
+	result, err := h.PasswordService.RestoreAccountBackup(userID, key, data, string(masterPassword), clientInfo(c))
+	if err != nil {
+		// Ungültige Signatur → 422, falsches Master-Passwort → 401, nicht leeres Konto → 409
+		return Problem(c, err, "Fehler beim Wiederherstellen des Backups")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schemas.AccountBackupRestoreResponse{
+		RestoredItems:      result.RestoredItems,
+		SkippedAttachments: result.SkippedAttachments,
+		IDMap:              result.IDMap,
+		BackupCreatedAt:    result.BackupCreatedAt,
+		SourceUsername:     result.SourceUsername,
+	})
+}
+
+// backupKey dekodiert den Signaturschlüssel aus dem Header X-Backup-Key (Base64, mit oder ohne Padding).
+func backupKey(c *fiber.Ctx) ([]byte, error) {
+	value := strings.TrimSpace(c.Get(headerBackupKey))
+	if value == "" {
+		return nil, services.ErrInvalidBackupKey
+	}
+	key, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, services.ErrInvalidBackupKey.Wrap(err)
+	}
+	return key, nil
+}
+
+// toVaultSnapshotResponse konvertiert ein VaultSnapshot-Modell in das Antwort-Schema.
+func toVaultSnapshotResponse(snapshot *models.VaultSnapshot) schemas.VaultSnapshotResponse {
+	return schemas.VaultSnapshotResponse{
+		ID:        snapshot.ID,
+		Reason:    snapshot.Reason,
+		ItemCount: snapshot.ItemCount,
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
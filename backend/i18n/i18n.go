@@ -0,0 +1,121 @@
+// Nachrichtenkatalog für API-Meldungen und E-Mails
+// Die Sprache wird über Accept-Language bzw. die gespeicherte Sprache des Benutzers gewählt;
+// fehlende Übersetzungen fallen auf die Standardsprache zurück
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unterstützte Sprachen (Basissprachen nach BCP 47)
+const (
+	German  = "de"
+	English = "en"
+	Default = German // Standardsprache und Quellsprache der Meldungen im Code
+)
+
+// catalogs enthält die Meldungen je Sprache. Schlüssel sind durch Punkte gegliedert
+// (z.B. "email.verification.subject"), Werte sind fmt-Formatstrings.
+var catalogs = map[string]map[string]string{
+	German:  messagesDE,
+	English: messagesEN,
+}
+
+// Normalize bildet ein Sprach-Tag auf eine unterstützte Sprache ab ("en-US" → "en", "de_AT" → "de").
+// Für nicht unterstützte Sprachen wird "" geliefert.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	base, _, _ := strings.Cut(tag, "-")
+	if _, ok := catalogs[base]; ok {
+		return base
+	}
+	return ""
+}
+
+// Negotiate wählt anhand eines Accept-Language-Headers (z.B. "en-US,en;q=0.9,de;q=0.8")
+// die bevorzugte unterstützte Sprache. Passt keine Sprache, wird "" geliefert.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		locale  string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale := Normalize(tag)
+		if locale == "" {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	// Bei gleicher Gewichtung entscheidet die Reihenfolge im Header
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].locale
+}
+
+// Resolve liefert die erste unterstützte Sprache der Kandidaten (z.B. Accept-Language,
+// dann gespeicherte Sprache des Benutzers) und sonst die Standardsprache.
+func Resolve(candidates ...string) string {
+	for _, candidate := range candidates {
+		if locale := Normalize(candidate); locale != "" {
+			return locale
+		}
+	}
+	return Default
+}
+
+// Lookup sucht eine Meldung entlang der Fallback-Kette: angefragte Sprache (bzw. ihre
+// Basissprache), dann Standardsprache.
+func Lookup(locale, key string) (string, bool) {
+	for _, candidate := range []string{Normalize(locale), Default} {
+		if text, ok := catalogs[candidate][key]; ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// T übersetzt eine Meldung und formatiert sie mit args. Fehlt der Schlüssel in allen
+// Katalogen, wird der Schlüssel selbst geliefert.
+func T(locale, key string, args ...interface{}) string {
+	text, ok := Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Message ist eine Meldung, die erst bei der Ausgabe in die Sprache des Empfängers übersetzt wird.
+type Message struct {
+	Key  string        // Schlüssel im Katalog
+	Args []interface{} // Argumente für den Formatstring
+}
+
+// Msg erstellt eine Message.
+func Msg(key string, args ...interface{}) Message {
+	return Message{Key: key, Args: args}
+}
+
+// In übersetzt die Meldung in die Sprache locale.
+func (m Message) In(locale string) string {
+	return T(locale, m.Key, m.Args...)
+}
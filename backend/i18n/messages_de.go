@@ -0,0 +1,59 @@
+package i18n
+
+// messagesDE ist der deutsche Katalog. Fehlermeldungen der Dienste sind auf Deutsch im Code
+// hinterlegt und brauchen daher keinen Eintrag unter "error.<code>".
+var messagesDE = map[string]string{
+	// Rückmeldungen der Handler
+	"auth.registered":              "Registrierung erfolgreich! Bitte überprüfen Sie Ihre E-Mails zur Bestätigung.",
+	"auth.registered_email_error":  "Registrierung erfolgreich! E-Mail-Versand fehlgeschlagen - bitte verwenden Sie 'E-Mail erneut senden'.",
+	"auth.master_password_changed": "Master-Passwort erfolgreich geändert",
+	"auth.logged_out":              "Erfolgreich abgemeldet",
+	"auth.account_deleted":         "Konto erfolgreich gelöscht",
+	"auth.token_valid":             "Token ist gültig",
+	"auth.email_verified":          "E-Mail erfolgreich verifiziert",
+	"auth.verification_resent":     "Verifizierungs-E-Mail wurde erneut gesendet",
+	"two_fa.enabled":               "2FA erfolgreich aktiviert",
+	"two_fa.disabled":              "2FA erfolgreich deaktiviert",
+
+	// Validierung von Anfragekörpern
+	"validation.invalid_request": "Ungültige Anfrage",
+	"validation.required":        "ist erforderlich",
+	"validation.email":           "muss eine gültige E-Mail-Adresse sein",
+	"validation.numeric":         "darf nur Ziffern enthalten",
+	"validation.encrypted":       "ist kein gültiger verschlüsselter Wert: %s",
+	"validation.oneof":           "muss einer der Werte %s sein",
+	"validation.min.string":      "muss mindestens %s Zeichen lang sein",
+	"validation.max.string":      "muss höchstens %s Zeichen lang sein",
+	"validation.len.string":      "muss genau %s Zeichen lang sein",
+	"validation.min.items":       "muss mindestens %s Einträge enthalten",
+	"validation.max.items":       "muss höchstens %s Einträge enthalten",
+	"validation.len.items":       "muss genau %s Einträge enthalten",
+	"validation.min.value":       "muss mindestens %s sein",
+	"validation.max.value":       "muss höchstens %s sein",
+	"validation.len.value":       "muss genau %s sein",
+	"validation.rule":            "verletzt die Regel %s",
+	"validation.type":            "erwartet %s statt %s",
+	"validation.unknown":         "unbekanntes Feld",
+
+	// Richtlinie für Master-Passwörter
+	"policy.min_length":    "Das Master-Passwort muss mindestens %d Zeichen lang sein",
+	"policy.entropy":       "Das Master-Passwort ist zu leicht zu erraten (geschätzt %.0f von mindestens %.0f Bit)",
+	"policy.personal_info": "Das Master-Passwort darf weder Benutzername noch E-Mail-Adresse enthalten",
+	"policy.breached":      "Das Master-Passwort ist aus Datenlecks bekannt",
+
+	// Verifizierungs-E-Mail
+	"email.verification.subject":     "TrustMe - E-Mail-Adresse bestätigen",
+	"email.verification.title":       "E-Mail-Verifizierung",
+	"email.verification.tagline":     "Sicherer Passwort-Manager",
+	"email.verification.greeting":    "Hallo %s!",
+	"email.verification.thanks":      "Vielen Dank für Ihre Registrierung bei TrustMe!",
+	"email.verification.instruction": "Bitte bestätigen Sie Ihre E-Mail-Adresse, indem Sie auf den folgenden Link klicken:",
+	"email.verification.button":      "E-Mail-Adresse bestätigen",
+	"email.verification.copy_link":   "Oder kopieren Sie diesen Link in Ihren Browser:",
+	"email.verification.notice":      "Wichtiger Hinweis:",
+	"email.verification.expiry":      "Dieser Link ist 24 Stunden gültig.",
+	"email.verification.ignore":      "Falls Sie sich nicht bei TrustMe registriert haben, können Sie diese E-Mail ignorieren.",
+	"email.verification.regards":     "Mit freundlichen Grüßen,",
+	"email.verification.team":        "Ihr TrustMe Team",
+	"email.verification.automated":   "Diese E-Mail wurde automatisch generiert. Bitte antworten Sie nicht auf diese E-Mail.",
+}
@@ -0,0 +1,135 @@
+package i18n
+
+// messagesEN ist der englische Katalog. Fehlermeldungen sind unter "error.<code>" nach dem
+// stabilen Fehlercode der Problem-Antworten abgelegt.
+var messagesEN = map[string]string{
+	// Rückmeldungen der Handler
+	"auth.registered":              "Registration successful! Please check your email to confirm your address.",
+	"auth.registered_email_error":  "Registration successful! Sending the email failed - please use 'Resend email'.",
+	"auth.master_password_changed": "Master password changed successfully",
+	"auth.logged_out":              "Successfully logged out",
+	"auth.account_deleted":         "Account deleted successfully",
+	"auth.token_valid":             "Token is valid",
+	"auth.email_verified":          "Email verified successfully",
+	"auth.verification_resent":     "Verification email has been resent",
+	"two_fa.enabled":               "2FA enabled successfully",
+	"two_fa.disabled":              "2FA disabled successfully",
+
+	// Validierung von Anfragekörpern
+	"validation.invalid_request": "Invalid request",
+	"validation.required":        "is required",
+	"validation.email":           "must be a valid email address",
+	"validation.numeric":         "must contain digits only",
+	"validation.encrypted":       "is not a valid encrypted value: %s",
+	"validation.oneof":           "must be one of %s",
+	"validation.min.string":      "must be at least %s characters long",
+	"validation.max.string":      "must be at most %s characters long",
+	"validation.len.string":      "must be exactly %s characters long",
+	"validation.min.items":       "must contain at least %s items",
+	"validation.max.items":       "must contain at most %s items",
+	"validation.len.items":       "must contain exactly %s items",
+	"validation.min.value":       "must be at least %s",
+	"validation.max.value":       "must be at most %s",
+	"validation.len.value":       "must be exactly %s",
+	"validation.rule":            "violates rule %s",
+	"validation.type":            "expected %s instead of %s",
+	"validation.unknown":         "unknown field",
+
+	// Richtlinie für Master-Passwörter
+	"policy.min_length":    "The master password must be at least %d characters long",
+	"policy.entropy":       "The master password is too easy to guess (estimated %.0f of at least %.0f bits)",
+	"policy.personal_info": "The master password must not contain the username or email address",
+	"policy.breached":      "The master password is known from data breaches",
+
+	// Verifizierungs-E-Mail
+	"email.verification.subject":     "TrustMe - Confirm your email address",
+	"email.verification.title":       "Email verification",
+	"email.verification.tagline":     "Secure password manager",
+	"email.verification.greeting":    "Hello %s!",
+	"email.verification.thanks":      "Thank you for registering with TrustMe!",
+	"email.verification.instruction": "Please confirm your email address by clicking the following link:",
+	"email.verification.button":      "Confirm email address",
+	"email.verification.copy_link":   "Or copy this link into your browser:",
+	"email.verification.notice":      "Important:",
+	"email.verification.expiry":      "This link is valid for 24 hours.",
+	"email.verification.ignore":      "If you did not register with TrustMe, you can ignore this email.",
+	"email.verification.regards":     "Kind regards,",
+	"email.verification.team":        "Your TrustMe team",
+	"email.verification.automated":   "This email was generated automatically. Please do not reply to it.",
+
+	// Fehlermeldungen nach Fehlercode
+	"error.validation_failed":               "The request is invalid",
+	"error.not_found":                       "Resource not found",
+	"error.internal_error":                  "An internal error occurred",
+	"error.method_not_allowed":              "Method not allowed",
+	"error.forbidden":                       "Access denied",
+	"error.invalid_password_id":             "Invalid password ID",
+	"error.invalid_attachment_id":           "Invalid password or attachment ID",
+	"error.invalid_snapshot_id":             "Invalid snapshot ID",
+	"error.invalid_import_id":               "Invalid import ID",
+	"error.invalid_revision_number":         "Invalid revision number",
+	"error.invalid_expected_revision":       "Invalid expected revision",
+	"error.invalid_if_match":                "Invalid If-Match header",
+	"error.invalid_query":                   "Invalid query parameters",
+	"error.invalid_sync_cursor":             "Invalid sync cursor",
+	"error.full_resync_required":            "The sync cursor is older than the retained deletions, a full sync (since=0) is required",
+	"error.unsupported_content_type":        "Unsupported Content-Type",
+	"error.body_too_large":                  "Request body is too large",
+	"error.unreadable_body":                 "Request body could not be read",
+	"error.length_required":                 "Content-Length is required",
+	"error.authorization_required":          "Authorization header is required",
+	"error.invalid_authorization_header":    "Invalid Authorization header format. Expected: Bearer <token>",
+	"error.empty_token":                     "Token must not be empty",
+	"error.invalid_token":                   "Invalid or expired token",
+	"error.rate_limited":                    "Too many requests. Please try again later.",
+	"error.user_not_found":                  "User not found",
+	"error.username_taken":                  "Username is already taken",
+	"error.email_taken":                     "Email address is already registered",
+	"error.invalid_credentials":             "Invalid credentials",
+	"error.email_not_verified":              "The email address must be verified before logging in",
+	"error.invalid_current_master_password": "Current master password is incorrect",
+	"error.weak_master_password":            "Master password does not meet the password policy",
+	"error.invalid_change_snapshot":         "The snapshot was not created when starting a master password change",
+	"error.invalid_verification_token":      "Invalid or expired verification token",
+	"error.email_not_registered":            "No user found with this email address",
+	"error.email_already_verified":          "Email address is already verified",
+	"error.two_fa_not_initiated":            "2FA setup has not been started",
+	"error.invalid_two_fa_code":             "Invalid 2FA code",
+	"error.invalid_trash_retention":         "Trash retention must be between 0 and 3650 days",
+	"error.password_not_found":              "Password entry not found",
+	"error.password_not_in_trash":           "Entry not found in trash",
+	"error.revision_not_found":              "Revision not found",
+	"error.revision_conflict":               "The entry has been changed in the meantime",
+	"error.invalid_item_type":               "Unknown item type",
+	"error.missing_item_payload":            "Encrypted payload (encrypted_data, data_iv, data_tag, data_version) is required for this item type",
+	"error.invalid_custom_field":            "Invalid custom field",
+	"error.invalid_uri":                     "Invalid URI",
+	"error.invalid_website_url":             "Invalid website URL",
+	"error.invalid_fingerprint":             "Invalid password fingerprint",
+	"error.invalid_list_query":              "Invalid list query",
+	"error.invalid_match_url":               "Invalid URL",
+	"error.invalid_domain_index":            "Invalid blind index",
+	"error.invalid_domain_group":            "Invalid domain group",
+	"error.invalid_batch":                   "Invalid batch request",
+	"error.batch_aborted":                   "Not applied because another entry of the batch failed",
+	"error.invalid_import_line":             "Line does not contain a valid JSON object",
+	"error.import_not_found":                "Import not found",
+	"error.attachment_not_found":            "Attachment not found",
+	"error.invalid_attachment":              "Encrypted file name (name, IV, tag) and SHA-256 checksum of the attachment are required",
+	"error.attachment_too_large":            "Attachment exceeds the maximum file size",
+	"error.attachment_quota_exceeded":       "Attachment storage quota exceeded",
+	"error.attachment_integrity":            "Attachment checksum does not match the given SHA-256 checksum",
+	"error.snapshot_not_found":              "Snapshot not found",
+	"error.invalid_report_query":            "Invalid report query",
+	"error.invalid_hash_prefix":             "Prefix must consist of five hex characters",
+	"error.breach_index_unavailable":        "No index of compromised passwords configured",
+	"error.idempotency_key_too_long":        "Idempotency key is too long",
+	"error.idempotency_key_reused":          "Idempotency key has already been used for a different request",
+	"error.idempotency_in_progress":         "A request with this idempotency key is still being processed",
+	"error.invalid_backup_key":              "Backup signing key is missing or too short (at least 32 bytes)",
+	"error.invalid_backup":                  "Backup file is invalid",
+	"error.backup_signature_invalid":        "Backup signature is invalid, the file was modified or the key is wrong",
+	"error.unsupported_backup_kdf":          "The backup's key derivation is not supported by this server",
+	"error.invalid_backup_master_password":  "The master password of the backup is incorrect",
+	"error.account_not_empty":               "A backup can only be restored into an empty account",
+}
@@ -4,14 +4,19 @@
 package main
 
 import (
+	"backend/breach"
 	"backend/handlers"
 	"backend/models"
 	"backend/security"
 	"backend/services"
+	"backend/storage"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -67,57 +72,135 @@ func initDB() error {
 
 	DB = db
 
-	// Automatische Tabellen-Migration (User & Password Models)
+	// Automatische Tabellen-Migration (User, Password & abhängige Models)
 	// Erstellt Tabellen nur wenn sie nicht existieren, behält bestehende Daten
-	if err := DB.AutoMigrate(&models.User{}, &models.Password{}); err != nil {
+	if err := DB.AutoMigrate(
+		&models.User{},
+		&models.Password{},
+		&models.PasswordField{},
+		&models.PasswordURI{},
+		&models.PasswordHistory{},
+		&models.PasswordRevision{},
+		&models.VaultSnapshot{},
+		&models.PasswordTombstone{},
+		&models.Attachment{},
+		&models.IdempotencyKey{},
+		&models.ImportJob{},
+		&models.GlobalDomainGroup{},
+		&models.UserDomainGroup{},
+		&models.ExcludedDomainGroup{},
+	); err != nil {
 		log.Printf("Warnung: Migration fehlgeschlagen: %v", err)
 	}
 
 	return nil
 }
 
+// Fehler der Middleware, als application/problem+json gemeldet
+var (
+	errAuthorizationRequired      = services.NewError(services.ErrUnauthorized, "authorization_required", "Authorization-Header ist erforderlich")
+	errInvalidAuthorizationHeader = services.NewError(services.ErrUnauthorized, "invalid_authorization_header", "Ungültiges Format des Authorization-Headers. Erwartet: Bearer <token>")
+	errEmptyToken                 = services.NewError(services.ErrUnauthorized, "empty_token", "Token darf nicht leer sein")
+	errInvalidToken               = services.NewError(services.ErrUnauthorized, "invalid_token", "Ungültiger oder abgelaufener Token")
+	errRateLimited                = services.NewError(services.ErrRateLimited, "rate_limited", "Zu viele Anfragen. Bitte versuchen Sie es später erneut.")
+	errTestUserOnly               = fiber.NewError(fiber.StatusForbidden, "Nur für Testbenutzer erlaubt")
+)
+
 // JWT-Authentifizierungs-Middleware für geschützte Routen
 // Prüft Authorization-Header, validiert JWT-Token und extrahiert User-ID
-// Macht User-ID über c.Locals("userID") für Handler verfügbar
+// Macht User-ID über c.Locals("userID") und die gespeicherte Sprache über c.Locals("locale") verfügbar
 func AuthRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Authorization-Header prüfen
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "authorization-Header ist erforderlich",
-			})
+			return handlers.Problem(c, errAuthorizationRequired, "")
 		}
 
 		// Bearer-Token extrahieren
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "ungültiges Format des Authorization-Headers. Erwartet: Bearer <token>",
-			})
+			return handlers.Problem(c, errInvalidAuthorizationHeader, "")
 		}
 
 		tokenString := parts[1]
 		if tokenString == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "token darf nicht leer sein",
-			})
+			return handlers.Problem(c, errEmptyToken, "")
 		}
 
 		// JWT-Token validieren und User-ID extrahieren
 		userID, err := security.ValidateJWTToken(tokenString)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "ungültiger oder abgelaufener Token",
-			})
+			return handlers.Problem(c, errInvalidToken, "")
 		}
 
 		// User-ID für nachfolgende Handler verfügbar machen
 		c.Locals("userID", userID)
+
+		// Ohne unterstützte Sprache in Accept-Language gilt die gespeicherte Sprache des Benutzers.
+		// Sie wird erst gelesen, wenn eine Antwort tatsächlich übersetzt werden muss
+		c.Locals("locale", handlers.LocaleLoader(func() string {
+			var locale string
+			if err := DB.Model(&models.User{}).Select("locale").Where("id = ?", userID).Scan(&locale).Error; err != nil {
+				log.Printf("Fehler beim Lesen der Sprache von Benutzer %d: %v", userID, err)
+			}
+			return locale
+		}))
+		return c.Next()
+	}
+}
+
+// errorHandler bildet Fehler, die Handler unbehandelt zurückgeben, auf Problem-Antworten ab.
+// Fiber-Fehler behalten Status und Nachricht, unbekannte Fehler werden als 500 protokolliert.
+func errorHandler(c *fiber.Ctx, err error) error {
+	return handlers.Problem(c, err, "Interner Serverfehler")
+}
+
+// Grenze für gepufferte Anfragekörper, entspricht dem früheren Fiber-Standardlimit
+const defaultBodyLimit = 4 * 1024 * 1024
+
+// RequestBodyLimit begrenzt die Größe gepufferter Anfragekörper.
+// Da der Server Anfragekörper streamt (StreamRequestBody), greift Fibers BodyLimit nicht mehr;
+// diese Middleware liest höchstens limit Bytes ein und lehnt größere Anfragen ab.
+// Streaming-Endpunkte (siehe streamingContentTypes) begrenzen ihre Körper selbst.
+func RequestBodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stream := c.Context().RequestBodyStream()
+		if stream == nil || isStreamingContentType(c.Get(fiber.HeaderContentType)) {
+			return c.Next()
+		}
+
+		if c.Request().Header.ContentLength() > limit {
+			return handlers.Problem(c, handlers.ErrBodyTooLarge, "")
+		}
+
+		// Auch Chunked-Anfragen ohne Content-Length dürfen das Limit nicht überschreiten
+		body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+		if err != nil {
+			return handlers.Problem(c, handlers.ErrBodyUnreadable, "")
+		}
+		if len(body) > limit {
+			return handlers.Problem(c, handlers.ErrBodyTooLarge, "")
+		}
+		c.Request().SetBody(body)
 		return c.Next()
 	}
 }
 
+// Content-Types, deren Anfragekörper von den Handlern gestreamt bzw. selbst begrenzt werden:
+// Anhänge, NDJSON-Importe und Backup-Dateien
+var streamingContentTypes = []string{fiber.MIMEOctetStream, "application/x-ndjson", handlers.MIMEAccountBackup}
+
+// isStreamingContentType prüft, ob ein Anfragekörper vom Handler selbst gelesen wird.
+func isStreamingContentType(contentType string) bool {
+	for _, streaming := range streamingContentTypes {
+		if strings.HasPrefix(contentType, streaming) {
+			return true
+		}
+	}
+	return false
+}
+
 // setupMiddleware konfiguriert alle Middleware für die Anwendung.
 func setupMiddleware(app *fiber.App) {
 	// Sicherheits-Middleware (Helmet) für verschiedene HTTP-Header zum Schutz der Anwendung
@@ -145,19 +228,22 @@ func setupMiddleware(app *fiber.App) {
 			return c.Get("x-forwarded-for", c.IP()) // Rate-Limiting basierend auf IP-Adresse
 		},
 		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "zu viele Anfragen. Bitte versuchen Sie es später erneut.",
-			})
+			return handlers.Problem(c, errRateLimited, "")
 		},
 	}))
 
+	// Größe gepufferter Anfragekörper begrenzen (Streaming-Uploads sind ausgenommen)
+	app.Use(RequestBodyLimit(defaultBodyLimit))
+
 	// CORS-Konfiguration (Cross-Origin Resource Sharing)
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     getEnv("ALLOWED_ORIGINS", "http://localhost:5173"),              // Erlaubte Ursprünge
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With", // Erlaubte Header
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",                                   // Erlaubte HTTP-Methoden
-		AllowCredentials: true,                                                            // Cookies und HTTP-Authentifizierungs-Header zulassen
-		MaxAge:           86400,                                                           // 24 Stunden Cache für Preflight-Anfragen
+		AllowOrigins: getEnv("ALLOWED_ORIGINS", "http://localhost:5173"), // Erlaubte Ursprünge
+		// Erlaubte Header (inkl. Metadaten-Header für Anhang-Uploads)
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With, X-Content-SHA256, X-Attachment-Name, X-Attachment-Name-IV, X-Attachment-Name-Tag, X-Device-ID, If-Match, Idempotency-Key, X-Backup-Key, X-Backup-Master-Password",
+		ExposeHeaders:    "X-Content-SHA256, ETag, X-Total-Count, X-Next-Cursor, Idempotent-Replayed", // Für Clients lesbare Antwort-Header
+		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",                                               // Erlaubte HTTP-Methoden
+		AllowCredentials: true,                                                                        // Cookies und HTTP-Authentifizierungs-Header zulassen
+		MaxAge:           86400,                                                                       // 24 Stunden Cache für Preflight-Anfragen
 	}))
 }
 
@@ -184,14 +270,57 @@ func setupRoutes(app *fiber.App, handlers *Handlers) {
 	auth.Post("/logout", AuthRequired(), handlers.Auth.Logout)               // Benutzerabmeldung (geschützt)
 	auth.Delete("/account", AuthRequired(), handlers.Auth.DeleteAccount)     // Account löschen (geschützt)
 
+	// Master-Passwort ändern (geschützt, neues Passwort muss die Richtlinie erfüllen):
+	// Beginn sichert den Tresor vor der Neuverschlüsselung, Abschluss setzt das neue Passwort
+	auth.Post("/master-password/begin", AuthRequired(), handlers.Auth.BeginMasterPasswordChange)
+	auth.Put("/master-password", AuthRequired(), handlers.Auth.ChangeMasterPassword)
+
+	// Abfrage kompromittierter Passwörter per Hash-Präfix (öffentlich, k-Anonymity)
+	api.Get("/breach/range/:sha1prefix", handlers.Breach.GetRange)
+
 	// Passwortverwaltungsrouten (geschützt, erfordert Authentifizierung)
 	passwords := api.Group("/passwords", AuthRequired())
-	passwords.Post("/", handlers.Password.CreatePassword)            // Passwort erstellen
-	passwords.Post("/batch", handlers.Password.BatchCreatePasswords) // Batch-Passwörter erstellen
-	passwords.Get("/", handlers.Password.GetPasswords)               // Alle Passwörter abrufen
-	passwords.Get("/:id", handlers.Password.GetPassword)             // Einzelnes Passwort nach ID abrufen
-	passwords.Put("/:id", handlers.Password.UpdatePassword)          // Passwort aktualisieren
-	passwords.Delete("/:id", handlers.Password.DeletePassword)       // Passwort löschen
+	// Anlegen ist mit dem Header Idempotency-Key gefahrlos wiederholbar
+	passwords.Post("/", handlers.Idempotency.Handle, handlers.Password.CreatePassword)            // Passwort erstellen
+	passwords.Post("/batch", handlers.Idempotency.Handle, handlers.Password.BatchCreatePasswords) // Batch-Passwörter erstellen
+
+	passwords.Put("/batch", handlers.Password.BatchUpdatePasswords)                          // Mehrere Passwörter aktualisieren (atomic/best_effort)
+	passwords.Delete("/batch", handlers.Password.BatchDeletePasswords)                       // Mehrere Passwörter in den Papierkorb verschieben
+	passwords.Get("/", handlers.Password.GetPasswords)                                       // Passwörter seitenweise abrufen (all=true: alle)
+	passwords.Post("/import", handlers.Password.ImportPasswords)                             // Streaming-Import (NDJSON)
+	passwords.Get("/imports", handlers.Password.GetImportJobs)                               // Fortschritt der letzten Importe
+	passwords.Get("/imports/:id", handlers.Password.GetImportJob)                            // Fortschritt eines Imports
+	passwords.Get("/export", handlers.Password.ExportPasswords)                              // Streaming-Export (NDJSON)
+	passwords.Get("/match", handlers.Password.MatchPasswords)                                // Zu einer URL passende Einträge (Browser-Erweiterung)
+	passwords.Get("/trash", handlers.Password.GetTrash)                                      // Papierkorb abrufen
+	passwords.Delete("/trash", handlers.Password.EmptyTrash)                                 // Papierkorb leeren
+	passwords.Post("/trash/:id/restore", handlers.Password.RestorePassword)                  // Eintrag aus dem Papierkorb wiederherstellen
+	passwords.Delete("/trash/:id", handlers.Password.DeletePasswordPermanently)              // Eintrag endgültig löschen
+	passwords.Get("/:id", handlers.Password.GetPassword)                                     // Einzelnes Passwort nach ID abrufen
+	passwords.Get("/:id/history", handlers.Password.GetPasswordHistory)                      // Frühere Passwörter eines Eintrags abrufen
+	passwords.Post("/:id/used", handlers.Password.MarkPasswordUsed)                          // Verwendung eines Eintrags festhalten
+	passwords.Get("/:id/revisions", handlers.Password.GetPasswordRevisions)                  // Revisionen eines Eintrags abrufen
+	passwords.Post("/:id/revisions/:rev/restore", handlers.Password.RestorePasswordRevision) // Eintrag auf eine Revision zurücksetzen
+	passwords.Put("/:id", handlers.Password.UpdatePassword)                                  // Passwort aktualisieren
+	passwords.Delete("/:id", handlers.Password.DeletePassword)                               // Passwort in den Papierkorb verschieben
+
+	// Verschlüsselte Dateianhänge eines Passwort-Eintrags
+	passwords.Get("/:id/attachments", handlers.Attachment.GetAttachments)                    // Anhänge auflisten
+	passwords.Post("/:id/attachments", handlers.Attachment.UploadAttachment)                 // Anhang hochladen (Streaming)
+	passwords.Get("/:id/attachments/:attachmentId", handlers.Attachment.DownloadAttachment)  // Anhang herunterladen (Streaming)
+	passwords.Delete("/:id/attachments/:attachmentId", handlers.Attachment.DeleteAttachment) // Anhang löschen
+	api.Get("/attachments/usage", AuthRequired(), handlers.Attachment.GetUsage)              // Speicherverbrauch für Anhänge
+
+	// Delta-Synchronisation für Clients mit lokalem Tresor (geschützt)
+	api.Get("/sync", AuthRequired(), handlers.Password.Sync)
+
+	// Tresor-Snapshots (geschützt)
+	vault := api.Group("/vault", AuthRequired())
+	vault.Get("/snapshots", handlers.Vault.GetSnapshots)                     // Snapshots auflisten
+	vault.Post("/snapshots", handlers.Vault.CreateSnapshot)                  // Snapshot anlegen
+	vault.Post("/snapshots/:id/rollback", handlers.Vault.RollbackToSnapshot) // Tresor auf einen Snapshot zurücksetzen
+	vault.Get("/backup", handlers.Vault.ExportBackup)                        // Signiertes Konto-Backup herunterladen
+	vault.Post("/backup/restore", handlers.Vault.RestoreBackup)              // Backup in ein leeres Konto wiederherstellen (mit Master-Passwort des Backups)
 
 	// Zwei-Faktor-Authentifizierungsrouten (2FA)
 	twofa := api.Group("/two-factor")
@@ -204,6 +333,16 @@ func setupRoutes(app *fiber.App, handlers *Handlers) {
 	// 2FA-Verifizierung (öffentlich - während des Logins verwendet)
 	twofa.Post("/verify", handlers.TwoFA.VerifyLoginCode) // 2FA-Login-Code verifizieren
 
+	// Berichte zum Zustand des Tresors (geschützt)
+	reports := api.Group("/reports", AuthRequired())
+	reports.Get("/reuse", handlers.Report.GetReuseReport) // Wiederverwendete Passwörter (über Fingerabdrücke)
+	reports.Get("/age", handlers.Report.GetAgeReport)     // Alter der Passwörter
+
+	// Einstellungen des URL-Abgleichs (geschützt)
+	settings := api.Group("/settings", AuthRequired())
+	settings.Get("/domains", handlers.Settings.GetDomains)    // Gleichwertige Domains abrufen
+	settings.Put("/domains", handlers.Settings.UpdateDomains) // Eigene Gruppen und Ausschlüsse ersetzen
+
 	// Benutzerverwaltungsrouten (geschützt)
 	users := api.Group("/users", AuthRequired())
 	users.Get("/profile", handlers.User.GetProfile)       // Benutzerprofil abrufen
@@ -219,18 +358,14 @@ func setupRoutes(app *fiber.App, handlers *Handlers) {
 
 			// Nur für Testbenutzer erlauben (Sicherheit)
 			if !strings.HasPrefix(username, "testuser_") {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-					"error": "Nur für Testbenutzer erlaubt",
-				})
+				return errTestUserOnly
 			}
 
 			// Benutzer anhand des Benutzernamens finden
 			var user models.User
 			result := DB.Where("username = ?", username).First(&user)
 			if result.Error != nil {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error": "Benutzer nicht gefunden",
-				})
+				return services.ErrUserNotFound
 			}
 
 			// E-Mail als verifiziert markieren
@@ -241,9 +376,7 @@ func setupRoutes(app *fiber.App, handlers *Handlers) {
 			})
 
 			if result.Error != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Fehler beim Aktualisieren der E-Mail-Verifikation",
-				})
+				return fmt.Errorf("Fehler beim Aktualisieren der E-Mail-Verifikation: %w", result.Error)
 			}
 
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -257,26 +390,146 @@ func setupRoutes(app *fiber.App, handlers *Handlers) {
 
 // Handlers-Struktur gruppiert alle Handler für die Anwendung.
 type Handlers struct {
-	Auth     *handlers.AuthHandler
-	Password *handlers.PasswordHandler
-	TwoFA    *handlers.TwoFAHandler
-	User     *handlers.UserHandler
+	Auth        *handlers.AuthHandler
+	Password    *handlers.PasswordHandler
+	Attachment  *handlers.AttachmentHandler
+	Vault       *handlers.VaultHandler
+	TwoFA       *handlers.TwoFAHandler
+	User        *handlers.UserHandler
+	Idempotency *handlers.IdempotencyHandler
+	Settings    *handlers.SettingsHandler
+	Report      *handlers.ReportHandler
+	Breach      *handlers.BreachHandler
 }
 
 // initServices initialisiert alle Anwendungsdienste (Services).
-func initServices() *Handlers {
-	userService := services.NewUserService(DB)                // Benutzerdienst erstellen
-	authService := services.NewAuthService(DB, userService)   // Authentifizierungsdienst erstellen
-	emailService := services.NewEmailService(DB)              // E-Mail-Dienst erstellen
-	passwordService := services.NewPasswordService(DB)        // Passwortdienst erstellen
+func initServices(attachmentStorage storage.Storage) *Handlers {
+	attachmentService := services.NewAttachmentService(DB, attachmentStorage, // Anhangdienst erstellen
+		getEnvInt64("ATTACHMENT_QUOTA_BYTES", 100*1024*1024), // Kontingent pro Benutzer (Standard: 100 MiB)
+		getEnvInt64("ATTACHMENT_MAX_BYTES", 25*1024*1024))    // Maximale Größe pro Anhang (Standard: 25 MiB)
+	userService := services.NewUserService(DB, attachmentService) // Benutzerdienst erstellen
+	breachService := initBreachService()                          // Index kompromittierter Passwörter öffnen
+	emailService := services.NewEmailService(DB)                  // E-Mail-Dienst erstellen
+
+	// Richtlinie für Master-Passwörter (Standard: 12 Zeichen, 50 Bit geschätzte Entropie,
+	// ohne Benutzername/E-Mail, Abgleich mit Datenlecks sofern ein Index geladen ist)
+	passwordPolicy := services.NewPasswordPolicy(
+		int(getEnvInt64("MASTER_PASSWORD_MIN_LENGTH", 12)),
+		float64(getEnvInt64("MASTER_PASSWORD_MIN_ENTROPY_BITS", 50)),
+		getEnv("MASTER_PASSWORD_FORBID_PERSONAL_INFO", "true") == "true",
+		getEnv("BREACH_REJECT_MASTER_PASSWORDS", "true") == "true",
+		breachService)
+
+	// Snapshot-Dienst erstellen (Standard: 10 Snapshots pro Benutzer)
+	snapshotService := services.NewSnapshotService(DB, int(getEnvInt64("VAULT_SNAPSHOT_LIMIT", 10)))
+	authService := services.NewAuthService(DB, userService, passwordPolicy, snapshotService) // Authentifizierungsdienst erstellen
+
+	// Maximale Größe hochgeladener Backup-Dateien (Standard: 256 MiB)
+	maxBackupBytes := getEnvInt64("BACKUP_MAX_BYTES", 256*1024*1024)
+
+	// Passwortdienst erstellen, Revisionen werden pro Eintrag und nach Alter begrenzt. Tombstones für die
+	// Delta-Synchronisation werden 180 Tage aufbewahrt, ältere Clients synchronisieren danach vollständig
+	passwordService := services.NewPasswordService(DB, attachmentService, snapshotService, services.RevisionRetention{
+		MaxPerItem: int(getEnvInt64("REVISION_MAX_PER_ITEM", 100)),                            // Revisionen pro Eintrag (Standard: 100)
+		MaxAge:     time.Duration(getEnvInt64("REVISION_MAX_AGE_DAYS", 365)) * 24 * time.Hour, // Maximales Alter (Standard: 1 Jahr)
+	}, time.Duration(getEnvInt64("TOMBSTONE_RETENTION_DAYS", 180))*24*time.Hour)
 	twoFAService := services.NewTwoFAService(DB, userService) // 2FA-Dienst erstellen
 
+	// Gespeicherte Antworten für Idempotency-Keys (Standard: 24 Stunden)
+	idempotencyService := services.NewIdempotencyService(DB, time.Duration(getEnvInt64("IDEMPOTENCY_TTL_HOURS", 24))*time.Hour)
+
+	reportService := services.NewReportService(DB) // Berichtsdienst erstellen
+
+	// Globale Gruppen gleichwertiger Domains abgleichen: eingebettete Liste oder
+	// aktualisierte Liste aus EQUIVALENT_DOMAINS_FILE (ohne neues Release austauschbar)
+	domainService := services.NewDomainService(DB)
+	var domainGroups []byte
+	if path := os.Getenv("EQUIVALENT_DOMAINS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warnung: Liste gleichwertiger Domains konnte nicht gelesen werden, Standardliste wird verwendet: %v", err)
+		}
+		domainGroups = data
+	}
+	if err := domainService.SyncGlobalDomainGroups(domainGroups); err != nil {
+		log.Printf("Warnung: Abgleich der gleichwertigen Domains fehlgeschlagen: %v", err)
+	}
+
 	// Handler mit den entsprechenden Diensten initialisieren und zurückgeben
 	return &Handlers{
-		Auth:     handlers.NewAuthHandler(authService, emailService),
-		Password: handlers.NewPasswordHandler(passwordService, userService),
-		TwoFA:    handlers.NewTwoFAHandler(twoFAService, userService),
-		User:     handlers.NewUserHandler(userService),
+		Auth:        handlers.NewAuthHandler(authService, emailService),
+		Password:    handlers.NewPasswordHandler(passwordService, userService),
+		Attachment:  handlers.NewAttachmentHandler(attachmentService),
+		Vault:       handlers.NewVaultHandler(snapshotService, passwordService, maxBackupBytes),
+		TwoFA:       handlers.NewTwoFAHandler(twoFAService, userService),
+		User:        handlers.NewUserHandler(userService),
+		Idempotency: handlers.NewIdempotencyHandler(idempotencyService),
+		Settings:    handlers.NewSettingsHandler(domainService),
+		Report:      handlers.NewReportHandler(reportService),
+		Breach:      handlers.NewBreachHandler(breachService),
+	}
+}
+
+// initBreachService öffnet den mit "breach-import" erstellten Index kompromittierter Passwörter
+// (BREACH_INDEX_PATH). Ohne Index antwortet /breach/range mit 503 und Master-Passwörter werden nicht abgeglichen.
+func initBreachService() *services.BreachService {
+	path := os.Getenv("BREACH_INDEX_PATH")
+	if path == "" {
+		return services.NewBreachService(nil)
+	}
+	index, err := breach.Open(path)
+	if err != nil {
+		log.Printf("Warnung: Index kompromittierter Passwörter konnte nicht geöffnet werden: %v", err)
+		return services.NewBreachService(nil)
+	}
+	log.Printf("Index kompromittierter Passwörter geladen: %d Hashes", index.Count())
+	return services.NewBreachService(index)
+}
+
+// runBreachImport erstellt aus einer Pwned-Passwords-Hashliste ("SHA1:Häufigkeit", nach Hash sortiert)
+// einen Index für /breach/range. Aufruf: main breach-import <hashliste|-> <indexdatei>
+func runBreachImport(args []string) {
+	if len(args) != 2 {
+		log.Fatalf("aufruf: %s breach-import <hashliste|-> <indexdatei>", os.Args[0])
+	}
+
+	input := os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Fatalf("hashliste konnte nicht geöffnet werden: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	started := time.Now()
+	count, err := breach.Build(input, args[1])
+	if err != nil {
+		log.Fatalf("import fehlgeschlagen: %v", err)
+	}
+	log.Printf("Index %s erstellt: %d Hashes in %s", args[1], count, time.Since(started).Round(time.Second))
+}
+
+// initStorage erstellt den Speicher-Treiber für Dateianhänge.
+// ATTACHMENT_STORAGE=local (Standard) speichert im Dateisystem, =s3 in einem S3-kompatiblen Bucket (z.B. MinIO).
+func initStorage() (storage.Storage, error) {
+	switch driver := getEnv("ATTACHMENT_STORAGE", "local"); driver {
+	case "local":
+		return storage.NewLocalStorage(getEnv("ATTACHMENT_DIR", "./data/attachments"))
+	case "s3":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return storage.NewS3Storage(ctx, storage.S3Config{
+			Endpoint:  getEnv("S3_ENDPOINT", "localhost:9000"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    getEnv("S3_BUCKET", "trustme-attachments"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    getEnv("S3_USE_SSL", "true") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unbekannter Speicher-Treiber für Anhänge: %s", driver)
 	}
 }
 
@@ -288,6 +541,59 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// startRetentionPurge löscht in regelmäßigen Abständen Papierkorb-Einträge, Revisionen, Tombstones und gespeicherte
+// Idempotenz-Antworten, deren Aufbewahrungsdauer abgelaufen ist. Das Intervall ist über RETENTION_PURGE_INTERVAL konfigurierbar.
+func startRetentionPurge(passwordService *services.PasswordService, idempotencyService *services.IdempotencyService) {
+	interval, err := time.ParseDuration(getEnv("RETENTION_PURGE_INTERVAL", "1h"))
+	if err != nil || interval <= 0 {
+		log.Printf("Warnung: ungültiges RETENTION_PURGE_INTERVAL, verwende 1h")
+		interval = time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := passwordService.PurgeExpiredTrash()
+			if err != nil {
+				log.Printf("Fehler beim Bereinigen des Papierkorbs: %v", err)
+			} else if purged > 0 {
+				log.Printf("Papierkorb bereinigt: %d Einträge endgültig gelöscht", purged)
+			}
+
+			revisions, err := passwordService.PurgeExpiredRevisions()
+			if err != nil {
+				log.Printf("Fehler beim Bereinigen der Revisionen: %v", err)
+			} else if revisions > 0 {
+				log.Printf("Revisionen bereinigt: %d abgelaufene Revisionen gelöscht", revisions)
+			}
+
+			tombstones, err := passwordService.PurgeExpiredTombstones()
+			if err != nil {
+				log.Printf("Fehler beim Bereinigen der Tombstones: %v", err)
+			} else if tombstones > 0 {
+				log.Printf("Tombstones bereinigt: %d abgelaufene Tombstones gelöscht", tombstones)
+			}
+
+			if _, err := idempotencyService.PurgeExpired(); err != nil {
+				log.Printf("Fehler beim Bereinigen der Idempotency-Keys: %v", err)
+			}
+		}
+	}()
+}
+
+// getEnvInt64 ruft eine ganzzahlige Umgebungsvariable ab und verwendet einen Fallback-Wert, falls nicht gesetzt oder ungültig.
+func getEnvInt64(key string, fallback int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return parsed
+		}
+		log.Printf("Warnung: ungültiger Wert für %s: %q, verwende %d", key, value, fallback)
+	}
+	return fallback
+}
+
 // gracefulShutdown behandelt das ordnungsgemäße Herunterfahren des Servers.
 func gracefulShutdown(app *fiber.App) {
 	// Kanal für Betriebssystemsignale erstellen
@@ -314,38 +620,36 @@ func gracefulShutdown(app *fiber.App) {
 }
 
 func main() {
+	// Unterbefehl: Index kompromittierter Passwörter erstellen (ohne Datenbank)
+	if len(os.Args) > 1 && os.Args[1] == "breach-import" {
+		runBreachImport(os.Args[2:])
+		return
+	}
+
 	// Datenbank initialisieren
 	if err := initDB(); err != nil {
 		log.Fatalf("datenbankinitialisierung fehlgeschlagen: %v", err)
 	}
 
+	// Speicher für Dateianhänge initialisieren
+	attachmentStorage, err := initStorage()
+	if err != nil {
+		log.Fatalf("speicherinitialisierung fehlgeschlagen: %v", err)
+	}
+
 	// Dienste und Handler initialisieren
-	handlers := initServices()
+	handlers := initServices(attachmentStorage)
+
+	// Abgelaufene Papierkorb-Einträge und Revisionen regelmäßig endgültig löschen
+	startRetentionPurge(handlers.Password.PasswordService, handlers.Idempotency.IdempotencyService)
 
 	// Fiber-Anwendung mit benutzerdefinierter Konfiguration erstellen
 	app := fiber.New(fiber.Config{
-		// Error-Handler für die zentrale Fehlerbehandlung
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			message := "interner Serverfehler"
-
-			// Wenn es ein Fiber-Fehler ist, den Statuscode und die Nachricht extrahieren
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-				message = e.Message
-			}
-
-			// Fehler protokollieren
-			log.Printf("Fehler: %v", err)
-
-			// Fehlerantwort als JSON zurückgeben
-			return c.Status(code).JSON(fiber.Map{
-				"error": message,
-			})
-		},
-		ReadTimeout:  time.Second * 30,  // Timeout für das Lesen des Anfragekörpers
-		WriteTimeout: time.Second * 30,  // Timeout für das Schreiben der Antwort
-		IdleTimeout:  time.Second * 120, // Timeout für inaktive Verbindungen
+		ErrorHandler:      errorHandler,      // Zentrale Fehlerbehandlung (application/problem+json)
+		StreamRequestBody: true,              // Große Anfragekörper (z.B. Anhänge) streamen statt puffern
+		ReadTimeout:       time.Second * 30,  // Timeout für das Lesen des Anfragekörpers (Streaming-Import verlängert ihn blockweise)
+		WriteTimeout:      time.Second * 30,  // Timeout für das Schreiben der Antwort (Streaming-Export verlängert ihn blockweise)
+		IdleTimeout:       time.Second * 120, // Timeout für inaktive Verbindungen
 	})
 
 	// Middleware und Routen einrichten
@@ -2,40 +2,292 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User repräsentiert das Benutzerprofil in der Datenbank.
 type User struct {
-	ID                   uint       `gorm:"primaryKey"`           // Eindeutige ID des Benutzers
-	Username             string     `gorm:"uniqueIndex;not null"` // Benutzername, muss eindeutig sein und darf nicht null sein
-	Email                string     `gorm:"uniqueIndex;not null"` // E-Mail-Adresse, muss eindeutig sein und darf nicht null sein
-	EmailVerified        bool       `gorm:"default:false"`        // Flag, ob die E-Mail-Adresse verifiziert ist
-	EmailVerificationToken string   `gorm:"type:text"`            // Token für die E-Mail-Verifizierung
-	EmailTokenExpiry     *time.Time // Ablaufzeit des E-Mail-Verifizierungstokens
-	HashedMasterPassword string     `gorm:"type:text;not null"`   // Gehashtes Master-Passwort des Benutzers
-	Salt                 string     `gorm:"type:text;not null"`   // Salt für das Hashing des Master-Passworts
-	TwoFAEnabled         bool       `gorm:"default:false"`        // Flag, ob die Zwei-Faktor-Authentifizierung aktiviert ist
-	TwoFASecret          string     `gorm:"type:text"`            // Geheimnis für die Zwei-Faktor-Authentifizierung (nullable)
-	CreatedAt            time.Time  // Zeitstempel der Erstellung des Benutzers
-	UpdatedAt            time.Time  // Zeitstempel der letzten Aktualisierung des Benutzers
-	Passwords            []Password `gorm:"foreignKey:UserID"` // Verknüpfung zu den Passwörtern des Benutzers (One-to-Many)
+	ID                     uint       `gorm:"primaryKey"`           // Eindeutige ID des Benutzers
+	Username               string     `gorm:"uniqueIndex;not null"` // Benutzername, muss eindeutig sein und darf nicht null sein
+	Email                  string     `gorm:"uniqueIndex;not null"` // E-Mail-Adresse, muss eindeutig sein und darf nicht null sein
+	EmailVerified          bool       `gorm:"default:false"`        // Flag, ob die E-Mail-Adresse verifiziert ist
+	EmailVerificationToken string     `gorm:"type:text"`            // Token für die E-Mail-Verifizierung
+	EmailTokenExpiry       *time.Time // Ablaufzeit des E-Mail-Verifizierungstokens
+	HashedMasterPassword   string     `gorm:"type:text;not null"`  // Gehashtes Master-Passwort des Benutzers
+	Salt                   string     `gorm:"type:text;not null"`  // Salt für das Hashing des Master-Passworts
+	TwoFAEnabled           bool       `gorm:"default:false"`       // Flag, ob die Zwei-Faktor-Authentifizierung aktiviert ist
+	TwoFASecret            string     `gorm:"type:text"`           // Geheimnis für die Zwei-Faktor-Authentifizierung (nullable)
+	TrashRetentionDays     int        `gorm:"not null;default:30"` // Tage, nach denen Einträge im Papierkorb endgültig gelöscht werden (0 = nie)
+	VaultRevision          int64      `gorm:"not null;default:0"`  // Fortlaufender Änderungszähler des Tresors für die Delta-Synchronisation
+	TombstoneHorizon       int64      `gorm:"not null;default:0"`  // Höchste Tresor-Revision bereinigter Tombstones; ältere Sync-Cursor erfordern eine vollständige Synchronisation
+	Locale                 string     `gorm:"size:8;default:de"`   // Bevorzugte Sprache für Meldungen und E-Mails (de, en)
+	CreatedAt              time.Time  // Zeitstempel der Erstellung des Benutzers
+	UpdatedAt              time.Time  // Zeitstempel der letzten Aktualisierung des Benutzers
+	Passwords              []Password `gorm:"foreignKey:UserID"` // Verknüpfung zu den Passwörtern des Benutzers (One-to-Many)
+}
+
+// Typen von Tresor-Einträgen. Logins nutzen weiterhin die festen Login-Spalten,
+// alle anderen Typen speichern ihre Daten im verschlüsselten Payload (EncryptedData).
+const (
+	ItemTypeLogin    = "login"    // Website-Login (Benutzername, Passwort, Notizen)
+	ItemTypeNote     = "note"     // Sichere Notiz
+	ItemTypeCard     = "card"     // Zahlungskarte
+	ItemTypeIdentity = "identity" // Identität (Name, Adresse, Ausweisdaten)
+	ItemTypeAPIKey   = "api_key"  // API-Schlüssel
+	ItemTypeSSHKey   = "ssh_key"  // SSH-Schlüsselpaar
+)
+
+// IsValidItemType prüft, ob der übergebene Typ ein bekannter Eintragstyp ist.
+func IsValidItemType(itemType string) bool {
+	switch itemType {
+	case ItemTypeLogin, ItemTypeNote, ItemTypeCard, ItemTypeIdentity, ItemTypeAPIKey, ItemTypeSSHKey:
+		return true
+	}
+	return false
 }
 
 // Password repräsentiert einen gespeicherten Passwort-Eintrag in der Datenbank.
 type Password struct {
-	ID                uint      `gorm:"primaryKey"`         // Eindeutige ID des Passwort-Eintrags
-	UserID            uint      `gorm:"index"`              // Fremdschlüssel zur Benutzer-ID
-	WebsiteURL        string    `gorm:"type:text;not null"` // URL der Website, zu der das Passwort gehört
-	EncryptedUsername string    `gorm:"type:text;not null"` // Verschlüsselter Benutzername für die Website
-	UsernameIV        string    `gorm:"type:text;not null"` // Initialisierungsvektor für den Benutzernamen
-	UsernameTag       string    `gorm:"type:text;not null"` // Authentifizierungs-Tag für den Benutzernamen (für GCM)
-	EncryptedPassword string    `gorm:"type:text;not null"` // Verschlüsseltes Passwort (als String)
-	PasswordIV        string    `gorm:"type:text;not null"` // Initialisierungsvektor für das Passwort
-	PasswordTag       string    `gorm:"type:text;not null"` // Authentifizierungs-Tag für das Passwort (für GCM)
-	EncryptedNotes    string    `gorm:"type:text"`          // Verschlüsselte Notizen (nullable)
-	NotesIV           string    `gorm:"type:text"`          // Initialisierungsvektor für Notizen (nullable)
-	NotesTag          string    `gorm:"type:text"`          // Authentifizierungs-Tag für Notizen (nullable)
-	CreatedAt         time.Time // Zeitstempel der Erstellung des Passwort-Eintrags
-	UpdatedAt         time.Time // Zeitstempel der letzten Aktualisierung des Passwort-Eintrags
-	Owner             User      `gorm:"foreignKey:UserID"` // Beziehung zurück zum Benutzer (gehört zu)
+	ID uint `gorm:"primaryKey"` // Eindeutige ID des Passwort-Eintrags
+	// Fremdschlüssel zur Benutzer-ID (führende Spalte der Indizes für Synchronisation und Sortierung)
+	UserID            uint            `gorm:"index;index:idx_passwords_user_sync,priority:1;index:idx_passwords_user_created,priority:1;index:idx_passwords_user_updated,priority:1"`
+	WebsiteURL        string          `gorm:"type:text;not null"`                              // URL der Website, zu der das Passwort gehört (leer, wenn verschlüsselt gespeichert)
+	EncryptedURL      string          `gorm:"type:text"`                                       // Verschlüsselte URL der Website (alternativ zu WebsiteURL, nullable)
+	URLIV             string          `gorm:"type:text"`                                       // Initialisierungsvektor für die URL (nullable)
+	URLTag            string          `gorm:"type:text"`                                       // Authentifizierungs-Tag für die URL (nullable)
+	DomainIndex       string          `gorm:"type:varchar(64);index"`                          // Blind-Index der registrierbaren Domain (vom Client berechneter HMAC, hex, nullable)
+	EncryptedUsername string          `gorm:"type:text;not null"`                              // Verschlüsselter Benutzername für die Website
+	UsernameIV        string          `gorm:"type:text;not null"`                              // Initialisierungsvektor für den Benutzernamen
+	UsernameTag       string          `gorm:"type:text;not null"`                              // Authentifizierungs-Tag für den Benutzernamen (für GCM)
+	EncryptedPassword string          `gorm:"type:text;not null"`                              // Verschlüsseltes Passwort (als String)
+	PasswordIV        string          `gorm:"type:text;not null"`                              // Initialisierungsvektor für das Passwort
+	PasswordTag       string          `gorm:"type:text;not null"`                              // Authentifizierungs-Tag für das Passwort (für GCM)
+	Fingerprint       string          `gorm:"type:varchar(64);index"`                          // HMAC-Fingerabdruck des Passworts (vom Client mit einem aus dem Tresorschlüssel abgeleiteten Schlüssel berechnet, hex, nullable)
+	EncryptedNotes    string          `gorm:"type:text"`                                       // Verschlüsselte Notizen (nullable)
+	NotesIV           string          `gorm:"type:text"`                                       // Initialisierungsvektor für Notizen (nullable)
+	NotesTag          string          `gorm:"type:text"`                                       // Authentifizierungs-Tag für Notizen (nullable)
+	Type              string          `gorm:"type:varchar(32);not null;default:'login';index"` // Typ des Eintrags (login, note, card, identity, api_key, ssh_key)
+	EncryptedName     string          `gorm:"type:text"`                                       // Verschlüsselter Anzeigename des Eintrags (nullable)
+	NameIV            string          `gorm:"type:text"`                                       // Initialisierungsvektor für den Anzeigenamen (nullable)
+	NameTag           string          `gorm:"type:text"`                                       // Authentifizierungs-Tag für den Anzeigenamen (nullable)
+	EncryptedData     string          `gorm:"type:text"`                                       // Verschlüsselter, typabhängiger Payload als ein Blob (nullable)
+	DataIV            string          `gorm:"type:text"`                                       // Initialisierungsvektor für den Payload (nullable)
+	DataTag           string          `gorm:"type:text"`                                       // Authentifizierungs-Tag für den Payload (nullable)
+	DataVersion       int             `gorm:"not null;default:0"`                              // Version des Payload-Formats (0 = kein Payload, nur Login-Spalten)
+	PasswordChangedAt *time.Time      // Zeitpunkt der letzten Änderung des Passworts (unabhängig von anderen Feldern)
+	LastUsedAt        *time.Time      // Zeitpunkt der letzten Verwendung (z.B. automatisches Ausfüllen), erhöht keine Revision
+	Revision          int             `gorm:"not null;default:1"`                                          // Fortlaufende Revisionsnummer des Eintrags (erhöht bei jeder inhaltlichen Änderung)
+	SyncRevision      int64           `gorm:"not null;default:0;index:idx_passwords_user_sync,priority:2"` // Tresor-Revision der letzten Änderung (inkl. Papierkorb), Grundlage der Delta-Synchronisation
+	DeletedAt         gorm.DeletedAt  `gorm:"index"`                                                       // Zeitpunkt der Verschiebung in den Papierkorb (Soft Delete, nullable)
+	CreatedAt         time.Time       `gorm:"index:idx_passwords_user_created,priority:2"`                 // Zeitstempel der Erstellung des Passwort-Eintrags
+	UpdatedAt         time.Time       `gorm:"index:idx_passwords_user_updated,priority:2"`                 // Zeitstempel der letzten Aktualisierung des Passwort-Eintrags
+	Owner             User            `gorm:"foreignKey:UserID"`                                           // Beziehung zurück zum Benutzer (gehört zu)
+	Fields            []PasswordField `gorm:"foreignKey:PasswordID"`                                       // Benutzerdefinierte Felder des Eintrags (One-to-Many, geordnet nach Position)
+	URIs              []PasswordURI   `gorm:"foreignKey:PasswordID"`                                       // URIs eines Logins mit Abgleichsregel (One-to-Many, geordnet nach Position)
+}
+
+// Typen benutzerdefinierter Felder
+const (
+	FieldTypeText    = "text"    // Sichtbares Textfeld
+	FieldTypeHidden  = "hidden"  // Verdecktes Feld (z.B. PIN, Sicherheitsantwort)
+	FieldTypeBoolean = "boolean" // Ja/Nein-Feld
+	FieldTypeLinked  = "linked"  // Verweis auf ein eingebautes Feld des Eintrags (username, password)
+)
+
+// Ziele, auf die ein verknüpftes Feld (FieldTypeLinked) verweisen kann
+const (
+	LinkedFieldUsername = "username"
+	LinkedFieldPassword = "password"
+)
+
+// PasswordField repräsentiert ein benutzerdefiniertes Feld eines Tresor-Eintrags.
+// Name und Wert werden client-seitig verschlüsselt, jeweils mit eigenem IV und Tag.
+type PasswordField struct {
+	ID             uint      `gorm:"primaryKey"`                               // Eindeutige ID des Feldes
+	PasswordID     uint      `gorm:"index"`                                    // Fremdschlüssel zum Passwort-Eintrag
+	Position       int       `gorm:"not null;default:0"`                       // Position des Feldes innerhalb des Eintrags
+	Type           string    `gorm:"type:varchar(16);not null;default:'text'"` // Feldtyp (text, hidden, boolean, linked)
+	EncryptedName  string    `gorm:"type:text;not null"`                       // Verschlüsselter Feldname
+	NameIV         string    `gorm:"type:text;not null"`                       // Initialisierungsvektor für den Feldnamen
+	NameTag        string    `gorm:"type:text;not null"`                       // Authentifizierungs-Tag für den Feldnamen
+	EncryptedValue string    `gorm:"type:text"`                                // Verschlüsselter Feldwert (nullable, leer bei verknüpften Feldern)
+	ValueIV        string    `gorm:"type:text"`                                // Initialisierungsvektor für den Feldwert (nullable)
+	ValueTag       string    `gorm:"type:text"`                                // Authentifizierungs-Tag für den Feldwert (nullable)
+	LinkedTo       string    `gorm:"type:varchar(32)"`                         // Ziel eines verknüpften Feldes (username, password)
+	CreatedAt      time.Time // Zeitstempel der Erstellung des Feldes
+	UpdatedAt      time.Time // Zeitstempel der letzten Aktualisierung des Feldes
+}
+
+// Abgleichsregeln für URIs eines Logins
+const (
+	URIMatchDomain     = "domain"      // Gleiche registrierbare Domain (Standard), z.B. login.example.com passt zu example.com
+	URIMatchHost       = "host"        // Gleicher Host inklusive Port
+	URIMatchStartsWith = "starts_with" // Die aufgerufene URL beginnt mit der URI
+	URIMatchExact      = "exact"       // Die aufgerufene URL entspricht exakt der URI
+	URIMatchRegex      = "regex"       // Die aufgerufene URL passt zum regulären Ausdruck in der URI
+	URIMatchNever      = "never"       // Die URI wird nie für das automatische Ausfüllen verwendet
+)
+
+// IsValidURIMatch prüft, ob die übergebene Abgleichsregel bekannt ist.
+func IsValidURIMatch(match string) bool {
+	switch match {
+	case URIMatchDomain, URIMatchHost, URIMatchStartsWith, URIMatchExact, URIMatchRegex, URIMatchNever:
+		return true
+	}
+	return false
+}
+
+// PasswordURI repräsentiert eine von mehreren URIs eines Logins (Website, separate Login-Domain, App).
+// Wie WebsiteURL liegt die URI im Klartext oder verschlüsselt mit Blind-Index der Domain vor.
+type PasswordURI struct {
+	ID           uint   `gorm:"primaryKey"`                                 // Eindeutige ID der URI
+	PasswordID   uint   `gorm:"index"`                                      // Fremdschlüssel zum Passwort-Eintrag
+	Position     int    `gorm:"not null;default:0"`                         // Position der URI innerhalb des Eintrags
+	URI          string `gorm:"type:text;not null"`                         // URI bzw. regulärer Ausdruck (bei Abgleichsregel regex; leer, wenn verschlüsselt)
+	Match        string `gorm:"type:varchar(16);not null;default:'domain'"` // Abgleichsregel (domain, host, starts_with, exact, regex, never)
+	EncryptedURI string `gorm:"type:text"`                                  // Verschlüsselte URI (alternativ zu URI, nullable)
+	URIIV        string `gorm:"type:text"`                                  // Initialisierungsvektor für die URI (nullable)
+	URITag       string `gorm:"type:text"`                                  // Authentifizierungs-Tag für die URI (nullable)
+	DomainIndex  string `gorm:"type:varchar(64);index"`                     // Blind-Index der registrierbaren Domain (HMAC, hex, nullable)
+}
+
+// Attachment repräsentiert einen verschlüsselten Dateianhang eines Tresor-Eintrags.
+// Der Inhalt liegt als Chiffretext im konfigurierten Speicher, hier stehen nur Metadaten.
+type Attachment struct {
+	ID                uint      `gorm:"primaryKey"`                     // Eindeutige ID des Anhangs
+	PasswordID        uint      `gorm:"index"`                          // Fremdschlüssel zum Passwort-Eintrag
+	UserID            uint      `gorm:"index"`                          // Fremdschlüssel zum Benutzer (für Quota-Berechnung)
+	EncryptedFileName string    `gorm:"type:text;not null"`             // Verschlüsselter Dateiname
+	FileNameIV        string    `gorm:"type:text;not null"`             // Initialisierungsvektor für den Dateinamen
+	FileNameTag       string    `gorm:"type:text;not null"`             // Authentifizierungs-Tag für den Dateinamen
+	Size              int64     `gorm:"not null"`                       // Größe des Chiffretexts in Bytes
+	SHA256            string    `gorm:"type:char(64);not null"`         // SHA-256 des Chiffretexts (hex) für Integritätsprüfungen
+	StorageKey        string    `gorm:"type:text;not null;uniqueIndex"` // Schlüssel des Objekts im Speicher-Treiber
+	CreatedAt         time.Time // Zeitstempel des Uploads
+}
+
+// PasswordHistory speichert ein früheres verschlüsseltes Passwort eines Eintrags (append-only).
+// Pro Eintrag wird nur eine begrenzte Anzahl der jüngsten Versionen aufbewahrt.
+type PasswordHistory struct {
+	ID                uint       `gorm:"primaryKey"`         // Eindeutige ID des Verlaufseintrags
+	PasswordID        uint       `gorm:"index"`              // Fremdschlüssel zum Passwort-Eintrag
+	UserID            uint       `gorm:"index"`              // Fremdschlüssel zum Benutzer
+	EncryptedPassword string     `gorm:"type:text;not null"` // Früheres verschlüsseltes Passwort
+	PasswordIV        string     `gorm:"type:text;not null"` // Initialisierungsvektor des früheren Passworts
+	PasswordTag       string     `gorm:"type:text;not null"` // Authentifizierungs-Tag des früheren Passworts
+	ValidFrom         *time.Time // Seit wann das frühere Passwort gesetzt war (nullable bei Altdaten)
+	CreatedAt         time.Time  // Zeitpunkt, an dem das Passwort ersetzt wurde
+}
+
+// Aktionen, durch die eine Revision eines Eintrags entstanden ist
+const (
+	RevisionActionCreate  = "create"  // Eintrag wurde angelegt
+	RevisionActionUpdate  = "update"  // Eintrag wurde bearbeitet
+	RevisionActionRestore = "restore" // Eintrag wurde auf eine frühere Revision zurückgesetzt
+)
+
+// PasswordRevision speichert eine vollständige verschlüsselte Momentaufnahme eines Eintrags
+// nach jeder inhaltlichen Änderung, zusammen mit dem Gerät, das die Änderung vorgenommen hat.
+// Ältere Revisionen werden gemäß der konfigurierten Aufbewahrung entfernt.
+type PasswordRevision struct {
+	ID         uint      `gorm:"primaryKey"`                                 // Eindeutige ID der Revision
+	PasswordID uint      `gorm:"not null;uniqueIndex:idx_password_revision"` // Fremdschlüssel zum Passwort-Eintrag
+	UserID     uint      `gorm:"not null;index"`                             // Fremdschlüssel zum Benutzer
+	Revision   int       `gorm:"not null;uniqueIndex:idx_password_revision"` // Revisionsnummer innerhalb des Eintrags
+	Action     string    `gorm:"type:varchar(16);not null"`                  // Art der Änderung (create, update, restore)
+	Snapshot   string    `gorm:"type:text;not null"`                         // Verschlüsselter Stand des Eintrags als JSON (inkl. benutzerdefinierter Felder)
+	DeviceID   string    `gorm:"type:varchar(128)"`                          // Vom Client gemeldete Geräte-ID (optional)
+	UserAgent  string    `gorm:"type:varchar(512)"`                          // User-Agent der Sitzung, die die Änderung vorgenommen hat
+	IPAddress  string    `gorm:"type:varchar(64)"`                           // IP-Adresse der Sitzung
+	CreatedAt  time.Time `gorm:"index"`                                      // Zeitpunkt der Änderung
+}
+
+// Anlässe, zu denen ein Tresor-Snapshot angelegt wird
+const (
+	SnapshotReasonManual               = "manual"                 // Vom Benutzer angefordert
+	SnapshotReasonBatchImport          = "batch_import"           // Automatisch vor einem Batch-Import
+	SnapshotReasonBatchUpdate          = "batch_update"           // Automatisch vor einer Batch-Aktualisierung (z.B. Neuverschlüsselung)
+	SnapshotReasonMasterPasswordChange = "master_password_change" // Automatisch vor der Änderung des Master-Passworts
+	SnapshotReasonRollback             = "pre_rollback"           // Automatisch vor dem Zurücksetzen auf einen anderen Snapshot
+)
+
+// VaultSnapshot speichert eine Kopie aller Tresor-Einträge eines Benutzers (inklusive Papierkorb,
+// benutzerdefinierter Felder und Passwort-Verlauf) zu einem Zeitpunkt. Die Einträge liegen
+// ausschließlich als Chiffretext vor; Anhang-Inhalte sind nicht enthalten.
+type VaultSnapshot struct {
+	ID        uint      `gorm:"primaryKey"`                // Eindeutige ID des Snapshots
+	UserID    uint      `gorm:"not null;index"`            // Fremdschlüssel zum Benutzer
+	Reason    string    `gorm:"type:varchar(32);not null"` // Anlass des Snapshots (manual, batch_import, pre_rollback)
+	ItemCount int       `gorm:"not null"`                  // Anzahl der enthaltenen Einträge
+	Data      string    `gorm:"type:text;not null"`        // Serialisierte Einträge als JSON
+	CreatedAt time.Time // Zeitpunkt der Erstellung
+}
+
+// PasswordTombstone markiert einen endgültig gelöschten Eintrag für die Delta-Synchronisation,
+// damit Clients ihn auch dann entfernen, wenn sie die Löschung nicht direkt beobachtet haben.
+type PasswordTombstone struct {
+	ID           uint      `gorm:"primaryKey"`                                         // Eindeutige ID des Tombstones
+	UserID       uint      `gorm:"not null;index:idx_tombstones_user_sync,priority:1"` // Fremdschlüssel zum Benutzer
+	PasswordID   uint      `gorm:"not null"`                                           // ID des gelöschten Eintrags
+	SyncRevision int64     `gorm:"not null;index:idx_tombstones_user_sync,priority:2"` // Tresor-Revision, in der der Eintrag gelöscht wurde
+	CreatedAt    time.Time // Zeitpunkt der endgültigen Löschung
+}
+
+// IdempotencyKey speichert die Antwort auf eine Anfrage mit Idempotency-Key, damit eine Wiederholung
+// (z.B. nach einem Timeout) dieselbe Antwort erhält, statt die Aktion ein zweites Mal auszuführen.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primaryKey"`                                                                 // Eindeutige ID
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_idempotency_user_key,priority:1"`                   // Fremdschlüssel zum Benutzer
+	Key         string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key,priority:2"` // Vom Client gewählter Schlüssel
+	RequestHash string    `gorm:"type:varchar(64);not null"`                                                  // SHA-256 über Methode, Pfad und Anfragekörper
+	StatusCode  int       `gorm:"not null;default:0"`                                                         // Gespeicherter HTTP-Status (0 = Anfrage wird noch bearbeitet)
+	ContentType string    `gorm:"type:varchar(128)"`                                                          // Content-Type der gespeicherten Antwort
+	Body        []byte    // Gespeicherter Antwortkörper
+	CreatedAt   time.Time `gorm:"index"` // Zeitpunkt der ersten Anfrage
+}
+
+// Status eines Streaming-Imports
+const (
+	ImportStatusRunning   = "running"   // Import läuft noch
+	ImportStatusCompleted = "completed" // Alle Zeilen wurden verarbeitet
+	ImportStatusFailed    = "failed"    // Import wurde abgebrochen; bereits übernommene Blöcke bleiben erhalten
+)
+
+// ImportJob protokolliert Fortschritt und Ergebnis eines Streaming-Imports (NDJSON).
+// Der Fortschritt wird nach jedem übernommenen Block aktualisiert und kann während des Imports abgefragt werden.
+type ImportJob struct {
+	ID        uint      `gorm:"primaryKey"`                // Eindeutige ID des Imports
+	UserID    uint      `gorm:"not null;index"`            // Fremdschlüssel zum Benutzer
+	Status    string    `gorm:"type:varchar(16);not null"` // Status (running, completed, failed)
+	Processed int       `gorm:"not null;default:0"`        // Anzahl gelesener Einträge
+	Created   int       `gorm:"not null;default:0"`        // Anzahl angelegter Einträge
+	Failed    int       `gorm:"not null;default:0"`        // Anzahl ungültiger Einträge
+	Error     string    `gorm:"type:varchar(512)"`         // Grund für den Abbruch (nur bei failed)
+	CreatedAt time.Time // Beginn des Imports
+	UpdatedAt time.Time // Letzte Fortschrittsaktualisierung
+}
+
+// GlobalDomainGroup ist eine globale Gruppe gleichwertiger Domains (z.B. google.com und youtube.com).
+// Die Tabelle wird beim Start aus der eingebetteten bzw. konfigurierten Liste abgeglichen.
+type GlobalDomainGroup struct {
+	ID        uint      `gorm:"primaryKey"`                            // Eindeutige ID der Gruppe
+	Key       string    `gorm:"type:varchar(64);uniqueIndex;not null"` // Stabiler Schlüssel der Gruppe (z.B. "google"), Grundlage für Ausschlüsse
+	Domains   []string  `gorm:"serializer:json;type:text;not null"`    // Registrierbare Domains der Gruppe
+	UpdatedAt time.Time // Zeitpunkt der letzten Aktualisierung
+}
+
+// UserDomainGroup ist eine benutzerdefinierte Gruppe gleichwertiger Domains.
+type UserDomainGroup struct {
+	ID        uint      `gorm:"primaryKey"`                         // Eindeutige ID der Gruppe
+	UserID    uint      `gorm:"not null;index"`                     // Fremdschlüssel zum Benutzer
+	Domains   []string  `gorm:"serializer:json;type:text;not null"` // Registrierbare Domains der Gruppe
+	CreatedAt time.Time // Zeitpunkt der Erstellung
+	UpdatedAt time.Time // Zeitpunkt der letzten Aktualisierung
+}
+
+// ExcludedDomainGroup markiert eine globale Domain-Gruppe, die ein Benutzer nicht verwenden möchte.
+type ExcludedDomainGroup struct {
+	UserID   uint   `gorm:"primaryKey"`                  // Fremdschlüssel zum Benutzer
+	GroupKey string `gorm:"primaryKey;type:varchar(64)"` // Schlüssel der ausgeschlossenen globalen Gruppe
 }
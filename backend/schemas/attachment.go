@@ -0,0 +1,33 @@
+package schemas
+
+import "time"
+
+// AttachmentUploadRequest enthält die Metadaten eines Anhang-Uploads.
+// Der Inhalt selbst wird als roher Chiffretext im Anfragekörper gestreamt,
+// die Metadaten werden daher aus den Headern gelesen.
+type AttachmentUploadRequest struct {
+	EncryptedFileName string // Verschlüsselter Dateiname (Header X-Attachment-Name)
+	FileNameIV        string // Initialisierungsvektor für Dateiname (Header X-Attachment-Name-IV)
+	FileNameTag       string // Authentifizierungs-Tag für Dateiname (Header X-Attachment-Name-Tag)
+	Size              int64  // Größe des Chiffretexts in Bytes (Header Content-Length)
+	SHA256            string // Erwarteter SHA-256 des Chiffretexts, hex (Header X-Content-SHA256)
+}
+
+// AttachmentResponse definiert die Struktur der Antwort für einen Dateianhang.
+type AttachmentResponse struct {
+	ID                uint      `json:"id"`                  // Eindeutige ID des Anhangs
+	PasswordID        uint      `json:"password_id"`         // ID des zugehörigen Passwort-Eintrags
+	EncryptedFileName string    `json:"encrypted_file_name"` // Verschlüsselter Dateiname (Base64)
+	FileNameIV        string    `json:"file_name_iv"`        // IV für Dateiname (Base64)
+	FileNameTag       string    `json:"file_name_tag"`       // Tag für Dateiname (Base64)
+	Size              int64     `json:"size"`                // Größe des Chiffretexts in Bytes
+	SHA256            string    `json:"sha256"`              // SHA-256 des Chiffretexts (hex)
+	CreatedAt         time.Time `json:"created_at"`          // Zeitpunkt des Uploads
+}
+
+// AttachmentUsageResponse beschreibt den Speicherverbrauch eines Benutzers für Anhänge.
+type AttachmentUsageResponse struct {
+	UsedBytes    int64 `json:"used_bytes"`     // Belegter Speicher in Bytes
+	QuotaBytes   int64 `json:"quota_bytes"`    // Verfügbares Kontingent in Bytes
+	MaxFileBytes int64 `json:"max_file_bytes"` // Maximale Größe eines einzelnen Anhangs
+}
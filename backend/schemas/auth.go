@@ -2,15 +2,16 @@ package schemas
 
 // RegisterRequest definiert die Struktur der Anfrage für die Benutzerregistrierung.
 type RegisterRequest struct {
-	Username       string `json:"username" binding:"required"`        // Benutzername, muss eindeutig sein
-	Email          string `json:"email" binding:"required,email"`     // E-Mail-Adresse, muss gültig sein
-	MasterPassword string `json:"master_password" binding:"required"` // Master-Passwort des Benutzers
+	Username       string `json:"username" validate:"required,min=3,max=64"` // Benutzername, muss eindeutig sein
+	Email          string `json:"email" validate:"required,email,max=254"`   // E-Mail-Adresse, muss gültig sein
+	MasterPassword string `json:"master_password" validate:"required"`       // Master-Passwort des Benutzers (siehe Passwortrichtlinie)
+	Locale         string `json:"locale" validate:"omitempty,oneof=de en"`   // Bevorzugte Sprache (ohne Angabe aus Accept-Language)
 }
 
 // LoginRequest definiert die Struktur der Anfrage für die Benutzeranmeldung.
 type LoginRequest struct {
-	Username       string `json:"username" binding:"required"`        // Benutzername
-	MasterPassword string `json:"master_password" binding:"required"` // Master-Passwort
+	Username       string `json:"username" validate:"required"`        // Benutzername
+	MasterPassword string `json:"master_password" validate:"required"` // Master-Passwort
 }
 
 // LoginResponse definiert die Struktur der Antwort nach einer erfolgreichen Anmeldung.
@@ -20,17 +21,18 @@ type LoginResponse struct {
 	Username     string `json:"username"`       // Benutzername des angemeldeten Benutzers
 	TwoFAEnabled bool   `json:"two_fa_enabled"` // Gibt an, ob 2FA für diesen Benutzer aktiviert ist
 	Salt         string `json:"salt"`           // Salt, der für die Ableitung des Verschlüsselungsschlüssels verwendet wird
+	Locale       string `json:"locale"`         // Bevorzugte Sprache des Benutzers
 }
 
 // TwoFactorSetupRequest definiert die Struktur für die Anfrage zur Einrichtung der Zwei-Faktor-Authentifizierung.
 type TwoFactorSetupRequest struct {
-	Code string `json:"code" binding:"required"` // Der vom Benutzer generierte 2FA-Code
+	Code string `json:"code" validate:"required,len=6,numeric"` // Der vom Benutzer generierte 2FA-Code
 }
 
 // TwoFactorVerifyRequest definiert die Struktur für die Anfrage zur Verifizierung der Zwei-Faktor-Authentifizierung während des Logins.
 type TwoFactorVerifyRequest struct {
-	Username string `json:"username" binding:"required"` // Benutzername
-	Code     string `json:"code" binding:"required"`     // Der vom Benutzer generierte 2FA-Code
+	Username string `json:"username" validate:"required"`           // Benutzername
+	Code     string `json:"code" validate:"required,len=6,numeric"` // Der vom Benutzer generierte 2FA-Code
 }
 
 // TwoFALoginResponse repräsentiert die Antwort nach erfolgreicher 2FA-Verifizierung
@@ -41,10 +43,35 @@ type TwoFALoginResponse struct {
 
 // EmailVerificationRequest definiert die Struktur für die E-Mail-Verifizierung
 type EmailVerificationRequest struct {
-	Token string `json:"token" binding:"required"` // Verifizierungstoken aus der E-Mail
+	Token string `json:"token" validate:"required"` // Verifizierungstoken aus der E-Mail
 }
 
 // ResendVerificationRequest definiert die Struktur für das erneute Senden der Verifizierungs-E-Mail
 type ResendVerificationRequest struct {
-	Email string `json:"email" binding:"required,email"` // E-Mail-Adresse des Benutzers
+	Email string `json:"email" validate:"required,email,max=254"` // E-Mail-Adresse des Benutzers
+}
+
+// BeginMasterPasswordChangeRequest definiert die Struktur der Anfrage zum Beginn einer Änderung des Master-Passworts.
+// Der Server sichert den Tresor, bevor der Client ihn mit dem neuen Schlüssel neu verschlüsselt.
+type BeginMasterPasswordChangeRequest struct {
+	CurrentMasterPassword string `json:"current_master_password" validate:"required"` // Bisheriges Master-Passwort
+	NewMasterPassword     string `json:"new_master_password" validate:"required"`     // Neues Master-Passwort
+}
+
+// BeginMasterPasswordChangeResponse definiert die Struktur der Antwort zum Beginn einer Änderung des Master-Passworts.
+type BeginMasterPasswordChangeResponse struct {
+	SnapshotID uint `json:"snapshot_id"` // Snapshot des Tresors vor der Neuverschlüsselung, beim Abschluss anzugeben
+}
+
+// ChangeMasterPasswordRequest definiert die Struktur der Anfrage zum Abschluss einer Änderung des Master-Passworts.
+// Der Client muss den Tresor nach dem Beginn der Änderung mit dem neuen Schlüssel neu verschlüsselt haben.
+type ChangeMasterPasswordRequest struct {
+	BeginMasterPasswordChangeRequest
+	SnapshotID uint `json:"snapshot_id" validate:"required"` // Snapshot aus dem Beginn der Änderung
+}
+
+// PasswordPolicyViolation beschreibt eine verletzte Regel der Master-Passwort-Richtlinie.
+type PasswordPolicyViolation struct {
+	Rule    string `json:"rule"`    // Stabiler Bezeichner der Regel (min_length, entropy, personal_info, breached)
+	Message string `json:"message"` // Lesbare Beschreibung für die Anzeige
 }
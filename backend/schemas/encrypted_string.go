@@ -0,0 +1,156 @@
+package schemas
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Algorithmen verschlüsselter Werte (Algorithmus-ID im versionierten Format)
+const (
+	EncAlgAES256GCM = 1 // AES-256-GCM mit 12-Byte-IV und 16-Byte-Tag
+)
+
+// Größen für AES-GCM in Bytes
+const (
+	GCMIVSize  = 12
+	GCMTagSize = 16
+)
+
+// Teile eines verschlüsselten Wertes (für feldgenaue Fehlermeldungen)
+const (
+	EncPartAlgorithm  = "algorithm"
+	EncPartIV         = "iv"
+	EncPartCiphertext = "ciphertext"
+	EncPartTag        = "tag"
+)
+
+// ErrInvalidEncryptedString ist der gemeinsame Fehler ungültiger verschlüsselter Werte (errors.Is).
+var ErrInvalidEncryptedString = errors.New("ungültiger verschlüsselter Wert")
+
+// EncryptedStringError beschreibt, welcher Teil eines verschlüsselten Wertes ungültig ist.
+type EncryptedStringError struct {
+	Part   string // Betroffener Teil (algorithm, iv, ciphertext, tag)
+	Reason string // Lesbare Begründung
+}
+
+func (e *EncryptedStringError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidEncryptedString, e.Reason)
+}
+
+func (e *EncryptedStringError) Unwrap() error {
+	return ErrInvalidEncryptedString
+}
+
+// EncryptedString ist ein client-seitig verschlüsselter Wert.
+// Versionierte Darstellung: "<Algorithmus-ID>.<IV>|<Ciphertext>|<Tag>" (jeweils Base64),
+// z.B. "1.q83v...|3q2+7w...|ZmFrZS..." für AES-256-GCM. Ältere Clients übertragen IV, Ciphertext und Tag
+// in getrennten Feldern (Algorithmus 1); die Web Crypto API hängt den Tag dabei an den Ciphertext an.
+type EncryptedString struct {
+	Algorithm  int    // Algorithmus-ID (siehe EncAlg*)
+	IV         []byte // Initialisierungsvektor
+	Ciphertext []byte // Ciphertext ohne Tag
+	Tag        []byte // Authentifizierungs-Tag
+}
+
+// IsVersionedEncryptedString prüft, ob ein Wert in der versionierten Darstellung vorliegt.
+// Base64 enthält weder "." noch "|", getrennte Ciphertexte sind dadurch eindeutig unterscheidbar.
+func IsVersionedEncryptedString(value string) bool {
+	return strings.Contains(value, "|")
+}
+
+// ParseEncryptedString liest einen Wert in der versionierten Darstellung und prüft ihn.
+func ParseEncryptedString(value string) (*EncryptedString, error) {
+	header, body, found := strings.Cut(value, ".")
+	if !found {
+		return nil, &EncryptedStringError{Part: EncPartAlgorithm, Reason: "Algorithmus-ID fehlt"}
+	}
+	algorithm, err := strconv.Atoi(header)
+	if err != nil {
+		return nil, &EncryptedStringError{Part: EncPartAlgorithm, Reason: fmt.Sprintf("ungültige Algorithmus-ID %q", header)}
+	}
+	parts := strings.Split(body, "|")
+	if len(parts) != 3 {
+		return nil, &EncryptedStringError{Part: EncPartCiphertext, Reason: "erwartet IV|Ciphertext|Tag"}
+	}
+
+	encrypted := &EncryptedString{Algorithm: algorithm}
+	for i, target := range []*[]byte{&encrypted.IV, &encrypted.Ciphertext, &encrypted.Tag} {
+		if *target, err = decodeBase64(parts[i]); err != nil {
+			return nil, &EncryptedStringError{Part: []string{EncPartIV, EncPartCiphertext, EncPartTag}[i], Reason: "kein gültiges Base64"}
+		}
+	}
+	if err := encrypted.Validate(); err != nil {
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// ParseEncryptedField liest einen verschlüsselten Wert aus den Feldern eines Eintrags: entweder in der
+// versionierten Darstellung im Ciphertext-Feld (IV und Tag leer) oder als getrennte Base64-Felder.
+// Ein leerer Tag bedeutet, dass er an den Ciphertext angehängt ist. Sind alle Felder leer, ist der
+// Wert nicht gesetzt (nil, nil).
+func ParseEncryptedField(ciphertext, iv, tag string) (*EncryptedString, error) {
+	if ciphertext == "" && iv == "" && tag == "" {
+		return nil, nil
+	}
+	if IsVersionedEncryptedString(ciphertext) {
+		if iv != "" || tag != "" {
+			return nil, &EncryptedStringError{Part: EncPartIV, Reason: "IV und Tag sind in der versionierten Darstellung enthalten und müssen leer sein"}
+		}
+		return ParseEncryptedString(ciphertext)
+	}
+
+	encrypted := &EncryptedString{Algorithm: EncAlgAES256GCM}
+	var err error
+	if encrypted.IV, err = decodeBase64(iv); err != nil {
+		return nil, &EncryptedStringError{Part: EncPartIV, Reason: "kein gültiges Base64"}
+	}
+	if encrypted.Ciphertext, err = decodeBase64(ciphertext); err != nil {
+		return nil, &EncryptedStringError{Part: EncPartCiphertext, Reason: "kein gültiges Base64"}
+	}
+	if encrypted.Tag, err = decodeBase64(tag); err != nil {
+		return nil, &EncryptedStringError{Part: EncPartTag, Reason: "kein gültiges Base64"}
+	}
+	if tag == "" {
+		// Web Crypto API: Tag am Ende des Ciphertexts
+		if len(encrypted.Ciphertext) < GCMTagSize {
+			return nil, &EncryptedStringError{Part: EncPartCiphertext, Reason: fmt.Sprintf("ohne separaten Tag muss der Ciphertext mindestens %d Bytes (angehängter Tag) lang sein", GCMTagSize)}
+		}
+		split := len(encrypted.Ciphertext) - GCMTagSize
+		encrypted.Ciphertext, encrypted.Tag = encrypted.Ciphertext[:split], encrypted.Ciphertext[split:]
+	}
+	if err := encrypted.Validate(); err != nil {
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// Validate prüft Algorithmus sowie die Längen von IV und Tag.
+func (e *EncryptedString) Validate() error {
+	switch e.Algorithm {
+	case EncAlgAES256GCM:
+		if len(e.IV) != GCMIVSize {
+			return &EncryptedStringError{Part: EncPartIV, Reason: fmt.Sprintf("IV muss %d Bytes lang sein (statt %d)", GCMIVSize, len(e.IV))}
+		}
+		if len(e.Tag) != GCMTagSize {
+			return &EncryptedStringError{Part: EncPartTag, Reason: fmt.Sprintf("Tag muss %d Bytes lang sein (statt %d)", GCMTagSize, len(e.Tag))}
+		}
+		return nil
+	default:
+		return &EncryptedStringError{Part: EncPartAlgorithm, Reason: fmt.Sprintf("unbekannter Algorithmus %d", e.Algorithm)}
+	}
+}
+
+// String liefert die versionierte Darstellung.
+func (e *EncryptedString) String() string {
+	return strconv.Itoa(e.Algorithm) + "." + base64.StdEncoding.EncodeToString(e.IV) + "|" +
+		base64.StdEncoding.EncodeToString(e.Ciphertext) + "|" + base64.StdEncoding.EncodeToString(e.Tag)
+}
+
+// decodeBase64 dekodiert Standard-Base64 mit oder ohne Padding.
+func decodeBase64(value string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+}
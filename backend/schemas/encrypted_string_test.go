@@ -0,0 +1,112 @@
+package schemas
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// b64 kodiert n Bytes mit dem Wert fill als Standard-Base64.
+func b64(fill byte, n int) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, n))
+}
+
+func TestParseEncryptedField(t *testing.T) {
+	iv := b64(0x01, GCMIVSize)
+	tag := b64(0x03, GCMTagSize)
+	// Web Crypto API: 5 Bytes Ciphertext gefolgt vom 16-Byte-Tag
+	appended := base64.StdEncoding.EncodeToString(append(bytes.Repeat([]byte{0x02}, 5), bytes.Repeat([]byte{0x03}, GCMTagSize)...))
+
+	tests := []struct {
+		name                string
+		ciphertext, iv, tag string
+		wantNil             bool
+		wantPart            string // Erwarteter fehlerhafter Teil ("" = kein Fehler)
+		wantCiphertextLen   int
+	}{
+		{name: "nicht gesetzt", wantNil: true},
+		{name: "getrennte Felder", ciphertext: b64(0x02, 5), iv: iv, tag: tag, wantCiphertextLen: 5},
+		{name: "getrennte Felder ohne Padding", ciphertext: "AgICAgI", iv: iv, tag: tag, wantCiphertextLen: 5},
+		{name: "angehängter Tag", ciphertext: appended, iv: iv, wantCiphertextLen: 5},
+		{name: "angehängter Tag, nur Tag", ciphertext: tag, iv: iv, wantCiphertextLen: 0},
+		{name: "angehängter Tag zu kurz", ciphertext: b64(0x02, GCMTagSize-1), iv: iv, wantPart: EncPartCiphertext},
+		{name: "versioniert", ciphertext: "1." + iv + "|" + b64(0x02, 5) + "|" + tag, wantCiphertextLen: 5},
+		{name: "versioniert mit IV-Feld", ciphertext: "1." + iv + "|" + b64(0x02, 5) + "|" + tag, iv: iv, wantPart: EncPartIV},
+		{name: "versioniert mit Tag-Feld", ciphertext: "1." + iv + "|" + b64(0x02, 5) + "|" + tag, tag: tag, wantPart: EncPartIV},
+		{name: "versioniert ohne Algorithmus", ciphertext: iv + "|" + b64(0x02, 5) + "|" + tag, wantPart: EncPartAlgorithm},
+		{name: "versioniert mit ungültiger Algorithmus-ID", ciphertext: "x." + iv + "|" + b64(0x02, 5) + "|" + tag, wantPart: EncPartAlgorithm},
+		{name: "versioniert mit unbekanntem Algorithmus", ciphertext: "2." + iv + "|" + b64(0x02, 5) + "|" + tag, wantPart: EncPartAlgorithm},
+		{name: "versioniert mit zwei Teilen", ciphertext: "1." + iv + "|" + b64(0x02, 5), wantPart: EncPartCiphertext},
+		{name: "versioniert mit ungültigem Tag", ciphertext: "1." + iv + "|" + b64(0x02, 5) + "|!!", wantPart: EncPartTag},
+		{name: "versioniert mit kurzem IV", ciphertext: "1." + b64(0x01, 8) + "|" + b64(0x02, 5) + "|" + tag, wantPart: EncPartIV},
+		{name: "IV zu kurz", ciphertext: b64(0x02, 5), iv: b64(0x01, 8), tag: tag, wantPart: EncPartIV},
+		{name: "IV zu lang", ciphertext: b64(0x02, 5), iv: b64(0x01, 16), tag: tag, wantPart: EncPartIV},
+		{name: "IV fehlt", ciphertext: b64(0x02, 5), tag: tag, wantPart: EncPartIV},
+		{name: "Tag zu kurz", ciphertext: b64(0x02, 5), iv: iv, tag: b64(0x03, 12), wantPart: EncPartTag},
+		{name: "IV kein Base64", ciphertext: b64(0x02, 5), iv: "!!", tag: tag, wantPart: EncPartIV},
+		{name: "Ciphertext kein Base64", ciphertext: "!!", iv: iv, tag: tag, wantPart: EncPartCiphertext},
+		{name: "Tag kein Base64", ciphertext: b64(0x02, 5), iv: iv, tag: "!!", wantPart: EncPartTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEncryptedField(tt.ciphertext, tt.iv, tt.tag)
+			if tt.wantPart != "" {
+				var encErr *EncryptedStringError
+				if !errors.As(err, &encErr) {
+					t.Fatalf("Fehler = %v, erwartet EncryptedStringError", err)
+				}
+				if encErr.Part != tt.wantPart {
+					t.Errorf("Part = %q, erwartet %q (%s)", encErr.Part, tt.wantPart, encErr.Reason)
+				}
+				if !errors.Is(err, ErrInvalidEncryptedString) {
+					t.Errorf("errors.Is(err, ErrInvalidEncryptedString) = false")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unerwarteter Fehler: %v", err)
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("Ergebnis = %+v, erwartet nil", got)
+				}
+				return
+			}
+			if got.Algorithm != EncAlgAES256GCM {
+				t.Errorf("Algorithm = %d, erwartet %d", got.Algorithm, EncAlgAES256GCM)
+			}
+			if !bytes.Equal(got.IV, bytes.Repeat([]byte{0x01}, GCMIVSize)) {
+				t.Errorf("IV = %x", got.IV)
+			}
+			if !bytes.Equal(got.Ciphertext, bytes.Repeat([]byte{0x02}, tt.wantCiphertextLen)) {
+				t.Errorf("Ciphertext = %x, erwartet %d Bytes 0x02", got.Ciphertext, tt.wantCiphertextLen)
+			}
+			if !bytes.Equal(got.Tag, bytes.Repeat([]byte{0x03}, GCMTagSize)) {
+				t.Errorf("Tag = %x", got.Tag)
+			}
+		})
+	}
+}
+
+func TestEncryptedStringRoundTrip(t *testing.T) {
+	want := &EncryptedString{
+		Algorithm:  EncAlgAES256GCM,
+		IV:         bytes.Repeat([]byte{0x01}, GCMIVSize),
+		Ciphertext: []byte("ciphertext"),
+		Tag:        bytes.Repeat([]byte{0x03}, GCMTagSize),
+	}
+	value := want.String()
+	if !IsVersionedEncryptedString(value) {
+		t.Fatalf("%q wird nicht als versionierte Darstellung erkannt", value)
+	}
+	got, err := ParseEncryptedString(value)
+	if err != nil {
+		t.Fatalf("ParseEncryptedString(%q): %v", value, err)
+	}
+	if got.Algorithm != want.Algorithm || !bytes.Equal(got.IV, want.IV) ||
+		!bytes.Equal(got.Ciphertext, want.Ciphertext) || !bytes.Equal(got.Tag, want.Tag) {
+		t.Errorf("ParseEncryptedString(String()) = %+v, erwartet %+v", got, want)
+	}
+}
@@ -3,54 +3,289 @@ package schemas
 import "time"
 
 // CreatePasswordRequest definiert die Struktur der Anfrage zum Erstellen eines neuen Passwort-Eintrags.
+// Logins benötigen verschlüsselten Benutzernamen und verschlüsseltes Passwort mit IV; jeder übergebene
+// Ciphertext braucht seinen IV (geprüft im Paket validation).
 type CreatePasswordRequest struct {
-	WebsiteURL        string `json:"website_url" binding:"required"`        // URL der Website
-	EncryptedUsername string `json:"encrypted_username" binding:"required"` // Verschlüsselter Benutzername
-	UsernameIV        string `json:"username_iv" binding:"required"`        // Initialisierungsvektor für Benutzername
-	UsernameTag       string `json:"username_tag" binding:"required"`       // Authentifizierungs-Tag für Benutzername
-	EncryptedPassword string `json:"encrypted_password" binding:"required"` // Verschlüsseltes Passwort (String)
-	PasswordIV        string `json:"password_iv" binding:"required"`        // Initialisierungsvektor für Passwort
-	PasswordTag       string `json:"password_tag" binding:"required"`       // Authentifizierungs-Tag für Passwort
-	EncryptedNotes    string `json:"encrypted_notes"`                       // Verschlüsselte Notizen (optional)
-	NotesIV           string `json:"notes_iv"`                              // Initialisierungsvektor für Notizen (optional)
-	NotesTag          string `json:"notes_tag"`                             // Authentifizierungs-Tag für Notizen (optional)
+	WebsiteURL        string               `json:"website_url"`        // URL der Website im Klartext (alternativ encrypted_url)
+	EncryptedURL      string               `json:"encrypted_url"`      // Verschlüsselte URL der Website (optional, statt website_url)
+	URLIV             string               `json:"url_iv"`             // Initialisierungsvektor für die URL (optional)
+	URLTag            string               `json:"url_tag"`            // Authentifizierungs-Tag für die URL (optional)
+	DomainIndex       string               `json:"domain_index"`       // Blind-Index der registrierbaren Domain (HMAC-SHA256, hex, optional)
+	EncryptedUsername string               `json:"encrypted_username"` // Verschlüsselter Benutzername
+	UsernameIV        string               `json:"username_iv"`        // Initialisierungsvektor für Benutzername
+	UsernameTag       string               `json:"username_tag"`       // Authentifizierungs-Tag für Benutzername
+	EncryptedPassword string               `json:"encrypted_password"` // Verschlüsseltes Passwort (String)
+	PasswordIV        string               `json:"password_iv"`        // Initialisierungsvektor für Passwort
+	PasswordTag       string               `json:"password_tag"`       // Authentifizierungs-Tag für Passwort
+	Fingerprint       string               `json:"fingerprint"`        // HMAC-SHA256 des Passworts für die Erkennung von Wiederverwendung (hex, optional)
+	EncryptedNotes    string               `json:"encrypted_notes"`    // Verschlüsselte Notizen (optional)
+	NotesIV           string               `json:"notes_iv"`           // Initialisierungsvektor für Notizen (optional)
+	NotesTag          string               `json:"notes_tag"`          // Authentifizierungs-Tag für Notizen (optional)
+	Type              string               `json:"type"`               // Eintragstyp (optional, Standard: login)
+	EncryptedName     string               `json:"encrypted_name"`     // Verschlüsselter Anzeigename (optional)
+	NameIV            string               `json:"name_iv"`            // Initialisierungsvektor für Anzeigename (optional)
+	NameTag           string               `json:"name_tag"`           // Authentifizierungs-Tag für Anzeigename (optional)
+	EncryptedData     string               `json:"encrypted_data"`     // Verschlüsselter Payload (erforderlich für Nicht-Login-Typen)
+	DataIV            string               `json:"data_iv"`            // Initialisierungsvektor für Payload
+	DataTag           string               `json:"data_tag"`           // Authentifizierungs-Tag für Payload
+	DataVersion       int                  `json:"data_version"`       // Version des Payload-Formats
+	Fields            []CustomFieldRequest `json:"fields"`             // Benutzerdefinierte Felder in Anzeigereihenfolge (optional)
+	URIs              []URIRequest         `json:"uris,omitempty"`     // URIs mit Abgleichsregel in Anzeigereihenfolge (optional)
+}
+
+// URIRequest definiert eine URI eines Logins innerhalb einer Erstellungs- oder Aktualisierungsanfrage.
+type URIRequest struct {
+	URI          string `json:"uri"`                     // URI bzw. regulärer Ausdruck (bei match=regex; leer, wenn verschlüsselt)
+	Match        string `json:"match"`                   // Abgleichsregel (domain, host, starts_with, exact, regex, never; Standard: domain)
+	EncryptedURI string `json:"encrypted_uri,omitempty"` // Verschlüsselte URI (optional, statt uri)
+	URIIV        string `json:"uri_iv,omitempty"`        // Initialisierungsvektor für die URI (optional)
+	URITag       string `json:"uri_tag,omitempty"`       // Authentifizierungs-Tag für die URI (optional)
+	DomainIndex  string `json:"domain_index,omitempty"`  // Blind-Index der registrierbaren Domain (HMAC-SHA256, hex, optional)
+}
+
+// CustomFieldRequest definiert ein benutzerdefiniertes Feld innerhalb einer Erstellungs- oder Aktualisierungsanfrage.
+// Die Position ergibt sich aus der Reihenfolge in der Liste.
+type CustomFieldRequest struct {
+	Type           string `json:"type"`            // Feldtyp (text, hidden, boolean, linked)
+	EncryptedName  string `json:"encrypted_name"`  // Verschlüsselter Feldname
+	NameIV         string `json:"name_iv"`         // Initialisierungsvektor für Feldname
+	NameTag        string `json:"name_tag"`        // Authentifizierungs-Tag für Feldname
+	EncryptedValue string `json:"encrypted_value"` // Verschlüsselter Feldwert (leer bei verknüpften Feldern)
+	ValueIV        string `json:"value_iv"`        // Initialisierungsvektor für Feldwert
+	ValueTag       string `json:"value_tag"`       // Authentifizierungs-Tag für Feldwert
+	LinkedTo       string `json:"linked_to"`       // Ziel eines verknüpften Feldes (username, password)
 }
 
 // BatchCreatePasswordRequest definiert die Struktur für die Batch-Erstellung mehrerer Passwörter.
+// Im Modus atomic (Standard) wird nur importiert, wenn alle Einträge gültig sind,
+// im Modus best_effort werden die gültigen Einträge importiert und die übrigen pro Index gemeldet.
 type BatchCreatePasswordRequest struct {
-	Passwords []CreatePasswordRequest `json:"passwords" binding:"required,min=1"` // Liste der Passwortanfragen
+	Mode      string                    `json:"mode"`                                         // atomic oder best_effort
+	Passwords []BatchCreatePasswordItem `json:"passwords" validate:"required,min=1,max=1000"` // Liste der Passwortanfragen
+}
+
+// BatchCreatePasswordItem beschreibt einen neuen Eintrag innerhalb eines Batch-Imports.
+// Die Felder entsprechen CreatePasswordRequest.
+type BatchCreatePasswordItem struct {
+	ClientRef string `json:"client_ref,omitempty"` // Optionale Referenz des Clients, wird im Ergebnis zurückgegeben
+	CreatePasswordRequest
+}
+
+// BatchUpdatePasswordRequest definiert die Struktur für die Aktualisierung mehrerer Einträge.
+// Im Modus atomic (Standard) werden alle Änderungen gemeinsam oder gar nicht übernommen,
+// im Modus best_effort wird jeder Eintrag unabhängig von den anderen angewendet.
+type BatchUpdatePasswordRequest struct {
+	Mode  string                    `json:"mode"`                                     // atomic oder best_effort
+	Items []BatchUpdatePasswordItem `json:"items" validate:"required,min=1,max=1000"` // Zu ändernde Einträge
+}
+
+// BatchUpdatePasswordItem beschreibt die Änderung eines Eintrags innerhalb eines Batches.
+// Die Felder entsprechen UpdatePasswordRequest inklusive expected_revision.
+type BatchUpdatePasswordItem struct {
+	ID uint `json:"id"` // ID des zu ändernden Eintrags
+	UpdatePasswordRequest
+}
+
+// BatchDeletePasswordRequest definiert die Struktur für das Löschen mehrerer Einträge (in den Papierkorb).
+type BatchDeletePasswordRequest struct {
+	Mode  string                    `json:"mode"`                                     // atomic oder best_effort
+	Items []BatchDeletePasswordItem `json:"items" validate:"required,min=1,max=1000"` // Zu löschende Einträge
+}
+
+// BatchDeletePasswordItem beschreibt das Löschen eines Eintrags innerhalb eines Batches.
+type BatchDeletePasswordItem struct {
+	ID               uint `json:"id"`                          // ID des zu löschenden Eintrags
+	ExpectedRevision *int `json:"expected_revision,omitempty"` // Optional: Revision, auf der das Löschen basiert
+}
+
+// BatchItemResult beschreibt das Ergebnis für einen einzelnen Eintrag eines Batches.
+type BatchItemResult struct {
+	Index     int               `json:"index"`                // Position des Eintrags in der Anfrage
+	ClientRef string            `json:"client_ref,omitempty"` // Referenz des Clients (nur beim Import)
+	ID        uint              `json:"id,omitempty"`         // ID des Eintrags (beim Import die neue Server-ID)
+	Status    int               `json:"status"`               // HTTP-Status des Eintrags (z.B. 200, 204, 404, 409)
+	Error     string            `json:"error,omitempty"`      // Fehlermeldung, falls der Eintrag nicht übernommen wurde
+	Code      string            `json:"code,omitempty"`       // Stabiler Fehlercode (wie in Problem-Antworten)
+	Fields    []FieldError      `json:"fields,omitempty"`     // Feldbezogene Validierungsfehler des Eintrags
+	Item      *PasswordResponse `json:"item,omitempty"`       // Neuer Stand nach erfolgreichem Anlegen bzw. Aktualisieren
+	Current   *PasswordResponse `json:"current,omitempty"`    // Aktueller Stand bei einem Revisionskonflikt
+}
+
+// BatchResultResponse definiert die Antwort auf eine Batch-Änderung.
+type BatchResultResponse struct {
+	Mode      string            `json:"mode"`      // Verwendeter Modus
+	Applied   bool              `json:"applied"`   // Ob Änderungen übernommen wurden (atomic: alle oder keine)
+	Succeeded int               `json:"succeeded"` // Anzahl erfolgreicher Einträge
+	Failed    int               `json:"failed"`    // Anzahl fehlgeschlagener Einträge
+	Results   []BatchItemResult `json:"results"`   // Ergebnisse in der Reihenfolge der Anfrage
 }
 
 // UpdatePasswordRequest definiert die Struktur der Anfrage zum Aktualisieren eines bestehenden Passwort-Eintrags.
-// Alle Felder sind optional, um Teilaktualisierungen zu ermöglichen.
+// Alle Felder sind optional, um Teilaktualisierungen zu ermöglichen. Der Eintragstyp ist unveränderlich.
 type UpdatePasswordRequest struct {
-	WebsiteURL        *string `json:"website_url,omitempty"`        // Optionale URL der Website
-	EncryptedUsername *string `json:"encrypted_username,omitempty"` // Optional verschlüsselter Benutzername
-	UsernameIV        *string `json:"username_iv,omitempty"`        // Optionaler IV für Benutzername
-	UsernameTag       *string `json:"username_tag,omitempty"`       // Optionaler Tag für Benutzername
-	EncryptedPassword *string `json:"encrypted_password,omitempty"` // Optional verschlüsseltes Passwort
-	PasswordIV        *string `json:"password_iv,omitempty"`        // Optionaler IV für Passwort
-	PasswordTag       *string `json:"password_tag,omitempty"`       // Optionaler Tag für Passwort
-	EncryptedNotes    *string `json:"encrypted_notes,omitempty"`    // Optional verschlüsselte Notizen
-	NotesIV           *string `json:"notes_iv,omitempty"`           // Optionaler IV für Notizen
-	NotesTag          *string `json:"notes_tag,omitempty"`          // Optionaler Tag für Notizen
+	WebsiteURL        *string               `json:"website_url,omitempty"`        // Optionale URL der Website
+	EncryptedURL      *string               `json:"encrypted_url,omitempty"`      // Optional verschlüsselte URL der Website
+	URLIV             *string               `json:"url_iv,omitempty"`             // Optionaler IV für die URL
+	URLTag            *string               `json:"url_tag,omitempty"`            // Optionaler Tag für die URL
+	DomainIndex       *string               `json:"domain_index,omitempty"`       // Optionaler Blind-Index der registrierbaren Domain
+	EncryptedUsername *string               `json:"encrypted_username,omitempty"` // Optional verschlüsselter Benutzername
+	UsernameIV        *string               `json:"username_iv,omitempty"`        // Optionaler IV für Benutzername
+	UsernameTag       *string               `json:"username_tag,omitempty"`       // Optionaler Tag für Benutzername
+	EncryptedPassword *string               `json:"encrypted_password,omitempty"` // Optional verschlüsseltes Passwort
+	PasswordIV        *string               `json:"password_iv,omitempty"`        // Optionaler IV für Passwort
+	PasswordTag       *string               `json:"password_tag,omitempty"`       // Optionaler Tag für Passwort
+	Fingerprint       *string               `json:"fingerprint,omitempty"`        // Optionaler Fingerabdruck des Passworts (entfällt bei Passwortänderung ohne neuen Wert)
+	EncryptedNotes    *string               `json:"encrypted_notes,omitempty"`    // Optional verschlüsselte Notizen
+	NotesIV           *string               `json:"notes_iv,omitempty"`           // Optionaler IV für Notizen
+	NotesTag          *string               `json:"notes_tag,omitempty"`          // Optionaler Tag für Notizen
+	EncryptedName     *string               `json:"encrypted_name,omitempty"`     // Optional verschlüsselter Anzeigename
+	NameIV            *string               `json:"name_iv,omitempty"`            // Optionaler IV für Anzeigename
+	NameTag           *string               `json:"name_tag,omitempty"`           // Optionaler Tag für Anzeigename
+	EncryptedData     *string               `json:"encrypted_data,omitempty"`     // Optional verschlüsselter Payload
+	DataIV            *string               `json:"data_iv,omitempty"`            // Optionaler IV für Payload
+	DataTag           *string               `json:"data_tag,omitempty"`           // Optionaler Tag für Payload
+	DataVersion       *int                  `json:"data_version,omitempty"`       // Optionale Version des Payload-Formats
+	Fields            *[]CustomFieldRequest `json:"fields,omitempty"`             // Optional: ersetzt die komplette Liste der benutzerdefinierten Felder
+	URIs              *[]URIRequest         `json:"uris,omitempty"`               // Optional: ersetzt die komplette Liste der URIs
+	ExpectedRevision  *int                  `json:"expected_revision,omitempty"`  // Optional: Revision, auf der die Änderung basiert (alternativ If-Match)
 }
 
 // PasswordResponse definiert die Struktur der Antwort für einen Passwort-Eintrag.
 // Verschlüsselte Felder werden hier als Base64-Strings zurückgegeben.
 type PasswordResponse struct {
-	ID                uint      `json:"id"`                 // Eindeutige ID des Passwort-Eintrags
-	UserID            uint      `json:"user_id"`            // ID des zugehörigen Benutzers
-	WebsiteURL        string    `json:"website_url"`        // URL der Website
-	EncryptedUsername string    `json:"encrypted_username"` // Verschlüsselter Benutzername (Base64)
-	UsernameIV        string    `json:"username_iv"`        // IV für Benutzernamen (Base64)
-	UsernameTag       string    `json:"username_tag"`       // Tag für Benutzernamen (Base64)
-	EncryptedPassword string    `json:"encrypted_password"` // Verschlüsseltes Passwort (Base64)
-	PasswordIV        string    `json:"password_iv"`        // IV für Passwort (Base64)
-	PasswordTag       string    `json:"password_tag"`       // Tag für Passwort (Base64)
-	EncryptedNotes    string    `json:"encrypted_notes"`    // Verschlüsselte Notizen (Base64, optional)
-	NotesIV           string    `json:"notes_iv"`           // IV für Notizen (Base64, optional)
-	NotesTag          string    `json:"notes_tag"`          // Tag für Notizen (Base64, optional)
-	CreatedAt         time.Time `json:"created_at"`         // Erstellungszeitpunkt
-	UpdatedAt         time.Time `json:"updated_at"`         // Letzter Aktualisierungszeitpunkt
+	ID                uint                  `json:"id"`                   // Eindeutige ID des Passwort-Eintrags
+	UserID            uint                  `json:"user_id"`              // ID des zugehörigen Benutzers
+	WebsiteURL        string                `json:"website_url"`          // URL der Website (leer, wenn verschlüsselt)
+	EncryptedURL      string                `json:"encrypted_url"`        // Verschlüsselte URL (Base64, optional)
+	URLIV             string                `json:"url_iv"`               // IV für URL (Base64, optional)
+	URLTag            string                `json:"url_tag"`              // Tag für URL (Base64, optional)
+	DomainIndex       string                `json:"domain_index"`         // Blind-Index der registrierbaren Domain (optional)
+	EncryptedUsername string                `json:"encrypted_username"`   // Verschlüsselter Benutzername (Base64)
+	UsernameIV        string                `json:"username_iv"`          // IV für Benutzernamen (Base64)
+	UsernameTag       string                `json:"username_tag"`         // Tag für Benutzernamen (Base64)
+	EncryptedPassword string                `json:"encrypted_password"`   // Verschlüsseltes Passwort (Base64)
+	PasswordIV        string                `json:"password_iv"`          // IV für Passwort (Base64)
+	PasswordTag       string                `json:"password_tag"`         // Tag für Passwort (Base64)
+	Fingerprint       string                `json:"fingerprint"`          // HMAC-Fingerabdruck des Passworts (optional)
+	EncryptedNotes    string                `json:"encrypted_notes"`      // Verschlüsselte Notizen (Base64, optional)
+	NotesIV           string                `json:"notes_iv"`             // IV für Notizen (Base64, optional)
+	NotesTag          string                `json:"notes_tag"`            // Tag für Notizen (Base64, optional)
+	Type              string                `json:"type"`                 // Eintragstyp (login, note, card, identity, api_key, ssh_key)
+	EncryptedName     string                `json:"encrypted_name"`       // Verschlüsselter Anzeigename (Base64, optional)
+	NameIV            string                `json:"name_iv"`              // IV für Anzeigename (Base64, optional)
+	NameTag           string                `json:"name_tag"`             // Tag für Anzeigename (Base64, optional)
+	EncryptedData     string                `json:"encrypted_data"`       // Verschlüsselter Payload (Base64, optional)
+	DataIV            string                `json:"data_iv"`              // IV für Payload (Base64, optional)
+	DataTag           string                `json:"data_tag"`             // Tag für Payload (Base64, optional)
+	DataVersion       int                   `json:"data_version"`         // Version des Payload-Formats
+	Fields            []CustomFieldResponse `json:"fields"`               // Benutzerdefinierte Felder in Anzeigereihenfolge
+	URIs              []URIResponse         `json:"uris"`                 // URIs mit Abgleichsregel in Anzeigereihenfolge
+	PasswordChangedAt *time.Time            `json:"password_changed_at"`  // Zeitpunkt der letzten Passwortänderung
+	LastUsedAt        *time.Time            `json:"last_used_at"`         // Zeitpunkt der letzten Verwendung
+	Revision          int                   `json:"revision"`             // Revision des Eintrags für optimistische Nebenläufigkeitskontrolle (auch als ETag)
+	DeletedAt         *time.Time            `json:"deleted_at,omitempty"` // Zeitpunkt der Verschiebung in den Papierkorb (nur im Papierkorb gesetzt)
+	CreatedAt         time.Time             `json:"created_at"`           // Erstellungszeitpunkt
+	UpdatedAt         time.Time             `json:"updated_at"`           // Letzter Aktualisierungszeitpunkt
+}
+
+// CustomFieldResponse definiert die Struktur der Antwort für ein benutzerdefiniertes Feld.
+type CustomFieldResponse struct {
+	ID             uint   `json:"id"`              // Eindeutige ID des Feldes
+	Position       int    `json:"position"`        // Position innerhalb des Eintrags
+	Type           string `json:"type"`            // Feldtyp (text, hidden, boolean, linked)
+	EncryptedName  string `json:"encrypted_name"`  // Verschlüsselter Feldname (Base64)
+	NameIV         string `json:"name_iv"`         // IV für Feldname (Base64)
+	NameTag        string `json:"name_tag"`        // Tag für Feldname (Base64)
+	EncryptedValue string `json:"encrypted_value"` // Verschlüsselter Feldwert (Base64, optional)
+	ValueIV        string `json:"value_iv"`        // IV für Feldwert (Base64, optional)
+	ValueTag       string `json:"value_tag"`       // Tag für Feldwert (Base64, optional)
+	LinkedTo       string `json:"linked_to"`       // Ziel eines verknüpften Feldes (optional)
+}
+
+// URIResponse definiert die Struktur der Antwort für eine URI eines Logins.
+type URIResponse struct {
+	ID           uint   `json:"id"`                      // Eindeutige ID der URI
+	Position     int    `json:"position"`                // Position innerhalb des Eintrags
+	URI          string `json:"uri"`                     // URI bzw. regulärer Ausdruck (leer, wenn verschlüsselt)
+	Match        string `json:"match"`                   // Abgleichsregel
+	EncryptedURI string `json:"encrypted_uri,omitempty"` // Verschlüsselte URI (Base64, optional)
+	URIIV        string `json:"uri_iv,omitempty"`        // IV für URI (Base64, optional)
+	URITag       string `json:"uri_tag,omitempty"`       // Tag für URI (Base64, optional)
+	DomainIndex  string `json:"domain_index,omitempty"`  // Blind-Index der registrierbaren Domain (optional)
+}
+
+// PasswordHistoryResponse definiert die Struktur der Antwort für ein früheres Passwort eines Eintrags.
+type PasswordHistoryResponse struct {
+	ID                uint       `json:"id"`                 // Eindeutige ID des Verlaufseintrags
+	EncryptedPassword string     `json:"encrypted_password"` // Früheres verschlüsseltes Passwort (Base64)
+	PasswordIV        string     `json:"password_iv"`        // IV des früheren Passworts (Base64)
+	PasswordTag       string     `json:"password_tag"`       // Tag des früheren Passworts (Base64)
+	ValidFrom         *time.Time `json:"valid_from"`         // Seit wann das Passwort gesetzt war (optional)
+	ReplacedAt        time.Time  `json:"replaced_at"`        // Zeitpunkt, an dem das Passwort ersetzt wurde
+}
+
+// PasswordSnapshot ist der verschlüsselte Inhalt eines Eintrags zu einem bestimmten Zeitpunkt.
+// Er entspricht dem Erstellungs-Schema, damit frühere Stände unverändert wieder angewendet werden können.
+type PasswordSnapshot = CreatePasswordRequest
+
+// PasswordRevisionResponse definiert die Struktur der Antwort für eine Revision eines Eintrags.
+type PasswordRevisionResponse struct {
+	Revision  int              `json:"revision"`   // Revisionsnummer innerhalb des Eintrags
+	Action    string           `json:"action"`     // Art der Änderung (create, update, restore)
+	DeviceID  string           `json:"device_id"`  // Geräte-ID des Clients (optional)
+	UserAgent string           `json:"user_agent"` // User-Agent der Sitzung
+	IPAddress string           `json:"ip_address"` // IP-Adresse der Sitzung
+	CreatedAt time.Time        `json:"created_at"` // Zeitpunkt der Änderung
+	Item      PasswordSnapshot `json:"item"`       // Verschlüsselter Stand des Eintrags nach der Änderung
+}
+
+// SyncResponse definiert die Struktur der Antwort der Delta-Synchronisation.
+// Große Änderungsmengen werden seitenweise geliefert: Solange HasMore gesetzt ist, wird die nächste
+// Seite mit NextCursor abgerufen.
+type SyncResponse struct {
+	Cursor     int64                     `json:"cursor"`                // Tresor-Revision, bis zu der alle Änderungen geliefert wurden (since des nächsten Aufrufs)
+	HasMore    bool                      `json:"has_more"`              // Weitere Seiten vorhanden
+	NextCursor string                    `json:"next_cursor,omitempty"` // Cursor der nächsten Seite (Query-Parameter cursor)
+	Changed    []PasswordResponse        `json:"changed"`               // Angelegte oder geänderte Einträge (Einträge im Papierkorb mit deleted_at)
+	Deleted    []PasswordTombstoneResult `json:"deleted"`               // Endgültig gelöschte Einträge
+}
+
+// PasswordTombstoneResult definiert die Struktur eines endgültig gelöschten Eintrags in der Synchronisation.
+type PasswordTombstoneResult struct {
+	ID        uint      `json:"id"`         // ID des gelöschten Eintrags
+	Revision  int64     `json:"revision"`   // Tresor-Revision der Löschung
+	DeletedAt time.Time `json:"deleted_at"` // Zeitpunkt der endgültigen Löschung
+}
+
+// PasswordListQuery definiert die Query-Parameter für das Auflisten von Passwort-Einträgen.
+// Zeitangaben werden im RFC-3339-Format erwartet.
+type PasswordListQuery struct {
+	Type          string `query:"type"`           // Nur Einträge dieses Typs (optional)
+	Host          string `query:"host"`           // Nur Einträge, deren URL zu diesem Host, einer Subdomain oder einer gleichwertigen Domain gehört (optional)
+	DomainIndex   string `query:"domain_index"`   // Nur Einträge mit diesem Blind-Index der Domain, mehrere kommagetrennt (optional)
+	CreatedAfter  string `query:"created_after"`  // Nur Einträge, die ab diesem Zeitpunkt erstellt wurden (optional)
+	CreatedBefore string `query:"created_before"` // Nur Einträge, die vor diesem Zeitpunkt erstellt wurden (optional)
+	UpdatedAfter  string `query:"updated_after"`  // Nur Einträge, die ab diesem Zeitpunkt geändert wurden (optional)
+	UpdatedBefore string `query:"updated_before"` // Nur Einträge, die vor diesem Zeitpunkt geändert wurden (optional)
+	Sort          string `query:"sort"`           // Sortierfeld: created (Standard), updated oder url
+	Order         string `query:"order"`          // Sortierrichtung: asc (Standard) oder desc
+	Limit         int    `query:"limit"`          // Seitengröße (Standard: 100, maximal 1000)
+	Cursor        string `query:"cursor"`         // Cursor der vorherigen Seite (X-Next-Cursor)
+	All           bool   `query:"all"`            // Alle Einträge ungepaginiert liefern (für ältere Clients)
+}
+
+// PasswordMatchResponse definiert die Struktur der Antwort auf eine URL-Abfrage der Browser-Erweiterung.
+type PasswordMatchResponse struct {
+	URL     string              `json:"url,omitempty"`    // Normalisierte URL (nur bei Abfrage über url)
+	Host    string              `json:"host,omitempty"`   // Normalisierter Host (Punycode, ohne www und Standardport)
+	Domain  string              `json:"domain,omitempty"` // Registrierbare Domain laut Public Suffix List
+	Matches []PasswordMatchItem `json:"matches"`          // Passende Einträge, genaueste und zuletzt verwendete zuerst
+}
+
+// PasswordMatchItem definiert einen zur URL passenden Eintrag.
+type PasswordMatchItem struct {
+	URI         string           `json:"uri"`         // Gespeicherte URI, über die der Eintrag gefunden wurde (leer, wenn verschlüsselt)
+	Match       string           `json:"match"`       // Angewendete Abgleichsregel
+	Specificity int              `json:"specificity"` // Spezifität des Treffers (1 = gleichwertige Domain, 2 = domain bis 6 = exact)
+	Item        PasswordResponse `json:"item"`        // Passender Eintrag
 }
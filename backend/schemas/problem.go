@@ -0,0 +1,16 @@
+package schemas
+
+// Problem beschreibt eine Fehlerantwort nach RFC 7807 (Content-Type application/problem+json).
+// Clients sollten auf code reagieren, die Texte in title und detail können sich ändern.
+type Problem struct {
+	Type       string                    `json:"type"`                 // URI des Problemtyps (about:blank, Bedeutung über code)
+	Title      string                    `json:"title"`                // Kurzbeschreibung des HTTP-Status
+	Status     int                       `json:"status"`               // HTTP-Status
+	Detail     string                    `json:"detail,omitempty"`     // Lesbare Beschreibung dieses Fehlers
+	Instance   string                    `json:"instance,omitempty"`   // Pfad der fehlgeschlagenen Anfrage
+	Code       string                    `json:"code"`                 // Stabiler, maschinenlesbarer Fehlercode (z.B. username_taken)
+	Error      string                    `json:"error"`                // Wie detail, für ältere Clients
+	Fields     []FieldError              `json:"fields,omitempty"`     // Feldbezogene Validierungsfehler
+	Violations []PasswordPolicyViolation `json:"violations,omitempty"` // Verletzte Regeln der Passwortrichtlinie
+	Current    *PasswordResponse         `json:"current,omitempty"`    // Aktueller Stand bei einem Revisionskonflikt
+}
@@ -0,0 +1,39 @@
+package schemas
+
+import "time"
+
+// ReuseReportResponse definiert die Struktur der Antwort des Berichts über wiederverwendete Passwörter.
+type ReuseReportResponse struct {
+	Groups         []ReuseGroupResponse `json:"groups"`          // Gruppen von Einträgen mit gleichem Passwort
+	ReusedItems    int                  `json:"reused_items"`    // Anzahl der betroffenen Einträge
+	CheckedItems   int64                `json:"checked_items"`   // Anzahl der Logins mit Fingerabdruck
+	UncheckedItems int64                `json:"unchecked_items"` // Anzahl der Logins ohne Fingerabdruck (nicht prüfbar)
+}
+
+// ReuseGroupResponse definiert eine Gruppe von Einträgen, die dasselbe Passwort verwenden.
+type ReuseGroupResponse struct {
+	ItemIDs []uint `json:"item_ids"` // IDs der Einträge (aufsteigend)
+	Count   int    `json:"count"`    // Anzahl der Einträge
+}
+
+// AgeReportResponse definiert die Struktur der Antwort des Passwort-Altersberichts.
+type AgeReportResponse struct {
+	MaxAgeDays   int                 `json:"max_age_days"`  // Alter in Tagen, ab dem ein Passwort als veraltet gilt
+	Buckets      []AgeBucketResponse `json:"buckets"`       // Verteilung der Logins auf Altersklassen
+	UnknownItems int                 `json:"unknown_items"` // Logins ohne Zeitpunkt der letzten Passwortänderung
+	StaleItems   []StaleItemResponse `json:"stale_items"`   // Veraltete Einträge, älteste zuerst
+}
+
+// AgeBucketResponse definiert eine Altersklasse des Passwort-Altersberichts.
+type AgeBucketResponse struct {
+	Label   string `json:"label"`    // Bezeichnung (under_90_days, 90_to_365_days, 1_to_2_years, over_2_years)
+	MinDays int    `json:"min_days"` // Untergrenze in Tagen (einschließlich)
+	Count   int    `json:"count"`    // Anzahl der Einträge
+}
+
+// StaleItemResponse definiert einen Eintrag mit veraltetem Passwort.
+type StaleItemResponse struct {
+	ID                uint       `json:"id"`                  // ID des Eintrags
+	PasswordChangedAt *time.Time `json:"password_changed_at"` // Zeitpunkt der letzten Passwortänderung
+	AgeDays           int        `json:"age_days"`            // Alter des Passworts in Tagen
+}
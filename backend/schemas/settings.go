@@ -0,0 +1,27 @@
+package schemas
+
+// DomainSettingsResponse definiert die Struktur der Antwort für die Gruppen gleichwertiger Domains.
+type DomainSettingsResponse struct {
+	GlobalGroups []GlobalDomainGroupResponse `json:"global_groups"` // Globale Gruppen (mit Ausschluss-Kennzeichen)
+	CustomGroups []CustomDomainGroupResponse `json:"custom_groups"` // Eigene Gruppen des Benutzers
+}
+
+// GlobalDomainGroupResponse definiert eine globale Gruppe gleichwertiger Domains.
+type GlobalDomainGroupResponse struct {
+	Key      string   `json:"key"`      // Stabiler Schlüssel der Gruppe
+	Domains  []string `json:"domains"`  // Gleichwertige Domains
+	Excluded bool     `json:"excluded"` // Vom Benutzer ausgeschlossen
+}
+
+// CustomDomainGroupResponse definiert eine eigene Gruppe gleichwertiger Domains.
+type CustomDomainGroupResponse struct {
+	ID      uint     `json:"id"`      // Eindeutige ID der Gruppe
+	Domains []string `json:"domains"` // Gleichwertige Domains (registrierbare Form)
+}
+
+// UpdateDomainSettingsRequest definiert die Struktur der Anfrage zum Ändern der Domain-Gruppen.
+// Übergebene Listen ersetzen den bisherigen Stand vollständig, fehlende bleiben unverändert.
+type UpdateDomainSettingsRequest struct {
+	CustomGroups         *[][]string `json:"custom_groups,omitempty"`          // Optional: eigene Gruppen (je mindestens zwei Domains)
+	ExcludedGlobalGroups *[]string   `json:"excluded_global_groups,omitempty"` // Optional: Schlüssel auszuschließender globaler Gruppen
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import "time"
+
+// ImportJobResponse definiert die Struktur der Antwort für den Fortschritt eines Streaming-Imports.
+type ImportJobResponse struct {
+	ID        uint      `json:"id"`              // Eindeutige ID des Imports
+	Status    string    `json:"status"`          // Status (running, completed, failed)
+	Processed int       `json:"processed"`       // Anzahl gelesener Einträge
+	Created   int       `json:"created"`         // Anzahl angelegter Einträge
+	Failed    int       `json:"failed"`          // Anzahl ungültiger Einträge
+	Error     string    `json:"error,omitempty"` // Grund für den Abbruch (nur bei failed)
+	CreatedAt time.Time `json:"created_at"`      // Beginn des Imports
+	UpdatedAt time.Time `json:"updated_at"`      // Letzte Fortschrittsaktualisierung
+}
+
+// ImportLineError beschreibt eine ungültige Zeile eines Streaming-Imports.
+type ImportLineError struct {
+	Line      int    `json:"line"`                 // Zeilennummer (ab 1)
+	ClientRef string `json:"client_ref,omitempty"` // Referenz des Clients, falls lesbar
+	Error     string `json:"error"`                // Fehlermeldung
+	Code      string `json:"code"`                 // Stabiler Fehlercode (wie in Problem-Antworten)
+}
+
+// ImportResponse definiert die Antwort auf einen Streaming-Import.
+type ImportResponse struct {
+	Job             ImportJobResponse `json:"job"`              // Endstand des Imports
+	Errors          []ImportLineError `json:"errors"`           // Ungültige Zeilen (begrenzt)
+	ErrorsTruncated bool              `json:"errors_truncated"` // Ob weitere ungültige Zeilen nicht aufgeführt sind
+}
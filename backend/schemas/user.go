@@ -4,17 +4,21 @@ import "time"
 
 // UserProfileResponse definiert die Struktur für die Antwort beim Abrufen eines Benutzerprofils.
 type UserProfileResponse struct {
-	ID           uint      `json:"id"`             // Eindeutige ID des Benutzers
-	Username     string    `json:"username"`       // Benutzername
-	Email        string    `json:"email"`          // E-Mail-Adresse
-	CreatedAt    time.Time `json:"created_at"`     // Erstellungszeitpunkt des Benutzers
-	UpdatedAt    time.Time `json:"updated_at"`     // Letzter Aktualisierungszeitpunkt des Benutzers
-	TwoFAEnabled bool      `json:"two_fa_enabled"` // Gibt an, ob 2FA aktiviert ist
+	ID                 uint      `json:"id"`                   // Eindeutige ID des Benutzers
+	Username           string    `json:"username"`             // Benutzername
+	Email              string    `json:"email"`                // E-Mail-Adresse
+	CreatedAt          time.Time `json:"created_at"`           // Erstellungszeitpunkt des Benutzers
+	UpdatedAt          time.Time `json:"updated_at"`           // Letzter Aktualisierungszeitpunkt des Benutzers
+	TwoFAEnabled       bool      `json:"two_fa_enabled"`       // Gibt an, ob 2FA aktiviert ist
+	TrashRetentionDays int       `json:"trash_retention_days"` // Aufbewahrungsdauer des Papierkorbs in Tagen (0 = unbegrenzt)
+	Locale             string    `json:"locale"`               // Bevorzugte Sprache für Meldungen und E-Mails
 }
 
 // UpdateProfileRequest definiert die Struktur der Anfrage zum Aktualisieren eines Benutzerprofils.
 type UpdateProfileRequest struct {
-	Username *string `json:"username,omitempty"` // Optionaler neuer Benutzername
-	Email    *string `json:"email,omitempty"`    // Optionale neue E-Mail-Adresse
-	Password *string `json:"password,omitempty"` // Optionales neues Passwort (nur für Passwortänderung)
+	Username           *string `json:"username,omitempty" validate:"omitempty,min=3,max=64"` // Optionaler neuer Benutzername
+	Email              *string `json:"email,omitempty" validate:"omitempty,email,max=254"`   // Optionale neue E-Mail-Adresse
+	Password           *string `json:"password,omitempty"`                                   // Optionales neues Passwort (nur für Passwortänderung)
+	TrashRetentionDays *int    `json:"trash_retention_days,omitempty"`                       // Optionale Aufbewahrungsdauer des Papierkorbs in Tagen (0 = unbegrenzt)
+	Locale             *string `json:"locale,omitempty" validate:"omitempty,oneof=de en"`    // Optionale bevorzugte Sprache (de, en)
 }
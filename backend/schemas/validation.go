@@ -0,0 +1,9 @@
+package schemas
+
+// FieldError beschreibt einen Validierungsfehler eines einzelnen Feldes der Anfrage.
+type FieldError struct {
+	Field   string `json:"field"`           // JSON-Pfad des Feldes, z.B. "email" oder "passwords.0.type"
+	Rule    string `json:"rule"`            // Verletzte Regel (required, email, max, oneof, unknown, type, ...)
+	Param   string `json:"param,omitempty"` // Parameter der Regel, z.B. die Höchstlänge
+	Message string `json:"message"`         // Lesbare Beschreibung des Fehlers
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import "time"
+
+// VaultSnapshotResponse definiert die Struktur der Antwort für einen Tresor-Snapshot.
+// Der Inhalt des Snapshots wird nicht ausgeliefert, nur seine Metadaten.
+type VaultSnapshotResponse struct {
+	ID        uint      `json:"id"`         // Eindeutige ID des Snapshots
+	Reason    string    `json:"reason"`     // Anlass (manual, batch_import, batch_update, master_password_change, pre_rollback)
+	ItemCount int       `json:"item_count"` // Anzahl der enthaltenen Einträge
+	CreatedAt time.Time `json:"created_at"` // Erstellungszeitpunkt
+}
+
+// VaultRollbackResponse definiert die Struktur der Antwort nach dem Zurücksetzen des Tresors.
+type VaultRollbackResponse struct {
+	RestoredItems  int                   `json:"restored_items"`  // Anzahl der wiederhergestellten Einträge
+	BackupSnapshot VaultSnapshotResponse `json:"backup_snapshot"` // Vor dem Zurücksetzen angelegter Snapshot des bisherigen Stands
+}
+
+// AccountBackupRestoreResponse definiert die Struktur der Antwort nach dem Wiederherstellen eines Backups.
+// Die Anfrage (POST /vault/backup/restore) enthält die Backup-Datei als Körper, den Signaturschlüssel im
+// Header X-Backup-Key und das Master-Passwort, unter dem das Backup erstellt wurde, Base64-kodiert im Header
+// X-Backup-Master-Password. Salt und Master-Passwort des Kontos werden auf die Werte des Backups gesetzt.
+type AccountBackupRestoreResponse struct {
+	RestoredItems      int           `json:"restored_items"`      // Anzahl der wiederhergestellten Einträge (inkl. Papierkorb)
+	SkippedAttachments int           `json:"skipped_attachments"` // Anhänge, deren Inhalt neu hochgeladen werden muss
+	IDMap              map[uint]uint `json:"id_map"`              // Zuordnung der IDs im Backup zu den neuen IDs
+	BackupCreatedAt    time.Time     `json:"backup_created_at"`   // Erstellungszeitpunkt des Backups
+	SourceUsername     string        `json:"source_username"`     // Benutzername des ursprünglichen Kontos
+}
@@ -18,11 +18,14 @@ import (
 // Kryptographische Konstanten für PBKDF2-Passwort-Hashing
 // Diese Parameter müssen mit dem Frontend synchron bleiben!
 const (
-	pbkdf2Iterations = 250000 // PBKDF2-Iterationen: Balance zwischen Sicherheit und Performance
-	pbkdf2KeyLen     = 32     // Schlüssellänge für AES-256 (32 Bytes = 256 Bit)
+	PBKDF2Iterations = 250000 // PBKDF2-Iterationen: Balance zwischen Sicherheit und Performance
+	PBKDF2KeyLen     = 32     // Schlüssellänge für AES-256 (32 Bytes = 256 Bit)
 	PBKDF2SaltLen    = 16     // Salt-Länge: 128 Bit für ausreichende Entropie
 )
 
+// KDFAlgorithm bezeichnet das Verfahren der client-seitigen Schlüsselableitung (z.B. in Backups)
+const KDFAlgorithm = "PBKDF2-SHA256"
+
 // HashPassword erstellt PBKDF2-Hash für Passwort-Verifikation
 // Verwendet SHA-256 als PRF (Pseudo-Random Function) für hohe Sicherheit
 // Rückgabe als Base64 für einfache Speicherung und Übertragung
@@ -33,7 +36,7 @@ func HashPassword(password, salt string) (string, error) {
 	}
 
 	// PBKDF2 mit SHA-256: 250k Iterationen für GPU-resistenten Schutz
-	key := pbkdf2.Key([]byte(password), saltBytes, pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
+	key := pbkdf2.Key([]byte(password), saltBytes, PBKDF2Iterations, PBKDF2KeyLen, sha256.New)
 
 	// Base64-Kodierung für Datenbank-Speicherung
 	keyBase64 := base64.RawStdEncoding.EncodeToString(key)
@@ -63,7 +66,7 @@ func VerifyPassword(plainPassword string, hashedPassword string, salt string) (b
 	}
 
 	// PBKDF2-Hash vom Klartext-Passwort mit identischen Parametern berechnen
-	comparisonHashBytes := pbkdf2.Key([]byte(plainPassword), saltBytes, pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
+	comparisonHashBytes := pbkdf2.Key([]byte(plainPassword), saltBytes, PBKDF2Iterations, PBKDF2KeyLen, sha256.New)
 	log.Printf("VerifyPassword: Derived hash from plaintext and salt: %s", base64.RawStdEncoding.EncodeToString(comparisonHashBytes)) // Debug
 
 	// Konstant-Zeit-Vergleich gegen Timing-Angriffe
@@ -0,0 +1,388 @@
+// Schätzung der Passwortstärke nach dem Vorbild von zxcvbn
+// Zerlegt ein Passwort in erratbare Muster (Wörterbuch, Sequenzen, Wiederholungen, Tastaturfolgen, Jahreszahlen)
+// und schätzt die Anzahl der Rateversuche eines Angreifers, der diese Muster kennt
+package security
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Eingebettete Liste häufiger Passwörter und Wörter (Rang = Position in der Liste)
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// Grenzen und Kenngrößen der Schätzung
+const (
+	maxAnalyzedRunes = 256 // Längere Passwörter werden nur bis hierhin analysiert (untere Schranke)
+	minPatternLength = 3   // Kürzere Teilstücke gelten nicht als Muster
+	maxWordRunes     = 64  // Längere Teilstücke werden nicht im Wörterbuch gesucht
+	minKeyboardRun   = 4   // Mindestlänge einer Tastaturfolge
+	minYearSpace     = 20  // Mindestabstand zum Bezugsjahr bei der Bewertung von Jahreszahlen
+)
+
+// referenceYear liefert das Bezugsjahr für Jahreszahlen (in Tests austauschbar)
+var referenceYear = func() int { return time.Now().Year() }
+
+// Score-Schwellen in Rateversuchen (wie zxcvbn): < 10^3, < 10^6, < 10^8, < 10^10, darüber
+var scoreThresholds = []float64{1e3, 1e6, 1e8, 1e10}
+
+// Tastaturzeilen (QWERTZ und QWERTY) für die Erkennung von Tastaturfolgen
+var keyboardRows = []string{
+	"1234567890ß", "qwertzuiopü", "asdfghjklöä", "yxcvbnm",
+	"qwertyuiop", "asdfghjkl", "zxcvbnm",
+}
+
+// Ersetzungen in l33t-Schreibweise; mehrdeutige Zeichen (1 = i oder l) werden in beiden Varianten geprüft
+var leetVariants = []map[rune]rune{
+	{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+	{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'l', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+}
+
+// commonPasswordRanks ordnet jedem Eintrag der eingebetteten Liste seinen Rang zu.
+var commonPasswordRanks = func() map[string]int {
+	ranks := make(map[string]int)
+	rank := 0
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		word := strings.TrimSpace(line)
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		rank++
+		if _, exists := ranks[word]; !exists {
+			ranks[word] = rank
+		}
+	}
+	return ranks
+}()
+
+// Strength ist das Ergebnis einer Stärkeschätzung.
+type Strength struct {
+	Guesses float64 // Geschätzte Anzahl benötigter Rateversuche
+	Bits    float64 // Entropie in Bit (log2 der Rateversuche)
+	Score   int     // 0 (sehr schwach) bis 4 (sehr stark)
+}
+
+// patternMatch ist ein erkanntes Muster im Passwort (Runen-Positionen i bis j einschließlich).
+type patternMatch struct {
+	i, j int
+	bits float64 // log2 der Rateversuche für dieses Teilstück
+}
+
+// EstimateStrength schätzt die Stärke eines Passworts. userInputs (z.B. Benutzername und E-Mail)
+// werden wie die häufigsten Wörterbucheinträge behandelt, da ein Angreifer sie kennt.
+func EstimateStrength(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	if len(runes) > maxAnalyzedRunes {
+		runes = runes[:maxAnalyzedRunes]
+	}
+	if len(runes) == 0 {
+		return Strength{Guesses: 1}
+	}
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	dictionary := userDictionary(userInputs)
+	var matches []patternMatch
+	matches = append(matches, dictionaryMatches(runes, lower, dictionary)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	// Kürzeste Zerlegung in Muster und einzeln geratene Zeichen (dynamische Programmierung in Bit)
+	charBits := math.Log2(bruteforceCardinality(runes))
+	type step struct {
+		bits     float64
+		patterns int
+	}
+	best := make([]step, len(runes)+1)
+	for k := 1; k <= len(runes); k++ {
+		best[k] = step{bits: best[k-1].bits + charBits, patterns: best[k-1].patterns}
+		for _, m := range matches {
+			if m.j != k-1 {
+				continue
+			}
+			candidate := step{bits: best[m.i].bits + m.bits, patterns: best[m.i].patterns + 1}
+			if candidate.bits+log2Factorial(candidate.patterns) < best[k].bits+log2Factorial(best[k].patterns) {
+				best[k] = candidate
+			}
+		}
+	}
+
+	// Die Reihenfolge der Muster muss zusätzlich erraten werden
+	bits := best[len(runes)].bits + log2Factorial(best[len(runes)].patterns)
+	guesses := math.Pow(2, bits)
+	score := 0
+	for _, threshold := range scoreThresholds {
+		if guesses >= threshold {
+			score++
+		}
+	}
+	return Strength{Guesses: guesses, Bits: bits, Score: score}
+}
+
+// userDictionary zerlegt benutzerbezogene Angaben in Wörterbucheinträge mit Rang 1.
+func userDictionary(userInputs []string) map[string]int {
+	dictionary := make(map[string]int)
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if len([]rune(input)) >= minPatternLength {
+			dictionary[input] = 1
+		}
+		for _, part := range strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len([]rune(part)) >= minPatternLength {
+				dictionary[part] = 1
+			}
+		}
+	}
+	return dictionary
+}
+
+// dictionaryMatches findet Wörterbucheinträge, auch rückwärts und in l33t-Schreibweise.
+func dictionaryMatches(runes, lower []rune, dictionary map[string]int) []patternMatch {
+	var matches []patternMatch
+	for i := 0; i < len(lower); i++ {
+		for j := i + minPatternLength - 1; j < len(lower) && j-i < maxWordRunes; j++ {
+			word := lower[i : j+1]
+			bits := math.Inf(1)
+			if rank, ok := lookupWord(string(word), dictionary); ok {
+				bits = math.Log2(float64(rank))
+			}
+			if rank, ok := lookupWord(reverseString(word), dictionary); ok {
+				bits = math.Min(bits, math.Log2(float64(rank))+1)
+			}
+			for _, variant := range leetVariants {
+				if unleeted, substituted := unleet(word, variant); substituted {
+					if rank, ok := lookupWord(unleeted, dictionary); ok {
+						bits = math.Min(bits, math.Log2(float64(rank))+1)
+					}
+				}
+			}
+			if !math.IsInf(bits, 1) {
+				matches = append(matches, patternMatch{i: i, j: j, bits: bits + uppercaseBits(runes[i:j+1])})
+			}
+		}
+	}
+	return matches
+}
+
+// lookupWord sucht ein Wort in den benutzerbezogenen Angaben und der Liste häufiger Passwörter.
+func lookupWord(word string, dictionary map[string]int) (int, bool) {
+	if rank, ok := dictionary[word]; ok {
+		return rank, true
+	}
+	rank, ok := commonPasswordRanks[word]
+	return rank, ok
+}
+
+// unleet ersetzt l33t-Zeichen durch Buchstaben und meldet, ob ersetzt wurde.
+func unleet(word []rune, substitutions map[rune]rune) (string, bool) {
+	result := make([]rune, len(word))
+	substituted := false
+	for i, r := range word {
+		if replacement, ok := substitutions[r]; ok {
+			r = replacement
+			substituted = true
+		}
+		result[i] = r
+	}
+	return string(result), substituted
+}
+
+// uppercaseBits bewertet die Groß-/Kleinschreibung eines Wortes. Übliche Varianten (alles klein,
+// alles groß, erster oder letzter Buchstabe groß) kosten ein Bit, sonst zählen die möglichen Kombinationen.
+func uppercaseBits(word []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 0
+	}
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))) {
+		return 1
+	}
+	variations := 0.0
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return math.Log2(variations)
+}
+
+// sequenceMatches findet auf- und absteigende Zeichenfolgen wie "abc", "987" oder "xyz".
+func sequenceMatches(lower []rune) []patternMatch {
+	var matches []patternMatch
+	for i := 0; i < len(lower)-1; {
+		delta := lower[i+1] - lower[i]
+		j := i + 1
+		if delta == 1 || delta == -1 {
+			for j+1 < len(lower) && lower[j+1]-lower[j] == delta {
+				j++
+			}
+			if j-i+1 >= minPatternLength {
+				base := 26.0
+				switch {
+				case strings.ContainsRune("az019", lower[i]):
+					base = 4 // Naheliegende Startzeichen
+				case unicode.IsDigit(lower[i]):
+					base = 10
+				}
+				bits := math.Log2(base * float64(j-i+1))
+				if delta < 0 {
+					bits++
+				}
+				matches = append(matches, patternMatch{i: i, j: j, bits: bits})
+			}
+		}
+		i = j
+	}
+	return matches
+}
+
+// repeatMatches findet Wiederholungen desselben Zeichens wie "aaa" oder "!!!!".
+func repeatMatches(runes []rune) []patternMatch {
+	var matches []patternMatch
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[i] {
+			j++
+		}
+		if j-i+1 >= minPatternLength {
+			bits := math.Log2(bruteforceCardinality(runes[i:i+1]) * float64(j-i+1))
+			matches = append(matches, patternMatch{i: i, j: j, bits: bits})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// keyboardMatches findet Folgen benachbarter Tasten einer Tastaturzeile wie "asdf" oder "trewq".
+func keyboardMatches(lower []rune) []patternMatch {
+	var matches []patternMatch
+	for _, row := range keyboardRows {
+		keys := []rune(row)
+		position := make(map[rune]int, len(keys))
+		for index, key := range keys {
+			position[key] = index
+		}
+		for i := 0; i < len(lower); {
+			j := i
+			for j+1 < len(lower) && adjacentKeys(position, lower[j], lower[j+1]) {
+				j++
+			}
+			if j-i+1 >= minKeyboardRun {
+				// Startposition auf der Tastatur und Richtungswechsel raten
+				bits := math.Log2(float64(len(keys)*2)) + float64(j-i)
+				matches = append(matches, patternMatch{i: i, j: j, bits: bits})
+			}
+			i = j + 1
+		}
+	}
+	return matches
+}
+
+// adjacentKeys prüft, ob zwei Tasten in derselben Zeile nebeneinander liegen.
+func adjacentKeys(position map[rune]int, a, b rune) bool {
+	pa, okA := position[a]
+	pb, okB := position[b]
+	return okA && okB && (pa-pb == 1 || pb-pa == 1)
+}
+
+// yearMatches findet Jahreszahlen zwischen 1900 und 2099, die nur wenige Versuche kosten.
+func yearMatches(runes []rune) []patternMatch {
+	reference := referenceYear()
+	var matches []patternMatch
+	for i := 0; i+4 <= len(runes); i++ {
+		year := 0
+		for _, r := range runes[i : i+4] {
+			if r < '0' || r > '9' {
+				year = -1
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if year >= 1900 && year <= 2099 {
+			space := max(abs(year-reference), minYearSpace)
+			matches = append(matches, patternMatch{i: i, j: i + 3, bits: math.Log2(float64(space))})
+		}
+	}
+	return matches
+}
+
+// bruteforceCardinality liefert die Größe des Zeichenvorrats, aus dem die Zeichen stammen.
+func bruteforceCardinality(runes []rune) float64 {
+	var lower, upper, digits, symbols, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digits = true
+		case r < unicode.MaxASCII:
+			symbols = true
+		default:
+			other = true
+		}
+	}
+	cardinality := 0.0
+	for _, class := range []struct {
+		present bool
+		size    float64
+	}{{lower, 26}, {upper, 26}, {digits, 10}, {symbols, 33}, {other, 100}} {
+		if class.present {
+			cardinality += class.size
+		}
+	}
+	return cardinality
+}
+
+// reverseString kehrt die Reihenfolge der Runen um.
+func reverseString(runes []rune) string {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return string(reversed)
+}
+
+// log2Factorial berechnet log2(n!).
+func log2Factorial(n int) float64 {
+	result := 0.0
+	for k := 2; k <= n; k++ {
+		result += math.Log2(float64(k))
+	}
+	return result
+}
+
+// binomial berechnet den Binomialkoeffizienten n über k.
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// abs liefert den Betrag einer ganzen Zahl.
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
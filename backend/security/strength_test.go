@@ -0,0 +1,99 @@
+package security
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		minBits  float64
+		maxBits  float64
+		score    int
+	}{
+		{"leer", "", 0, 0, 0},
+		{"häufiges Passwort", "password", 0, 5, 0},
+		{"l33t-Schreibweise", "P@ssw0rd", 0, 10, 0},
+		{"rückwärts", "drowssap", 0, 10, 0},
+		{"Sequenz", "abcdefgh", 0, 10, 0},
+		{"Wiederholung", "aaaaaaaaaa", 0, 10, 0},
+		{"Tastaturfolge QWERTY", "qwertyuiop", 0, 10, 0},
+		{"Tastaturfolge QWERTZ mit Ziffern", "qwertz123", 0, 10, 0},
+		{"Jahreszahl", "1987", 0, 10, 0},
+		{"Wort und Jahreszahl", "sommer2024", 0, 20, 1},
+		{"Passphrase", "correct horse battery staple", 60, math.Inf(1), 4},
+		{"zufällig", "xK9#mQ2$vL7!pR4@", 80, math.Inf(1), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateStrength(tt.password)
+			if got.Bits < tt.minBits || got.Bits > tt.maxBits {
+				t.Errorf("Bits = %.1f, erwartet %.0f bis %.0f", got.Bits, tt.minBits, tt.maxBits)
+			}
+			if got.Score != tt.score {
+				t.Errorf("Score = %d, erwartet %d", got.Score, tt.score)
+			}
+			if math.Abs(math.Log2(got.Guesses)-got.Bits) > 1e-9 {
+				t.Errorf("Bits = %.3f passt nicht zu Guesses = %g", got.Bits, got.Guesses)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthUserInputs(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+	}{
+		{"alice2024", []string{"alice", "alice@example.com"}},
+		{"Alice2024x", []string{"ALICE"}},
+		{"trustme-bob", []string{"bob@trustme.example"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			without := EstimateStrength(tt.password)
+			with := EstimateStrength(tt.password, tt.userInputs...)
+			if with.Bits >= without.Bits {
+				t.Errorf("Bits mit Benutzerangaben = %.1f, erwartet weniger als %.1f", with.Bits, without.Bits)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthMonotonicScore(t *testing.T) {
+	// Ein zusätzliches zufälliges Zeichen darf die Schätzung nicht verschlechtern
+	password := ""
+	previous := EstimateStrength(password)
+	for _, r := range "k7#Qz9!mW2" {
+		password += string(r)
+		current := EstimateStrength(password)
+		if current.Bits < previous.Bits || current.Score < previous.Score {
+			t.Errorf("%q: Bits %.1f/Score %d, vorher %.1f/%d", password, current.Bits, current.Score, previous.Bits, previous.Score)
+		}
+		previous = current
+	}
+}
+
+func TestEstimateStrengthLongPassword(t *testing.T) {
+	// Überlange Passwörter werden nur bis maxAnalyzedRunes analysiert
+	long := strings.Repeat("xK9#mQ2$", maxAnalyzedRunes)
+	if got, want := EstimateStrength(long), EstimateStrength(long[:maxAnalyzedRunes]); got != want {
+		t.Errorf("EstimateStrength(überlang) = %+v, erwartet %+v", got, want)
+	}
+}
+
+func TestEstimateStrengthReferenceYear(t *testing.T) {
+	// Jahreszahlen nahe dem Bezugsjahr sind leichter zu erraten als weit entfernte
+	defer func(previous func() int) { referenceYear = previous }(referenceYear)
+
+	referenceYear = func() int { return 2030 }
+	near := EstimateStrength("2031")
+	referenceYear = func() int { return 2090 }
+	far := EstimateStrength("2031")
+	if near.Bits >= far.Bits {
+		t.Errorf("Bits für 2031 mit Bezugsjahr 2030 = %.1f, erwartet weniger als mit Bezugsjahr 2090 (%.1f)", near.Bits, far.Bits)
+	}
+}
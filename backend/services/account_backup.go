@@ -0,0 +1,336 @@
+package services
+
+import (
+	"backend/models"
+	"backend/security"
+	"backend/validation"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kennung, Version und Signaturverfahren des Backup-Formats
+const (
+	AccountBackupFormat    = "trustme-backup"
+	accountBackupVersion   = 1
+	accountBackupSignature = "HMAC-SHA256"
+)
+
+// Mindestlänge des Signaturschlüssels in Bytes
+const minBackupKeyLength = 32
+
+// Fehler bei Backup-Export und -Wiederherstellung
+var (
+	ErrInvalidBackupKey     = NewError(ErrValidation, "invalid_backup_key", "Signaturschlüssel für das Backup fehlt oder ist zu kurz (mindestens 32 Bytes)")
+	ErrInvalidBackup        = NewError(ErrValidation, "invalid_backup", "Backup-Datei ist ungültig")
+	ErrBackupSignature      = NewError(ErrValidation, "backup_signature_invalid", "Signatur des Backups ist ungültig, die Datei wurde verändert oder der Schlüssel ist falsch")
+	ErrUnsupportedBackupKDF = NewError(ErrValidation, "unsupported_backup_kdf", "Schlüsselableitung des Backups wird von diesem Server nicht unterstützt")
+	ErrBackupMasterPassword = NewError(ErrUnauthorized, "invalid_backup_master_password", "Master-Passwort des Backups ist falsch")
+	ErrAccountNotEmpty      = NewError(ErrConflict, "account_not_empty", "Backup kann nur in ein leeres Konto wiederhergestellt werden")
+)
+
+// accountBackupFile ist die äußere Hülle einer Backup-Datei. Die Signatur wird über die
+// unveränderten Bytes von Payload gebildet, damit keine Kanonisierung des JSON nötig ist
+type accountBackupFile struct {
+	Format             string          `json:"format"`
+	Version            int             `json:"version"`
+	SignatureAlgorithm string          `json:"signature_algorithm"`
+	Payload            json.RawMessage `json:"payload"`
+	Signature          string          `json:"signature"`
+}
+
+// accountBackupPayload enthält alle Daten eines Kontos, ausschließlich als Chiffretext
+type accountBackupPayload struct {
+	CreatedAt   time.Time                 `json:"created_at"`
+	Account     accountBackupAccount      `json:"account"`
+	KDF         accountBackupKDF          `json:"kdf"`
+	Items       []vaultSnapshotItem       `json:"items" validate:"dive"`
+	Attachments []accountBackupAttachment `json:"attachments"`
+}
+
+// accountBackupAccount enthält die übertragbaren Einstellungen des Kontos. Der bcrypt-Hash des
+// Master-Passworts wird mit dem Salt wiederhergestellt, da nur beide zusammen zu den Einträgen passen
+type accountBackupAccount struct {
+	Username           string `json:"username"`
+	Email              string `json:"email"`
+	TrashRetentionDays int    `json:"trash_retention_days"`
+	MasterPasswordHash string `json:"master_password_hash"`
+}
+
+// accountBackupKDF beschreibt die client-seitige Schlüsselableitung, mit der die Einträge verschlüsselt wurden
+type accountBackupKDF struct {
+	Algorithm  string `json:"algorithm"`
+	Iterations int    `json:"iterations"`
+	KeyLength  int    `json:"key_length"`
+	Salt       string `json:"salt"`
+}
+
+// accountBackupAttachment enthält die Metadaten eines Anhangs. Der Inhalt ist nicht Teil des Backups
+type accountBackupAttachment struct {
+	PasswordID        uint      `json:"password_id"`
+	EncryptedFileName string    `json:"encrypted_file_name"`
+	FileNameIV        string    `json:"file_name_iv"`
+	FileNameTag       string    `json:"file_name_tag"`
+	Size              int64     `json:"size"`
+	SHA256            string    `json:"sha256"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// BackupRestoreResult fasst das Ergebnis einer Wiederherstellung zusammen.
+type BackupRestoreResult struct {
+	RestoredItems      int           // Anzahl der wiederhergestellten Einträge (inkl. Papierkorb)
+	SkippedAttachments int           // Anhänge, deren Inhalt nicht im Backup enthalten ist und neu hochgeladen werden muss
+	IDMap              map[uint]uint // Zuordnung der IDs im Backup zu den neuen IDs
+	BackupCreatedAt    time.Time     // Erstellungszeitpunkt des Backups
+	SourceUsername     string        // Benutzername des Kontos, aus dem das Backup stammt
+}
+
+// ExportAccountBackup erstellt eine signierte, versionierte Backup-Datei mit allen Einträgen
+// (inkl. Papierkorb, Felder und Passwort-Verlauf), Anhang-Metadaten sowie KDF-Parametern und Salt.
+// Der Signaturschlüssel wird vom Client aus seinem Master-Schlüssel abgeleitet und nicht gespeichert;
+// nur wer ihn kennt, kann das Backup prüfen oder eine gültige Signatur erzeugen.
+func (s *PasswordService) ExportAccountBackup(userID uint, key []byte) ([]byte, error) {
+	if len(key) < minBackupKeyLength {
+		return nil, ErrInvalidBackupKey
+	}
+
+	payload := accountBackupPayload{CreatedAt: time.Now().UTC()}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return fmt.Errorf("Benutzer %d nicht gefunden: %w", userID, err)
+		}
+		payload.Account = accountBackupAccount{
+			Username:           user.Username,
+			Email:              user.Email,
+			TrashRetentionDays: user.TrashRetentionDays,
+			MasterPasswordHash: user.HashedMasterPassword,
+		}
+		payload.KDF = accountBackupKDF{
+			Algorithm:  security.KDFAlgorithm,
+			Iterations: security.PBKDF2Iterations,
+			KeyLength:  security.PBKDF2KeyLen,
+			Salt:       user.Salt,
+		}
+
+		var err error
+		if payload.Items, err = collectVaultItems(tx, userID); err != nil {
+			return err
+		}
+
+		var attachments []models.Attachment
+		if err := tx.Where("user_id = ?", userID).Order("id ASC").Find(&attachments).Error; err != nil {
+			return fmt.Errorf("Fehler beim Lesen der Anhänge für Benutzer %d: %w", userID, err)
+		}
+		payload.Attachments = make([]accountBackupAttachment, 0, len(attachments))
+		for _, attachment := range attachments {
+			payload.Attachments = append(payload.Attachments, accountBackupAttachment{
+				PasswordID:        attachment.PasswordID,
+				EncryptedFileName: attachment.EncryptedFileName,
+				FileNameIV:        attachment.FileNameIV,
+				FileNameTag:       attachment.FileNameTag,
+				Size:              attachment.Size,
+				SHA256:            attachment.SHA256,
+				CreatedAt:         attachment.CreatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := encodeBackupFile(key, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Serialisieren des Backups für Benutzer %d: %w", userID, err)
+	}
+	return backup, nil
+}
+
+// RestoreAccountBackup prüft die Signatur einer Backup-Datei und stellt ihren Inhalt in einem
+// leeren Konto wieder her. Einträge erhalten neue IDs und werden wie bei jedem anderen Schreibzugriff
+// validiert. masterPassword muss das Master-Passwort sein, unter dem das Backup erstellt wurde:
+// Salt und Passwort-Hash des Kontos werden auf die Werte des Backups gesetzt, damit sich der Client
+// danach mit diesem Passwort anmelden und die Einträge entschlüsseln kann.
+// Anhänge werden nicht wiederhergestellt, da ihr Inhalt nicht Teil des Backups ist.
+func (s *PasswordService) RestoreAccountBackup(userID uint, key, data []byte, masterPassword string, client ClientInfo) (*BackupRestoreResult, error) {
+	if len(key) < minBackupKeyLength {
+		return nil, ErrInvalidBackupKey
+	}
+
+	payload, err := decodeBackupFile(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ohne das passende Master-Passwort könnte sich der Benutzer anmelden, aber nichts entschlüsseln
+	if bcrypt.CompareHashAndPassword([]byte(payload.Account.MasterPasswordHash), []byte(masterPassword)) != nil {
+		return nil, ErrBackupMasterPassword
+	}
+
+	result := &BackupRestoreResult{
+		SkippedAttachments: len(payload.Attachments),
+		IDMap:              make(map[uint]uint, len(payload.Items)),
+		BackupCreatedAt:    payload.CreatedAt,
+		SourceUsername:     payload.Account.Username,
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Benutzerzeile sperren, damit parallel keine Einträge angelegt werden
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("Fehler beim Sperren des Benutzers %d: %w", userID, err)
+		}
+		var existing int64
+		if err := tx.Unscoped().Model(&models.Password{}).Where("user_id = ?", userID).Count(&existing).Error; err != nil {
+			return fmt.Errorf("Fehler beim Prüfen des Tresors für Benutzer %d: %w", userID, err)
+		}
+		if existing > 0 {
+			return ErrAccountNotEmpty
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"salt":                   payload.KDF.Salt,
+			"hashed_master_password": payload.Account.MasterPasswordHash,
+			"trash_retention_days":   payload.Account.TrashRetentionDays,
+		}).Error; err != nil {
+			return fmt.Errorf("Fehler beim Übernehmen der Kontoeinstellungen: %w", err)
+		}
+
+		// Alle wiederhergestellten Einträge gehören zur selben Tresor-Revision
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		restored := make([]models.Password, 0, len(payload.Items))
+		for i := range payload.Items {
+			item := &payload.Items[i]
+			if _, ok := result.IDMap[item.ID]; ok {
+				return fmt.Errorf("%w: Eintrag %d ist mehrfach enthalten", ErrInvalidBackup, item.ID)
+			}
+			password, err := restoreBackupItem(tx, userID, item, revision)
+			if err != nil {
+				return fmt.Errorf("Eintrag %d: %w", item.ID, err)
+			}
+			result.IDMap[item.ID] = password.ID
+			restored = append(restored, *password)
+		}
+		result.RestoredItems = len(restored)
+
+		return s.recordRevisions(tx, restored, models.RevisionActionCreate, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encodeBackupFile serialisiert den Inhalt eines Backups und signiert ihn mit key.
+func encodeBackupFile(key []byte, payload *accountBackupPayload) ([]byte, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(accountBackupFile{
+		Format:             AccountBackupFormat,
+		Version:            accountBackupVersion,
+		SignatureAlgorithm: accountBackupSignature,
+		Payload:            encoded,
+		Signature:          base64.StdEncoding.EncodeToString(signBackup(key, encoded)),
+	})
+}
+
+// decodeBackupFile prüft Format, Version und Signatur einer Backup-Datei und dekodiert danach ihren Inhalt.
+func decodeBackupFile(key, data []byte) (*accountBackupPayload, error) {
+	var file accountBackupFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if file.Format != AccountBackupFormat || file.SignatureAlgorithm != accountBackupSignature {
+		return nil, fmt.Errorf("%w: unbekanntes Format", ErrInvalidBackup)
+	}
+	if file.Version != accountBackupVersion {
+		return nil, fmt.Errorf("%w: unbekannte Formatversion %d", ErrInvalidBackup, file.Version)
+	}
+
+	// Signatur vor dem Auswerten des Inhalts prüfen
+	signature, err := base64.StdEncoding.DecodeString(file.Signature)
+	if err != nil || !hmac.Equal(signature, signBackup(key, file.Payload)) {
+		return nil, ErrBackupSignature
+	}
+	return decodeBackupPayload(file.Payload)
+}
+
+// decodeBackupPayload dekodiert den signierten Inhalt eines Backups und prüft Schlüsselableitung,
+// Passwort-Hash und alle Einträge nach denselben Regeln wie beim Anlegen. Fehler der Einträge werden
+// gesammelt mit ihrer Position gemeldet (items[i].item.…)
+func decodeBackupPayload(data []byte) (*accountBackupPayload, error) {
+	var payload accountBackupPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if payload.KDF.Algorithm != security.KDFAlgorithm || payload.KDF.Iterations != security.PBKDF2Iterations ||
+		payload.KDF.KeyLength != security.PBKDF2KeyLen || payload.KDF.Salt == "" {
+		return nil, ErrUnsupportedBackupKDF
+	}
+	if _, err := bcrypt.Cost([]byte(payload.Account.MasterPasswordHash)); err != nil {
+		return nil, fmt.Errorf("%w: Hash des Master-Passworts fehlt oder ist ungültig", ErrInvalidBackup)
+	}
+	if err := validation.Struct(&payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// restoreBackupItem legt einen Eintrag aus einem Backup mit neuer ID samt Feldern und Verlauf an.
+func restoreBackupItem(tx *gorm.DB, userID uint, item *vaultSnapshotItem, syncRevision int64) (*models.Password, error) {
+	password, err := newPasswordFromRequest(userID, &item.Item)
+	if err != nil {
+		return nil, err
+	}
+	password.PasswordChangedAt = item.PasswordChangedAt
+	password.CreatedAt = item.CreatedAt
+	password.Revision = max(item.Revision, 1)
+	password.SyncRevision = syncRevision
+	if item.DeletedAt != nil {
+		password.DeletedAt = gorm.DeletedAt{Time: *item.DeletedAt, Valid: true}
+	}
+
+	// Felder und URIs werden über die Verknüpfung mit angelegt
+	if err := tx.Create(password).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Anlegen des Eintrags: %w", err)
+	}
+
+	if len(item.History) > 0 {
+		history := make([]models.PasswordHistory, 0, len(item.History))
+		for _, entry := range item.History {
+			history = append(history, models.PasswordHistory{
+				PasswordID:        password.ID,
+				UserID:            userID,
+				EncryptedPassword: entry.EncryptedPassword,
+				PasswordIV:        entry.PasswordIV,
+				PasswordTag:       entry.PasswordTag,
+				ValidFrom:         entry.ValidFrom,
+				CreatedAt:         entry.CreatedAt,
+			})
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Speichern des Passwort-Verlaufs: %w", err)
+		}
+	}
+	return password, nil
+}
+
+// signBackup berechnet die HMAC-SHA256-Signatur über den Payload eines Backups.
+func signBackup(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
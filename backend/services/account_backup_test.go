@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/schemas"
+	"backend/security"
+	"backend/validation"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testBackupKey ist ein gültiger Signaturschlüssel (32 Bytes).
+var testBackupKey = bytes.Repeat([]byte{0x42}, minBackupKeyLength)
+
+// b64 kodiert n Bytes mit dem Wert fill als Standard-Base64.
+func b64(fill byte, n int) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, n))
+}
+
+// testBackupPayload liefert einen gültigen Backup-Inhalt mit einem Login unter dem Master-Passwort "geheim".
+func testBackupPayload(t *testing.T) *accountBackupPayload {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("geheim"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv, ciphertext, tag := b64(0x01, schemas.GCMIVSize), b64(0x02, 5), b64(0x03, schemas.GCMTagSize)
+	return &accountBackupPayload{
+		CreatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Account:   accountBackupAccount{Username: "alice", Email: "alice@example.com", TrashRetentionDays: 30, MasterPasswordHash: string(hash)},
+		KDF: accountBackupKDF{
+			Algorithm:  security.KDFAlgorithm,
+			Iterations: security.PBKDF2Iterations,
+			KeyLength:  security.PBKDF2KeyLen,
+			Salt:       b64(0x04, security.PBKDF2SaltLen),
+		},
+		Items: []vaultSnapshotItem{{
+			ID:       1,
+			Revision: 2,
+			Item: schemas.PasswordSnapshot{
+				Type:              models.ItemTypeLogin,
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+				EncryptedPassword: ciphertext, PasswordIV: iv, PasswordTag: tag,
+			},
+		}},
+		Attachments: []accountBackupAttachment{},
+	}
+}
+
+func TestBackupFileRoundTrip(t *testing.T) {
+	payload := testBackupPayload(t)
+	data, err := encodeBackupFile(testBackupKey, payload)
+	if err != nil {
+		t.Fatalf("encodeBackupFile: %v", err)
+	}
+
+	got, err := decodeBackupFile(testBackupKey, data)
+	if err != nil {
+		t.Fatalf("decodeBackupFile: %v", err)
+	}
+	if !reflect.DeepEqual(got, payload) {
+		t.Errorf("decodeBackupFile = %+v, erwartet %+v", got, payload)
+	}
+}
+
+func TestDecodeBackupFileRejectsTampering(t *testing.T) {
+	data, err := encodeBackupFile(testBackupKey, testBackupPayload(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// modify ändert die äußere Hülle der Backup-Datei
+	modify := func(change func(file *accountBackupFile)) []byte {
+		var file accountBackupFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			t.Fatal(err)
+		}
+		change(&file)
+		encoded, err := json.Marshal(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+		data []byte
+		want error
+	}{
+		{"falscher Schlüssel", bytes.Repeat([]byte{0x43}, minBackupKeyLength), data, ErrBackupSignature},
+		{"Inhalt verändert", testBackupKey, modify(func(file *accountBackupFile) {
+			file.Payload = json.RawMessage(strings.Replace(string(file.Payload), `"alice"`, `"mallory"`, 1))
+		}), ErrBackupSignature},
+		{"Signatur fehlt", testBackupKey, modify(func(file *accountBackupFile) { file.Signature = "" }), ErrBackupSignature},
+		{"Signatur kein Base64", testBackupKey, modify(func(file *accountBackupFile) { file.Signature = "!!" }), ErrBackupSignature},
+		{"anderes Format", testBackupKey, modify(func(file *accountBackupFile) { file.Format = "other-backup" }), ErrInvalidBackup},
+		{"anderes Signaturverfahren", testBackupKey, modify(func(file *accountBackupFile) { file.SignatureAlgorithm = "none" }), ErrInvalidBackup},
+		{"unbekannte Version", testBackupKey, modify(func(file *accountBackupFile) { file.Version = accountBackupVersion + 1 }), ErrInvalidBackup},
+		{"kein JSON", testBackupKey, []byte("backup"), ErrInvalidBackup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeBackupFile(tt.key, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("decodeBackupFile Fehler = %v, erwartet %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBackupFileValidatesContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(payload *accountBackupPayload)
+		want   error
+	}{
+		{"andere Iterationszahl", func(payload *accountBackupPayload) { payload.KDF.Iterations = 1000 }, ErrUnsupportedBackupKDF},
+		{"Salt fehlt", func(payload *accountBackupPayload) { payload.KDF.Salt = "" }, ErrUnsupportedBackupKDF},
+		{"Passwort-Hash fehlt", func(payload *accountBackupPayload) { payload.Account.MasterPasswordHash = "" }, ErrInvalidBackup},
+		{"ungültiger Chiffretext", func(payload *accountBackupPayload) { payload.Items[0].Item.EncryptedPassword = "kein Base64!" }, validation.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Auch korrekt signierte Backups werden nach den aktuellen Regeln geprüft
+			payload := testBackupPayload(t)
+			tt.change(payload)
+			data, err := encodeBackupFile(testBackupKey, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := decodeBackupFile(testBackupKey, data); !errors.Is(err, tt.want) {
+				t.Errorf("decodeBackupFile Fehler = %v, erwartet %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBackupFileReportsItemPositions(t *testing.T) {
+	payload := testBackupPayload(t)
+	second := payload.Items[0]
+	second.ID = 2
+	second.Item.PasswordIV = ""
+	payload.Items = append(payload.Items, second)
+	data, err := encodeBackupFile(testBackupKey, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = decodeBackupFile(testBackupKey, data)
+	var validationErr *validation.Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("decodeBackupFile Fehler = %v, erwartet *validation.Error", err)
+	}
+	for _, field := range validationErr.Fields {
+		if !strings.HasPrefix(field.Field, "items[1].item.") {
+			t.Errorf("Feld = %q, erwartet Position items[1]", field.Field)
+		}
+	}
+}
+
+func TestRestoreAccountBackupRequiresBackupMasterPassword(t *testing.T) {
+	data, err := encodeBackupFile(testBackupKey, testBackupPayload(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Die Prüfung erfolgt vor jedem Datenbankzugriff
+	service := &PasswordService{}
+	if _, err := service.RestoreAccountBackup(1, testBackupKey, data, "anderes", ClientInfo{}); !errors.Is(err, ErrBackupMasterPassword) {
+		t.Errorf("RestoreAccountBackup Fehler = %v, erwartet ErrBackupMasterPassword", err)
+	}
+	if _, err := service.RestoreAccountBackup(1, testBackupKey[:minBackupKeyLength-1], data, "geheim", ClientInfo{}); !errors.Is(err, ErrInvalidBackupKey) {
+		t.Errorf("RestoreAccountBackup Fehler = %v, erwartet ErrInvalidBackupKey", err)
+	}
+}
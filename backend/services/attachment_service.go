@@ -0,0 +1,229 @@
+// AttachmentService - Verwaltet verschlüsselte Dateianhänge von Tresor-Einträgen
+// Clients laden ausschließlich Chiffretext hoch, der Server prüft Größe, Kontingent und Integrität
+// Der Inhalt liegt im konfigurierten Speicher-Treiber, die Metadaten in der Datenbank
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"backend/storage"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fehler bei der Verarbeitung von Anhängen
+var (
+	ErrInvalidAttachment       = NewError(ErrValidation, "invalid_attachment", "Verschlüsselter Dateiname (Name, IV, Tag) und SHA-256-Prüfsumme des Anhangs sind erforderlich")
+	ErrAttachmentTooLarge      = NewError(ErrValidation, "attachment_too_large", "Anhang überschreitet die maximale Dateigröße")
+	ErrAttachmentQuotaExceeded = NewError(ErrConflict, "attachment_quota_exceeded", "Speicherkontingent für Anhänge überschritten")
+	ErrAttachmentIntegrity     = NewError(ErrValidation, "attachment_integrity", "Prüfsumme des Anhangs stimmt nicht mit der angegebenen SHA-256-Prüfsumme überein")
+)
+
+// AttachmentService verwaltet Upload, Download und Löschung von Anhängen
+type AttachmentService struct {
+	DB           *gorm.DB        // Datenbankverbindung für Anhang-Metadaten
+	Storage      storage.Storage // Speicher-Treiber für den Chiffretext
+	QuotaBytes   int64           // Kontingent pro Benutzer in Bytes
+	MaxFileBytes int64           // Maximale Größe eines einzelnen Anhangs in Bytes
+}
+
+// NewAttachmentService erstellt eine neue AttachmentService-Instanz.
+func NewAttachmentService(db *gorm.DB, store storage.Storage, quotaBytes, maxFileBytes int64) *AttachmentService {
+	return &AttachmentService{DB: db, Storage: store, QuotaBytes: quotaBytes, MaxFileBytes: maxFileBytes}
+}
+
+// Upload streamt den Chiffretext in den Speicher und legt danach die Metadaten an
+// Der SHA-256 wird beim Schreiben berechnet; bei Abweichung wird das Objekt wieder entfernt
+func (s *AttachmentService) Upload(ctx context.Context, userID, passwordID uint, req *schemas.AttachmentUploadRequest, body io.Reader) (*models.Attachment, error) {
+	expectedSum := strings.ToLower(req.SHA256)
+	if req.EncryptedFileName == "" || req.FileNameIV == "" || req.FileNameTag == "" || req.Size <= 0 || !isSHA256Hex(expectedSum) {
+		return nil, ErrInvalidAttachment
+	}
+	if req.Size > s.MaxFileBytes {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	if err := s.ensurePasswordOwner(userID, passwordID); err != nil {
+		return nil, err
+	}
+
+	// Kontingent vorab prüfen, damit abgelehnte Uploads gar nicht erst gespeichert werden
+	used, err := s.usedBytes(s.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if used+req.Size > s.QuotaBytes {
+		return nil, ErrAttachmentQuotaExceeded
+	}
+
+	key, err := newStorageKey(userID, passwordID)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	if err := s.Storage.Put(ctx, key, io.TeeReader(io.LimitReader(body, req.Size), hasher), req.Size); err != nil {
+		s.removeObjects([]string{key})
+		return nil, fmt.Errorf("Fehler beim Speichern des Anhangs: %w", err)
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != expectedSum {
+		s.removeObjects([]string{key})
+		return nil, ErrAttachmentIntegrity
+	}
+
+	attachment := &models.Attachment{
+		PasswordID:        passwordID,
+		UserID:            userID,
+		EncryptedFileName: req.EncryptedFileName,
+		FileNameIV:        req.FileNameIV,
+		FileNameTag:       req.FileNameTag,
+		Size:              req.Size,
+		SHA256:            expectedSum,
+		StorageKey:        key,
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Benutzerzeile sperren, damit parallele Uploads das Kontingent nicht gemeinsam überschreiten
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("Fehler beim Sperren des Benutzers %d: %w", userID, notFound(err, ErrUserNotFound))
+		}
+		used, err := s.usedBytes(tx, userID)
+		if err != nil {
+			return err
+		}
+		if used+req.Size > s.QuotaBytes {
+			return ErrAttachmentQuotaExceeded
+		}
+		if err := tx.Create(attachment).Error; err != nil {
+			return fmt.Errorf("Fehler beim Speichern der Anhang-Metadaten: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.removeObjects([]string{key})
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// GetAttachments ruft alle Anhänge eines Passwort-Eintrags ab.
+func (s *AttachmentService) GetAttachments(userID, passwordID uint) ([]models.Attachment, error) {
+	if err := s.ensurePasswordOwner(userID, passwordID); err != nil {
+		return nil, err
+	}
+
+	var attachments []models.Attachment
+	if err := s.DB.Where("password_id = ? AND user_id = ?", passwordID, userID).Order("id ASC").Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Anhänge von Passwort %d: %w", passwordID, err)
+	}
+	return attachments, nil
+}
+
+// OpenAttachment liefert die Metadaten und einen Reader auf den Chiffretext eines Anhangs
+// Der Aufrufer muss den Reader schließen
+func (s *AttachmentService) OpenAttachment(ctx context.Context, userID, passwordID, attachmentID uint) (*models.Attachment, io.ReadCloser, error) {
+	var attachment models.Attachment
+	if err := s.DB.Where("id = ? AND password_id = ? AND user_id = ?", attachmentID, passwordID, userID).First(&attachment).Error; err != nil {
+		return nil, nil, fmt.Errorf("Anhang %d nicht gefunden oder Fehler beim Abrufen: %w", attachmentID, notFound(err, ErrAttachmentNotFound))
+	}
+
+	reader, err := s.Storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			err = ErrAttachmentNotFound.Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("Inhalt von Anhang %d konnte nicht geöffnet werden: %w", attachmentID, err)
+	}
+	return &attachment, reader, nil
+}
+
+// DeleteAttachment löscht einen einzelnen Anhang samt Inhalt.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, userID, passwordID, attachmentID uint) error {
+	var attachment models.Attachment
+	if err := s.DB.Where("id = ? AND password_id = ? AND user_id = ?", attachmentID, passwordID, userID).First(&attachment).Error; err != nil {
+		return fmt.Errorf("Anhang %d nicht gefunden oder Fehler beim Löschen: %w", attachmentID, notFound(err, ErrAttachmentNotFound))
+	}
+	if err := s.DB.Delete(&attachment).Error; err != nil {
+		return fmt.Errorf("Fehler beim Löschen von Anhang %d: %w", attachmentID, err)
+	}
+
+	s.removeObjects([]string{attachment.StorageKey})
+	return nil
+}
+
+// GetUsage liefert den belegten Speicher eines Benutzers für Anhänge.
+func (s *AttachmentService) GetUsage(userID uint) (int64, error) {
+	return s.usedBytes(s.DB, userID)
+}
+
+// removeObjects entfernt Objekte aus dem Speicher, nachdem ihre Metadaten gelöscht wurden
+// Fehler werden nur protokolliert, da die Datenbank bereits konsistent ist
+func (s *AttachmentService) removeObjects(keys []string) {
+	for _, key := range keys {
+		if err := s.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Warnung: Anhang-Objekt %s konnte nicht gelöscht werden: %v", key, err)
+		}
+	}
+}
+
+// ensurePasswordOwner prüft, ob der Passwort-Eintrag existiert und dem Benutzer gehört.
+func (s *AttachmentService) ensurePasswordOwner(userID, passwordID uint) error {
+	if err := s.DB.Select("id").Where("id = ? AND user_id = ?", passwordID, userID).First(&models.Password{}).Error; err != nil {
+		return fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
+	}
+	return nil
+}
+
+// usedBytes summiert die Größe aller Anhänge eines Benutzers.
+func (s *AttachmentService) usedBytes(db *gorm.DB, userID uint) (int64, error) {
+	var used int64
+	if err := db.Model(&models.Attachment{}).Where("user_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		return 0, fmt.Errorf("Fehler beim Berechnen des Speicherverbrauchs für Benutzer %d: %w", userID, err)
+	}
+	return used, nil
+}
+
+// deleteAttachmentRows löscht die Metadaten aller Anhänge der angegebenen Passwort-Einträge
+// und gibt die Speicherschlüssel zurück, die nach dem Commit entfernt werden müssen
+func deleteAttachmentRows(tx *gorm.DB, passwordIDs interface{}) ([]string, error) {
+	var keys []string
+	if err := tx.Model(&models.Attachment{}).Where("password_id IN (?)", passwordIDs).Pluck("storage_key", &keys).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Anhänge: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("password_id IN (?)", passwordIDs).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Anhänge: %w", err)
+	}
+	return keys, nil
+}
+
+// newStorageKey erzeugt einen zufälligen, nicht erratbaren Speicherschlüssel
+func newStorageKey(userID, passwordID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("Fehler beim Generieren des Speicherschlüssels: %w", err)
+	}
+	return fmt.Sprintf("%d/%d/%s", userID, passwordID, hex.EncodeToString(random)), nil
+}
+
+// isSHA256Hex prüft, ob der Wert ein hex-kodierter SHA-256 ist
+func isSHA256Hex(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
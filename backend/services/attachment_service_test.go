@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"backend/schemas"
+)
+
+func TestIsSHA256Hex(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{strings.Repeat("ab", 32), true},
+		{strings.Repeat("0", 64), true},
+		{strings.Repeat("ab", 31), false},
+		{strings.Repeat("ab", 33), false},
+		{strings.Repeat("g", 64), false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isSHA256Hex(tt.value); got != tt.want {
+			t.Errorf("isSHA256Hex(%q) = %v, erwartet %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNewStorageKey(t *testing.T) {
+	pattern := regexp.MustCompile(`^7/42/[0-9a-f]{32}$`)
+	first, err := newStorageKey(7, 42)
+	if err != nil {
+		t.Fatalf("newStorageKey: %v", err)
+	}
+	second, err := newStorageKey(7, 42)
+	if err != nil {
+		t.Fatalf("newStorageKey: %v", err)
+	}
+	if !pattern.MatchString(first) || !pattern.MatchString(second) {
+		t.Errorf("Schlüssel %q, %q entsprechen nicht %s", first, second, pattern)
+	}
+	if first == second {
+		t.Errorf("zwei Schlüssel sind gleich: %q", first)
+	}
+}
+
+func TestUploadRejectsInvalidRequest(t *testing.T) {
+	// Die Prüfungen laufen vor jedem Datenbank- oder Speicherzugriff
+	service := &AttachmentService{QuotaBytes: 100, MaxFileBytes: 10}
+	valid := schemas.AttachmentUploadRequest{
+		EncryptedFileName: "name", FileNameIV: "iv", FileNameTag: "tag",
+		Size: 10, SHA256: strings.Repeat("AB", 32),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*schemas.AttachmentUploadRequest)
+		want   error
+	}{
+		{"Name fehlt", func(r *schemas.AttachmentUploadRequest) { r.EncryptedFileName = "" }, ErrInvalidAttachment},
+		{"IV fehlt", func(r *schemas.AttachmentUploadRequest) { r.FileNameIV = "" }, ErrInvalidAttachment},
+		{"Tag fehlt", func(r *schemas.AttachmentUploadRequest) { r.FileNameTag = "" }, ErrInvalidAttachment},
+		{"leer", func(r *schemas.AttachmentUploadRequest) { r.Size = 0 }, ErrInvalidAttachment},
+		{"ungültige Prüfsumme", func(r *schemas.AttachmentUploadRequest) { r.SHA256 = "abc" }, ErrInvalidAttachment},
+		{"zu groß", func(r *schemas.AttachmentUploadRequest) { r.Size = 11 }, ErrAttachmentTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			_, err := service.Upload(context.Background(), 1, 2, &req, strings.NewReader(""))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Upload = %v, erwartet %v", err, tt.want)
+			}
+		})
+	}
+}
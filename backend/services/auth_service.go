@@ -4,9 +4,9 @@
 package services
 
 import (
-	"errors"
 	"fmt"
 
+	"backend/i18n"
 	"backend/models"
 	"backend/schemas"
 	"backend/security"
@@ -18,14 +18,26 @@ import (
 // AuthService behandelt alle Authentifizierungsoperationen
 // Arbeitet eng mit UserService zusammen für Benutzer-CRUD-Operationen
 type AuthService struct {
-	DB          *gorm.DB     // Datenbankverbindung für direkte Operationen
-	UserService *UserService // Service für Benutzer-spezifische Operationen
+	DB          *gorm.DB         // Datenbankverbindung für direkte Operationen
+	UserService *UserService     // Service für Benutzer-spezifische Operationen
+	Policy      *PasswordPolicy  // Richtlinie für neue Master-Passwörter
+	Snapshots   *SnapshotService // Dienst für Tresor-Snapshots vor der Neuverschlüsselung
 }
 
+// Fehler bei Registrierung, Anmeldung und Änderung des Master-Passworts
+var (
+	ErrUsernameTaken                = NewError(ErrConflict, "username_taken", "Benutzername ist bereits vergeben")
+	ErrEmailTaken                   = NewError(ErrConflict, "email_taken", "E-Mail-Adresse ist bereits registriert")
+	ErrInvalidCredentials           = NewError(ErrUnauthorized, "invalid_credentials", "Ungültige Anmeldeinformationen")
+	ErrEmailNotVerified             = NewError(ErrUnauthorized, "email_not_verified", "E-Mail-Adresse muss vor der Anmeldung verifiziert werden")
+	ErrInvalidCurrentMasterPassword = NewError(ErrUnauthorized, "invalid_current_master_password", "Bisheriges Master-Passwort ist falsch")
+	ErrMasterPasswordChangeNotBegun = NewError(ErrValidation, "invalid_change_snapshot", "Snapshot stammt nicht vom Beginn einer Änderung des Master-Passworts")
+)
+
 // NewAuthService erstellt eine neue AuthService-Instanz
 // Dependency Injection Pattern für lose Kopplung der Services
-func NewAuthService(db *gorm.DB, userService *UserService) *AuthService {
-	return &AuthService{DB: db, UserService: userService}
+func NewAuthService(db *gorm.DB, userService *UserService, policy *PasswordPolicy, snapshotService *SnapshotService) *AuthService {
+	return &AuthService{DB: db, UserService: userService, Policy: policy, Snapshots: snapshotService}
 }
 
 // RegisterUser registriert einen neuen Benutzer mit umfassenden Validierungen
@@ -33,13 +45,26 @@ func NewAuthService(db *gorm.DB, userService *UserService) *AuthService {
 // Prüft auf doppelte Benutzernamen und E-Mail-Adressen
 func (s *AuthService) RegisterUser(req *schemas.RegisterRequest) (*models.User, error) {
 	// Prüfen, ob der Benutzername bereits existiert
-	if existingUser, _ := s.UserService.GetUserByUsername(req.Username); existingUser != nil {
-		return nil, errors.New("Benutzername ist bereits vergeben")
+	existingUser, err := s.UserService.GetUserByUsername(req.Username)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Prüfen des Benutzernamens: %w", err)
+	}
+	if existingUser != nil {
+		return nil, ErrUsernameTaken
 	}
 
 	// Prüfen, ob die E-Mail bereits existiert
-	if existingUser, _ := s.UserService.GetUserByEmail(req.Email); existingUser != nil {
-		return nil, errors.New("E-Mail-Adresse ist bereits registriert")
+	existingUser, err = s.UserService.GetUserByEmail(req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Prüfen der E-Mail-Adresse: %w", err)
+	}
+	if existingUser != nil {
+		return nil, ErrEmailTaken
+	}
+
+	// Master-Passwort gegen die Richtlinie prüfen (Länge, Entropie, persönliche Daten, Datenlecks)
+	if err := s.Policy.Check(req.MasterPassword, req.Username, req.Email); err != nil {
+		return nil, err
 	}
 
 	// Salt generieren für Frontend-Verschlüsselung (separat von bcrypt)
@@ -64,9 +89,10 @@ func (s *AuthService) RegisterUser(req *schemas.RegisterRequest) (*models.User,
 		Salt:                 salt,  // Dieser Salt ist für das Frontend
 		TwoFAEnabled:         false, // 2FA standardmäßig deaktiviert
 		EmailVerified:        false, // E-Mail noch nicht verifiziert
+		Locale:               i18n.Resolve(req.Locale),
 	}
 
-	// Benutzer in der Datenbank speichern
+	// Benutzer in der Datenbank speichern (gleichzeitige Registrierungen scheitern am Unique-Index)
 	if err := s.UserService.CreateUser(user); err != nil {
 		return nil, fmt.Errorf("Fehler beim Erstellen des Benutzers: %w", err)
 	}
@@ -74,6 +100,75 @@ func (s *AuthService) RegisterUser(req *schemas.RegisterRequest) (*models.User,
 	return user, nil
 }
 
+// BeginMasterPasswordChange beginnt die Änderung des Master-Passworts, bevor der Client den Tresor neu verschlüsselt
+// Prüft das bisherige Passwort und das neue Passwort gegen die Richtlinie und sichert den Tresor,
+// solange er noch mit dem bisherigen Schlüssel verschlüsselt ist. Der Snapshot erlaubt das Zurücksetzen
+// einer fehlgeschlagenen Neuverschlüsselung; seine ID wird beim Abschluss der Änderung benötigt
+func (s *AuthService) BeginMasterPasswordChange(userID uint, req *schemas.BeginMasterPasswordChangeRequest) (*models.VaultSnapshot, error) {
+	if _, err := s.verifyMasterPasswordChange(userID, req); err != nil {
+		return nil, err
+	}
+
+	var snapshot *models.VaultSnapshot
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		snapshot, err = s.Snapshots.capture(tx, userID, models.SnapshotReasonMasterPasswordChange)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ChangeMasterPassword schließt die Änderung des Master-Passworts ab
+// Der Server kann den Tresor nicht entschlüsseln: Der Client verschlüsselt die Einträge zwischen
+// BeginMasterPasswordChange und diesem Aufruf mit dem neuen Schlüssel neu (z.B. über PUT /passwords/batch)
+// Der Snapshot aus BeginMasterPasswordChange muss noch vorhanden sein
+func (s *AuthService) ChangeMasterPassword(userID uint, req *schemas.ChangeMasterPasswordRequest) error {
+	user, err := s.verifyMasterPasswordChange(userID, &req.BeginMasterPasswordChangeRequest)
+	if err != nil {
+		return err
+	}
+
+	var snapshot models.VaultSnapshot
+	if err := s.DB.Omit("Data").Where("id = ? AND user_id = ?", req.SnapshotID, userID).First(&snapshot).Error; err != nil {
+		return fmt.Errorf("Snapshot %d für Benutzer %d nicht gefunden: %w", req.SnapshotID, userID, notFound(err, ErrSnapshotNotFound))
+	}
+	if snapshot.Reason != models.SnapshotReasonMasterPasswordChange {
+		return ErrMasterPasswordChangeNotBegun
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewMasterPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Hashen des Master-Passworts: %w", err)
+	}
+	if err := s.DB.Model(user).Update("hashed_master_password", string(hashedPassword)).Error; err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Master-Passworts: %w", err)
+	}
+	return nil
+}
+
+// verifyMasterPasswordChange prüft das bisherige Master-Passwort und das neue Passwort gegen die Richtlinie
+func (s *AuthService) verifyMasterPasswordChange(userID uint, req *schemas.BeginMasterPasswordChangeRequest) (*models.User, error) {
+	user, err := s.UserService.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Benutzers: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedMasterPassword), []byte(req.CurrentMasterPassword)); err != nil {
+		return nil, ErrInvalidCurrentMasterPassword
+	}
+
+	if err := s.Policy.Check(req.NewMasterPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // LoginUser authentifiziert Benutzer mit umfassenden Sicherheitsprüfungen
 // Validiert E-Mail-Verifizierung, prüft Passwort mit bcrypt und generiert JWT-Token
 // Rückgabe enthält alle für Frontend benötigten Authentifizierungsdaten
@@ -84,18 +179,18 @@ func (s *AuthService) LoginUser(req *schemas.LoginRequest) (*schemas.LoginRespon
 		return nil, fmt.Errorf("Fehler beim Abrufen des Benutzers: %w", err)
 	}
 	if user == nil {
-		return nil, errors.New("Ungültige Anmeldeinformationen") // Benutzer nicht gefunden
+		return nil, ErrInvalidCredentials // Benutzer nicht gefunden
 	}
 
 	// Prüfen, ob die E-Mail verifiziert ist
 	if !user.EmailVerified {
-		return nil, errors.New("E-Mail-Adresse muss vor der Anmeldung verifiziert werden")
+		return nil, ErrEmailNotVerified
 	}
 
 	// Das bereitgestellte Passwort mit dem bcrypt-Hash vergleichen
 	// bcrypt macht automatisch Salt-Extraktion und Vergleich
 	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedMasterPassword), []byte(req.MasterPassword)); err != nil {
-		return nil, errors.New("Ungültige Anmeldeinformationen") // Passwort stimmt nicht überein
+		return nil, ErrInvalidCredentials // Passwort stimmt nicht überein
 	}
 
 	// JWT-Token generieren
@@ -111,22 +206,16 @@ func (s *AuthService) LoginUser(req *schemas.LoginRequest) (*schemas.LoginRespon
 		Username:     user.Username,
 		TwoFAEnabled: user.TwoFAEnabled,
 		Salt:         user.Salt,
+		Locale:       i18n.Resolve(user.Locale),
 	}, nil
 }
 
 // DeleteAccount löscht Benutzeraccount und alle verknüpften Daten
-// Transaktionssichere Löschung: erst Passwörter, dann Benutzer
+// Delegiert an den UserService, der Tresor-Daten, Anhänge und Benutzer transaktionssicher entfernt
 // GDPR-konform: vollständige Entfernung aller Benutzerdaten
 func (s *AuthService) DeleteAccount(userID uint) error {
-	// Zuerst alle Passwörter des Benutzers löschen
-	if err := s.DB.Where("user_id = ?", userID).Delete(&models.Password{}).Error; err != nil {
-		return fmt.Errorf("Fehler beim Löschen der Passwörter: %w", err)
+	if err := s.UserService.DeleteUserAccount(userID); err != nil {
+		return fmt.Errorf("Fehler beim Löschen des Accounts: %w", err)
 	}
-
-	// Dann den Benutzer selbst löschen
-	if err := s.DB.Delete(&models.User{}, userID).Error; err != nil {
-		return fmt.Errorf("Fehler beim Löschen des Benutzers: %w", err)
-	}
-
 	return nil
 }
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"backend/breach"
+	"errors"
+)
+
+// Fehler bei der Prüfung auf kompromittierte Passwörter
+var (
+	ErrBreachIndexUnavailable = NewError(ErrUnavailable, "breach_index_unavailable", "Kein Index kompromittierter Passwörter konfiguriert")
+	ErrInvalidHashPrefix      = NewError(ErrValidation, "invalid_hash_prefix", "Präfix muss aus fünf Hex-Zeichen bestehen")
+)
+
+// BreachService beantwortet Abfragen gegen den lokal importierten Index kompromittierter Passwörter.
+type BreachService struct {
+	Index *breach.Index // Geöffneter Index (nil, wenn nicht konfiguriert)
+}
+
+// NewBreachService erstellt eine neue BreachService-Instanz.
+func NewBreachService(index *breach.Index) *BreachService {
+	return &BreachService{Index: index}
+}
+
+// Range liefert alle bekannten Hashes mit dem angegebenen SHA-1-Präfix (k-Anonymity).
+func (s *BreachService) Range(prefix string) ([]breach.Entry, error) {
+	if !s.Available() {
+		return nil, ErrBreachIndexUnavailable
+	}
+	entries, err := s.Index.Range(prefix)
+	if errors.Is(err, breach.ErrInvalidPrefix) {
+		return nil, ErrInvalidHashPrefix.Wrap(err)
+	}
+	return entries, err
+}
+
+// Available meldet, ob ein Index geladen ist.
+func (s *BreachService) Available() bool {
+	return s != nil && s.Index != nil
+}
+
+// PasswordCount liefert, wie oft ein Passwort in bekannten Datenlecks vorkommt (0 = nicht gefunden).
+func (s *BreachService) PasswordCount(password string) (uint32, error) {
+	if !s.Available() {
+		return 0, ErrBreachIndexUnavailable
+	}
+	return s.Index.PasswordCount(password)
+}
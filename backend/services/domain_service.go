@@ -0,0 +1,281 @@
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Eingebettete Standardliste globaler Gruppen gleichwertiger Domains
+//
+//go:embed equivalent_domains.json
+var builtinDomainGroups []byte
+
+// Grenzen für benutzerdefinierte Domain-Gruppen
+const (
+	maxCustomDomainGroups = 100
+	maxDomainsPerGroup    = 50
+	maxDomainGroupKeyLen  = 64
+)
+
+// ErrInvalidDomainGroup wird zurückgegeben, wenn eine Domain-Gruppe ungültig ist.
+var ErrInvalidDomainGroup = NewError(ErrValidation, "invalid_domain_group", "Ungültige Domain-Gruppe")
+
+// DomainGroupDefinition ist ein Eintrag der Liste globaler Domain-Gruppen (JSON).
+type DomainGroupDefinition struct {
+	Key     string   `json:"key"`     // Stabiler Schlüssel der Gruppe
+	Domains []string `json:"domains"` // Gleichwertige Domains
+}
+
+// DomainSettings fasst die globalen und benutzerdefinierten Domain-Gruppen eines Benutzers zusammen.
+type DomainSettings struct {
+	Global   []models.GlobalDomainGroup // Alle globalen Gruppen
+	Excluded map[string]bool            // Vom Benutzer ausgeschlossene globale Gruppen (nach Schlüssel)
+	Custom   []models.UserDomainGroup   // Benutzerdefinierte Gruppen
+}
+
+// DomainService verwaltet Gruppen gleichwertiger Domains für den URL-Abgleich.
+type DomainService struct {
+	DB *gorm.DB // Datenbankverbindung für Domain-Gruppen
+}
+
+// NewDomainService erstellt eine neue DomainService-Instanz.
+func NewDomainService(db *gorm.DB) *DomainService {
+	return &DomainService{DB: db}
+}
+
+// SyncGlobalDomainGroups gleicht die globale Tabelle mit einer Liste im JSON-Format ab.
+// Ohne Daten wird die eingebettete Standardliste verwendet. Gruppen werden über ihren Schlüssel
+// aktualisiert, nicht mehr enthaltene Gruppen entfernt; Ausschlüsse der Benutzer bleiben erhalten.
+func (s *DomainService) SyncGlobalDomainGroups(data []byte) error {
+	if data == nil {
+		data = builtinDomainGroups
+	}
+	var definitions []DomainGroupDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDomainGroup, err)
+	}
+
+	groups := make([]models.GlobalDomainGroup, 0, len(definitions))
+	keys := make([]string, 0, len(definitions))
+	seen := make(map[string]bool)
+	for _, definition := range definitions {
+		key := strings.TrimSpace(definition.Key)
+		if key == "" || len(key) > maxDomainGroupKeyLen || seen[key] {
+			return fmt.Errorf("%w: ungültiger oder doppelter Schlüssel %q", ErrInvalidDomainGroup, key)
+		}
+		seen[key] = true
+		domains, err := normalizeDomainGroup(definition.Domains)
+		if err != nil {
+			return fmt.Errorf("Gruppe %q: %w", key, err)
+		}
+		groups = append(groups, models.GlobalDomainGroup{Key: key, Domains: domains})
+		keys = append(keys, key)
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if len(groups) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"domains", "updated_at"}),
+			}).Create(&groups).Error; err != nil {
+				return fmt.Errorf("Fehler beim Speichern der globalen Domain-Gruppen: %w", err)
+			}
+		}
+		stale := tx.Model(&models.GlobalDomainGroup{})
+		if len(keys) > 0 {
+			stale = stale.Where("key NOT IN ?", keys)
+		} else {
+			stale = stale.Where("1 = 1")
+		}
+		if err := stale.Delete(&models.GlobalDomainGroup{}).Error; err != nil {
+			return fmt.Errorf("Fehler beim Entfernen veralteter Domain-Gruppen: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetDomainSettings liefert die globalen und benutzerdefinierten Domain-Gruppen eines Benutzers.
+func (s *DomainService) GetDomainSettings(userID uint) (*DomainSettings, error) {
+	return loadDomainSettings(s.DB, userID)
+}
+
+// UpdateDomainSettings ersetzt die benutzerdefinierten Gruppen und/oder die ausgeschlossenen
+// globalen Gruppen eines Benutzers. Nicht übergebene Listen bleiben unverändert.
+func (s *DomainService) UpdateDomainSettings(userID uint, req *schemas.UpdateDomainSettingsRequest) (*DomainSettings, error) {
+	var custom []models.UserDomainGroup
+	if req.CustomGroups != nil {
+		if len(*req.CustomGroups) > maxCustomDomainGroups {
+			return nil, fmt.Errorf("%w: höchstens %d eigene Gruppen", ErrInvalidDomainGroup, maxCustomDomainGroups)
+		}
+		for i, group := range *req.CustomGroups {
+			domains, err := normalizeDomainGroup(group)
+			if err != nil {
+				return nil, fmt.Errorf("Gruppe %d: %w", i, err)
+			}
+			custom = append(custom, models.UserDomainGroup{UserID: userID, Domains: domains})
+		}
+	}
+
+	var settings *DomainSettings
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if req.ExcludedGlobalGroups != nil {
+			keys := uniqueStrings(*req.ExcludedGlobalGroups)
+			if len(keys) > 0 {
+				var known int64
+				if err := tx.Model(&models.GlobalDomainGroup{}).Where("key IN ?", keys).Count(&known).Error; err != nil {
+					return fmt.Errorf("Fehler beim Prüfen der globalen Domain-Gruppen: %w", err)
+				}
+				if known != int64(len(keys)) {
+					return fmt.Errorf("%w: unbekannte globale Gruppe", ErrInvalidDomainGroup)
+				}
+			}
+			if err := tx.Where("user_id = ?", userID).Delete(&models.ExcludedDomainGroup{}).Error; err != nil {
+				return fmt.Errorf("Fehler beim Entfernen der Ausschlüsse: %w", err)
+			}
+			for _, key := range keys {
+				if err := tx.Create(&models.ExcludedDomainGroup{UserID: userID, GroupKey: key}).Error; err != nil {
+					return fmt.Errorf("Fehler beim Speichern der Ausschlüsse: %w", err)
+				}
+			}
+		}
+
+		if req.CustomGroups != nil {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.UserDomainGroup{}).Error; err != nil {
+				return fmt.Errorf("Fehler beim Entfernen der eigenen Domain-Gruppen: %w", err)
+			}
+			if len(custom) > 0 {
+				if err := tx.Create(&custom).Error; err != nil {
+					return fmt.Errorf("Fehler beim Speichern der eigenen Domain-Gruppen: %w", err)
+				}
+			}
+		}
+
+		var err error
+		settings, err = loadDomainSettings(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// loadDomainSettings lädt globale Gruppen, Ausschlüsse und eigene Gruppen eines Benutzers.
+func loadDomainSettings(db *gorm.DB, userID uint) (*DomainSettings, error) {
+	settings := &DomainSettings{Excluded: make(map[string]bool)}
+	if err := db.Order("key ASC").Find(&settings.Global).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der globalen Domain-Gruppen: %w", err)
+	}
+	var excluded []models.ExcludedDomainGroup
+	if err := db.Where("user_id = ?", userID).Find(&excluded).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Ausschlüsse: %w", err)
+	}
+	for _, exclusion := range excluded {
+		settings.Excluded[exclusion.GroupKey] = true
+	}
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&settings.Custom).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der eigenen Domain-Gruppen: %w", err)
+	}
+	return settings, nil
+}
+
+// equivalentDomains liefert die zu einer registrierbaren Domain gleichwertigen Domains
+// aus den nicht ausgeschlossenen globalen und den eigenen Gruppen eines Benutzers (ohne die Domain selbst).
+func equivalentDomains(db *gorm.DB, userID uint, domain string) ([]string, error) {
+	if domain == "" {
+		return nil, nil
+	}
+	// Vorfilter über die JSON-Darstellung, die genaue Prüfung erfolgt unten
+	pattern := "%" + escapeLike(`"`+domain+`"`) + "%"
+
+	var global []models.GlobalDomainGroup
+	if err := db.Where("domains LIKE ?", pattern).
+		Where("key NOT IN (?)", db.Model(&models.ExcludedDomainGroup{}).Select("group_key").Where("user_id = ?", userID)).
+		Find(&global).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der globalen Domain-Gruppen: %w", err)
+	}
+	var custom []models.UserDomainGroup
+	if err := db.Where("user_id = ? AND domains LIKE ?", userID, pattern).Find(&custom).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der eigenen Domain-Gruppen: %w", err)
+	}
+
+	groups := make([][]string, 0, len(global)+len(custom))
+	for _, group := range global {
+		groups = append(groups, group.Domains)
+	}
+	for _, group := range custom {
+		groups = append(groups, group.Domains)
+	}
+
+	seen := map[string]bool{domain: true}
+	var equivalent []string
+	for _, group := range groups {
+		if !containsString(group, domain) {
+			continue
+		}
+		for _, other := range group {
+			if !seen[other] {
+				seen[other] = true
+				equivalent = append(equivalent, other)
+			}
+		}
+	}
+	sort.Strings(equivalent)
+	return equivalent, nil
+}
+
+// normalizeDomainGroup bringt die Domains einer Gruppe auf ihre registrierbare Form
+// (Punycode, ohne Subdomains) und entfernt Duplikate. Eine Gruppe braucht mindestens zwei Domains.
+func normalizeDomainGroup(domains []string) ([]string, error) {
+	if len(domains) > maxDomainsPerGroup {
+		return nil, fmt.Errorf("%w: höchstens %d Domains pro Gruppe", ErrInvalidDomainGroup, maxDomainsPerGroup)
+	}
+	normalized := make([]string, 0, len(domains))
+	seen := make(map[string]bool)
+	for _, domain := range domains {
+		parsed, err := NormalizeURL(domain)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q ist keine gültige Domain", ErrInvalidDomainGroup, domain)
+		}
+		if !seen[parsed.Domain] {
+			seen[parsed.Domain] = true
+			normalized = append(normalized, parsed.Domain)
+		}
+	}
+	if len(normalized) < 2 {
+		return nil, fmt.Errorf("%w: mindestens zwei verschiedene Domains erforderlich", ErrInvalidDomainGroup)
+	}
+	return normalized, nil
+}
+
+// uniqueStrings entfernt leere Werte und Duplikate unter Beibehaltung der Reihenfolge.
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// containsString prüft, ob ein Wert in einer Liste enthalten ist.
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -4,10 +4,14 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	htmltemplate "html/template"
 	"os"
 	"strconv"
+	"strings"
+	texttemplate "text/template"
 	"time"
 
+	"backend/i18n"
 	"backend/models"
 
 	"gopkg.in/gomail.v2"
@@ -19,6 +23,82 @@ type EmailService struct {
 	DB *gorm.DB
 }
 
+// Fehler bei der E-Mail-Verifizierung
+var (
+	ErrInvalidVerificationToken = NewError(ErrValidation, "invalid_verification_token", "Ungültiger oder abgelaufener Verifizierungstoken")
+	ErrEmailNotRegistered       = NewError(ErrNotFound, "email_not_registered", "Benutzer mit dieser E-Mail-Adresse nicht gefunden")
+	ErrEmailAlreadyVerified     = NewError(ErrConflict, "email_already_verified", "E-Mail-Adresse ist bereits verifiziert")
+)
+
+// verificationEmail enthält die übersetzten Texte der Verifizierungs-E-Mail.
+type verificationEmail struct {
+	Lang, Title, Tagline, Greeting, Thanks, Instruction, Button, CopyLink string
+	Link, Notice, Expiry, Ignore, Regards, Team, Automated                string
+}
+
+// HTML-Vorlage der Verifizierungs-E-Mail (html/template maskiert u.a. den Benutzernamen)
+var verificationHTML = htmltemplate.Must(htmltemplate.New("verification.html").Parse(`
+<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #1976d2; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #1976d2; color: white !important; text-decoration: none; border-radius: 5px; margin: 20px 0; border: none; cursor: pointer; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🔐 TrustMe</h1>
+            <p>{{.Tagline}}</p>
+        </div>
+        <div class="content">
+            <h2>{{.Greeting}}</h2>
+            <p>{{.Thanks}}</p>
+            <p>{{.Instruction}}</p>
+            <div style="text-align: center; margin: 20px 0;">
+                <a href="{{.Link}}" style="display: inline-block; padding: 12px 24px; background-color: #1976d2; color: white; text-decoration: none; border-radius: 5px; font-weight: bold;" target="_blank">{{.Button}}</a>
+            </div>
+            <p>{{.CopyLink}}</p>
+            <p style="word-break: break-all; background-color: #e8e8e8; padding: 10px; border-radius: 3px;">
+                {{.Link}}
+            </p>
+            <p><strong>{{.Notice}}</strong> {{.Expiry}}</p>
+            <p>{{.Ignore}}</p>
+        </div>
+        <div class="footer">
+            <p>{{.Regards}}<br>{{.Team}}</p>
+            <p>{{.Automated}}</p>
+        </div>
+    </div>
+</body>
+</html>`))
+
+// Plaintext-Alternative der Verifizierungs-E-Mail
+var verificationText = texttemplate.Must(texttemplate.New("verification.txt").Parse(`
+{{.Greeting}}
+
+{{.Thanks}}
+
+{{.Instruction}}
+
+{{.Link}}
+
+{{.Expiry}}
+
+{{.Ignore}}
+
+{{.Regards}}
+{{.Team}}
+`))
+
 // NewEmailService erstellt eine neue EmailService-Instanz
 func NewEmailService(db *gorm.DB) *EmailService {
 	return &EmailService{DB: db}
@@ -66,84 +146,49 @@ func (s *EmailService) SendVerificationEmail(user *models.User, token string) er
 	// Verifizierungslink erstellen
 	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", baseURL, token)
 
+	// Texte in der gespeicherten Sprache des Benutzers (Fallback: Standardsprache)
+	locale := i18n.Resolve(user.Locale)
+	content := verificationEmail{
+		Lang:        locale,
+		Title:       i18n.T(locale, "email.verification.title"),
+		Tagline:     i18n.T(locale, "email.verification.tagline"),
+		Greeting:    i18n.T(locale, "email.verification.greeting", user.Username),
+		Thanks:      i18n.T(locale, "email.verification.thanks"),
+		Instruction: i18n.T(locale, "email.verification.instruction"),
+		Button:      i18n.T(locale, "email.verification.button"),
+		CopyLink:    i18n.T(locale, "email.verification.copy_link"),
+		Link:        verificationLink,
+		Notice:      i18n.T(locale, "email.verification.notice"),
+		Expiry:      i18n.T(locale, "email.verification.expiry"),
+		Ignore:      i18n.T(locale, "email.verification.ignore"),
+		Regards:     i18n.T(locale, "email.verification.regards"),
+		Team:        i18n.T(locale, "email.verification.team"),
+		Automated:   i18n.T(locale, "email.verification.automated"),
+	}
+
+	var htmlBody, textBody strings.Builder
+	if err := verificationHTML.Execute(&htmlBody, content); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen der E-Mail: %w", err)
+	}
+	if err := verificationText.Execute(&textBody, content); err != nil {
+		return fmt.Errorf("Fehler beim Erstellen der E-Mail: %w", err)
+	}
+
 	// E-Mail-Message mit gomail erstellen
 	m := gomail.NewMessage()
 	m.SetHeader("From", fromEmail)
 	m.SetHeader("To", user.Email)
-	m.SetHeader("Subject", "TrustMe - E-Mail-Adresse bestätigen")
-
-	// HTML-Template für die E-Mail
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>E-Mail-Verifizierung</title>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #1976d2; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 24px; background-color: #1976d2; color: white !important; text-decoration: none; border-radius: 5px; margin: 20px 0; border: none; cursor: pointer; }
-        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>🔐 TrustMe</h1>
-            <p>Sicherer Passwort-Manager</p>
-        </div>
-        <div class="content">
-            <h2>Hallo %s!</h2>
-            <p>Vielen Dank für Ihre Registrierung bei TrustMe!</p>
-            <p>Bitte bestätigen Sie Ihre E-Mail-Adresse, indem Sie auf den folgenden Button klicken:</p>
-            <div style="text-align: center; margin: 20px 0;">
-                <a href="%s" style="display: inline-block; padding: 12px 24px; background-color: #1976d2; color: white; text-decoration: none; border-radius: 5px; font-weight: bold;" target="_blank">E-Mail-Adresse bestätigen</a>
-            </div>
-            <p>Oder kopieren Sie diesen Link in Ihren Browser:</p>
-            <p style="word-break: break-all; background-color: #e8e8e8; padding: 10px; border-radius: 3px;">
-                %s
-            </p>
-            <p><strong>Wichtiger Hinweis:</strong> Dieser Link ist 24 Stunden gültig.</p>
-            <p>Falls Sie sich nicht bei TrustMe registriert haben, können Sie diese E-Mail ignorieren.</p>
-        </div>
-        <div class="footer">
-            <p>Mit freundlichen Grüßen,<br>Ihr TrustMe Team</p>
-            <p>Diese E-Mail wurde automatisch generiert. Bitte antworten Sie nicht auf diese E-Mail.</p>
-        </div>
-    </div>
-</body>
-</html>`, user.Username, verificationLink, verificationLink)
-
-	// Plaintext-Alternative
-	textBody := fmt.Sprintf(`
-Hallo %s,
-
-vielen Dank für Ihre Registrierung bei TrustMe!
-
-Bitte bestätigen Sie Ihre E-Mail-Adresse, indem Sie auf den folgenden Link klicken:
-
-%s
-
-Dieser Link ist 24 Stunden gültig.
-
-Falls Sie sich nicht bei TrustMe registriert haben, können Sie diese E-Mail ignorieren.
-
-Mit freundlichen Grüßen,
-Ihr TrustMe Team
-`, user.Username, verificationLink)
-
-	m.SetBody("text/plain", textBody)
-	m.AddAlternative("text/html", htmlBody)
+	m.SetHeader("Subject", i18n.T(locale, "email.verification.subject"))
+	m.SetHeader("Content-Language", locale)
+	m.SetBody("text/plain", textBody.String())
+	m.AddAlternative("text/html", htmlBody.String())
 
 	// SMTP-Dialer konfigurieren
 	var d *gomail.Dialer
 	if smtpUser != "" && smtpPass != "" {
 		// Mit Authentifizierung (Produktion)
 		d = gomail.NewDialer(smtpHost, smtpPort, smtpUser, smtpPass)
-		
+
 		// TLS/SSL Konfiguration
 		if smtpPort == 465 {
 			// SSL
@@ -191,14 +236,14 @@ func (s *EmailService) VerifyEmail(token string) error {
 	result := s.DB.Where("email_verification_token = ? AND email_token_expiry > ?", token, time.Now()).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return fmt.Errorf("Ungültiger oder abgelaufener Verifizierungstoken")
+			return ErrInvalidVerificationToken
 		}
 		return fmt.Errorf("Fehler beim Suchen des Benutzers: %w", result.Error)
 	}
 
 	// E-Mail als verifiziert markieren und Token löschen
 	result = s.DB.Model(&user).Updates(map[string]interface{}{
-		"email_verified":            true,
+		"email_verified":           true,
 		"email_verification_token": nil,
 		"email_token_expiry":       nil,
 	})
@@ -218,14 +263,14 @@ func (s *EmailService) ResendVerificationEmail(email string) error {
 	result := s.DB.Where("email = ?", email).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return fmt.Errorf("Benutzer mit dieser E-Mail-Adresse nicht gefunden")
+			return ErrEmailNotRegistered
 		}
 		return fmt.Errorf("Fehler beim Suchen des Benutzers: %w", result.Error)
 	}
 
 	// Prüfen, ob E-Mail bereits verifiziert ist
 	if user.EmailVerified {
-		return fmt.Errorf("E-Mail-Adresse ist bereits verifiziert")
+		return ErrEmailAlreadyVerified
 	}
 
 	// Neuen Token generieren
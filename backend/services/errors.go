@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// SQLSTATE von Postgres für die Verletzung eines Unique-Constraints
+const pgUniqueViolation = "23505"
+
+// Fehlerarten der Dienste. Jeder Domänenfehler gehört zu genau einer Art, die Handler bilden
+// die Art auf den HTTP-Status ab (errors.Is(err, ErrNotFound) usw.).
+var (
+	ErrNotFound     = errors.New("nicht gefunden")
+	ErrConflict     = errors.New("konflikt")
+	ErrUnauthorized = errors.New("nicht autorisiert")
+	ErrValidation   = errors.New("ungültige Eingabe")
+	ErrRateLimited  = errors.New("zu viele Anfragen")
+	ErrUnavailable  = errors.New("vorübergehend nicht verfügbar")
+)
+
+// Error ist ein Domänenfehler mit Fehlerart und stabilem, maschinenlesbarem Code.
+// Zwei Fehler mit gleichem Code gelten für errors.Is als gleich, auch wenn einer davon
+// mit Wrap um eine Ursache ergänzt wurde.
+type Error struct {
+	Kind    error  // Fehlerart (ErrNotFound, ErrConflict, ...)
+	Code    string // Stabiler Fehlercode, z.B. "username_taken"
+	Message string // Lesbare Beschreibung
+	cause   error  // Ursprünglicher Fehler (optional)
+}
+
+// NewError erstellt einen Domänenfehler.
+func NewError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap liefert Fehlerart und Ursache, damit errors.Is beide findet.
+func (e *Error) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.cause}
+}
+
+// Is vergleicht Domänenfehler anhand ihres Codes.
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.Code == e.Code
+}
+
+// Wrap liefert eine Kopie des Fehlers mit cause als Ursache.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.cause = cause
+	return &wrapped
+}
+
+// Nicht gefundene Ressourcen
+var (
+	ErrUserNotFound       = NewError(ErrNotFound, "user_not_found", "Benutzer nicht gefunden")
+	ErrPasswordNotFound   = NewError(ErrNotFound, "password_not_found", "Passwort-Eintrag nicht gefunden")
+	ErrPasswordNotInTrash = NewError(ErrNotFound, "password_not_in_trash", "Eintrag nicht im Papierkorb gefunden")
+	ErrRevisionNotFound   = NewError(ErrNotFound, "revision_not_found", "Revision nicht gefunden")
+	ErrAttachmentNotFound = NewError(ErrNotFound, "attachment_not_found", "Anhang nicht gefunden")
+	ErrSnapshotNotFound   = NewError(ErrNotFound, "snapshot_not_found", "Snapshot nicht gefunden")
+	ErrImportNotFound     = NewError(ErrNotFound, "import_not_found", "Import nicht gefunden")
+)
+
+// notFound ersetzt gorm.ErrRecordNotFound durch den typisierten Fehler target;
+// errors.Is(err, gorm.ErrRecordNotFound) bleibt dabei gültig. Andere Fehler bleiben unverändert.
+func notFound(err error, target *Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target.Wrap(err)
+	}
+	return err
+}
+
+// uniqueViolation liefert den Namen des verletzten Unique-Constraints bzw. -Index, wenn err eine
+// Unique-Verletzung von Postgres ist, sonst "".
+func uniqueViolation(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// IdempotencyService - Speichert Antworten auf Anfragen mit Idempotency-Key
+// Wiederholt ein Client eine Anfrage (z.B. nach einem Timeout) mit demselben Schlüssel,
+// erhält er die ursprüngliche Antwort, ohne dass die Aktion erneut ausgeführt wird
+package services
+
+import (
+	"backend/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Nach dieser Zeit gilt eine nicht abgeschlossene Anfrage als abgebrochen (z.B. Serverneustart)
+const idempotencyLockTimeout = 10 * time.Minute
+
+// Fehler bei der Verwendung von Idempotency-Keys
+var (
+	ErrIdempotencyKeyReused  = NewError(ErrValidation, "idempotency_key_reused", "Idempotency-Key wurde bereits für eine andere Anfrage verwendet")
+	ErrIdempotencyInProgress = NewError(ErrConflict, "idempotency_in_progress", "Eine Anfrage mit diesem Idempotency-Key wird noch bearbeitet")
+)
+
+// IdempotencyService verwaltet gespeicherte Antworten pro Benutzer und Schlüssel
+type IdempotencyService struct {
+	DB  *gorm.DB      // Datenbankverbindung für gespeicherte Antworten
+	TTL time.Duration // Aufbewahrungsdauer gespeicherter Antworten
+}
+
+// NewIdempotencyService erstellt eine neue IdempotencyService-Instanz.
+func NewIdempotencyService(db *gorm.DB, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{DB: db, TTL: ttl}
+}
+
+// Begin reserviert den Schlüssel für eine neue Anfrage. Liegt für denselben Schlüssel bereits
+// eine abgeschlossene Antwort auf dieselbe Anfrage vor, wird sie zurückgegeben und die Anfrage
+// darf nicht erneut ausgeführt werden. Ein Rückgabewert nil bedeutet, dass der Aufrufer die Anfrage
+// bearbeiten und anschließend Complete oder Release aufrufen muss.
+func (s *IdempotencyService) Begin(userID uint, key, requestHash string) (*models.IdempotencyKey, error) {
+	// Ein abgelaufener oder verwaister Eintrag wird entfernt und die Reservierung einmal wiederholt
+	for attempt := 0; attempt < 2; attempt++ {
+		record := models.IdempotencyKey{UserID: userID, Key: key, RequestHash: requestHash}
+		result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return nil, fmt.Errorf("Fehler beim Reservieren des Idempotency-Keys: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var existing models.IdempotencyKey
+		if err := s.DB.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("Fehler beim Abrufen des Idempotency-Keys: %w", err)
+		}
+
+		stale, err := s.resolve(&existing, requestHash, time.Now())
+		if stale {
+			if err := s.DB.Delete(&models.IdempotencyKey{}, existing.ID).Error; err != nil {
+				return nil, fmt.Errorf("Fehler beim Entfernen des abgelaufenen Idempotency-Keys: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	return nil, ErrIdempotencyInProgress
+}
+
+// resolve entscheidet, wie mit einer Anfrage zu einem bereits vorhandenen Eintrag verfahren wird.
+// stale meldet einen abgelaufenen oder verwaisten Eintrag, der ersetzt werden darf. Sonst ist err
+// ErrIdempotencyKeyReused (andere Anfrage), ErrIdempotencyInProgress (noch nicht abgeschlossen)
+// oder nil, wenn die gespeicherte Antwort wiederholt werden kann.
+func (s *IdempotencyService) resolve(existing *models.IdempotencyKey, requestHash string, now time.Time) (bool, error) {
+	expired := s.TTL > 0 && existing.CreatedAt.Before(now.Add(-s.TTL))
+	abandoned := existing.StatusCode == 0 && existing.CreatedAt.Before(now.Add(-idempotencyLockTimeout))
+	if expired || abandoned {
+		return true, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return false, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return false, ErrIdempotencyInProgress
+	}
+	return false, nil
+}
+
+// Complete speichert die Antwort auf eine reservierte Anfrage.
+func (s *IdempotencyService) Complete(userID uint, key string, statusCode int, contentType string, body []byte) error {
+	err := s.DB.Model(&models.IdempotencyKey{}).Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{"status_code": statusCode, "content_type": contentType, "body": body}).Error
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Antwort zum Idempotency-Key: %w", err)
+	}
+	return nil
+}
+
+// Release gibt einen reservierten Schlüssel wieder frei, z.B. nach einem Serverfehler,
+// damit der Client die Anfrage mit demselben Schlüssel wiederholen kann.
+func (s *IdempotencyService) Release(userID uint, key string) error {
+	if err := s.DB.Where("user_id = ? AND key = ? AND status_code = 0", userID, key).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("Fehler beim Freigeben des Idempotency-Keys: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired löscht gespeicherte Antworten, deren Aufbewahrungsdauer abgelaufen ist.
+func (s *IdempotencyService) PurgeExpired() (int64, error) {
+	if s.TTL <= 0 {
+		return 0, nil
+	}
+	result := s.DB.Where("created_at < ?", time.Now().Add(-s.TTL)).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("Fehler beim Bereinigen abgelaufener Idempotency-Keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
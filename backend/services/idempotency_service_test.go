@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+func TestIdempotencyResolve(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := &IdempotencyService{TTL: 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		existing  models.IdempotencyKey
+		hash      string
+		wantStale bool
+		wantErr   error
+	}{
+		{"abgeschlossen, gleiche Anfrage", models.IdempotencyKey{RequestHash: "a", StatusCode: 201, CreatedAt: now.Add(-time.Hour)}, "a", false, nil},
+		{"abgeschlossen, andere Anfrage", models.IdempotencyKey{RequestHash: "a", StatusCode: 201, CreatedAt: now.Add(-time.Hour)}, "b", false, ErrIdempotencyKeyReused},
+		{"in Bearbeitung", models.IdempotencyKey{RequestHash: "a", CreatedAt: now.Add(-time.Minute)}, "a", false, ErrIdempotencyInProgress},
+		{"in Bearbeitung, andere Anfrage", models.IdempotencyKey{RequestHash: "a", CreatedAt: now.Add(-time.Minute)}, "b", false, ErrIdempotencyKeyReused},
+		{"verwaist", models.IdempotencyKey{RequestHash: "a", CreatedAt: now.Add(-idempotencyLockTimeout - time.Second)}, "a", true, nil},
+		{"abgelaufen", models.IdempotencyKey{RequestHash: "a", StatusCode: 201, CreatedAt: now.Add(-25 * time.Hour)}, "a", true, nil},
+		{"abgelaufen, andere Anfrage", models.IdempotencyKey{RequestHash: "a", StatusCode: 201, CreatedAt: now.Add(-25 * time.Hour)}, "b", true, nil},
+		{"Fehlerantwort wird wiederholt", models.IdempotencyKey{RequestHash: "a", StatusCode: 400, CreatedAt: now.Add(-time.Hour)}, "a", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stale, err := service.resolve(&tt.existing, tt.hash, now)
+			if stale != tt.wantStale || !errors.Is(err, tt.wantErr) {
+				t.Errorf("resolve = (%v, %v), erwartet (%v, %v)", stale, err, tt.wantStale, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdempotencyResolveWithoutTTL(t *testing.T) {
+	// Ohne Aufbewahrungsdauer bleiben abgeschlossene Antworten unbegrenzt gültig
+	now := time.Now()
+	existing := models.IdempotencyKey{RequestHash: "a", StatusCode: 201, CreatedAt: now.AddDate(-1, 0, 0)}
+	if stale, err := (&IdempotencyService{}).resolve(&existing, "a", now); stale || err != nil {
+		t.Errorf("resolve = (%v, %v), erwartet (false, nil)", stale, err)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"backend/validation"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Modi für Batch-Änderungen an bestehenden Einträgen
+const (
+	BatchModeAtomic     = "atomic"      // Alle Einträge gemeinsam oder gar nicht (Standard)
+	BatchModeBestEffort = "best_effort" // Jeder Eintrag unabhängig von den anderen
+)
+
+// Maximale Anzahl Einträge pro Batch-Änderung
+const maxBatchItems = 1000
+
+// Fehler bei Batch-Änderungen
+var (
+	ErrInvalidBatch = NewError(ErrValidation, "invalid_batch", "Ungültige Batch-Anfrage")
+	ErrBatchAborted = NewError(ErrConflict, "batch_aborted", "Nicht übernommen, da ein anderer Eintrag des Batches fehlgeschlagen ist")
+)
+
+// BatchItemOutcome enthält das Ergebnis für einen Eintrag eines Batches.
+type BatchItemOutcome struct {
+	ID        uint             // ID des Eintrags (beim Import die neue Server-ID)
+	ClientRef string           // Referenz des Clients (nur beim Import)
+	Password  *models.Password // Neuer Stand (nur bei erfolgreicher Aktualisierung)
+	Err       error            // Fehler des Eintrags, nil bei Erfolg
+}
+
+// errBatchRollback bricht die Transaktion eines atomaren Batches ab, nachdem ein Eintrag fehlgeschlagen ist
+var errBatchRollback = errors.New("batch abgebrochen")
+
+// BatchUpdatePasswords aktualisiert mehrere Einträge des Benutzers. Jeder Eintrag kann eine
+// erwartete Revision angeben; fremde oder unbekannte IDs schlagen mit ErrRecordNotFound fehl.
+// Der zurückgegebene Wert applied gibt an, ob Änderungen übernommen wurden. Da Clients den Tresor
+// über diesen Weg vollständig neu verschlüsseln, wird vorher ein Snapshot angelegt.
+func (s *PasswordService) BatchUpdatePasswords(userID uint, req *schemas.BatchUpdatePasswordRequest, client ClientInfo) ([]BatchItemOutcome, bool, error) {
+	ids := make([]uint, len(req.Items))
+	for i := range req.Items {
+		ids[i] = req.Items[i].ID
+	}
+
+	return s.runBatch(userID, req.Mode, ids, models.SnapshotReasonBatchUpdate, func(tx *gorm.DB, syncRevision int64, i int) (*models.Password, error) {
+		if err := validation.Struct(&req.Items[i].UpdatePasswordRequest); err != nil {
+			return nil, err
+		}
+		return s.updatePasswordTx(tx, syncRevision, ids[i], userID, &req.Items[i].UpdatePasswordRequest, models.RevisionActionUpdate, client)
+	})
+}
+
+// BatchDeletePasswords verschiebt mehrere Einträge des Benutzers in den Papierkorb.
+// Jeder Eintrag kann eine erwartete Revision angeben.
+func (s *PasswordService) BatchDeletePasswords(userID uint, req *schemas.BatchDeletePasswordRequest) ([]BatchItemOutcome, bool, error) {
+	ids := make([]uint, len(req.Items))
+	for i := range req.Items {
+		ids[i] = req.Items[i].ID
+	}
+
+	return s.runBatch(userID, req.Mode, ids, "", func(tx *gorm.DB, syncRevision int64, i int) (*models.Password, error) {
+		return nil, trashPasswordTx(tx, syncRevision, ids[i], userID, req.Items[i].ExpectedRevision)
+	})
+}
+
+// runBatch wendet apply für jeden Eintrag innerhalb einer gemeinsamen Transaktion an.
+// Alle Einträge erhalten dieselbe Tresor-Revision. Jeder Eintrag läuft in einem eigenen Savepoint,
+// sodass im Modus best_effort ein Fehler nur diesen Eintrag zurückrollt. Im Modus atomic wird die
+// gesamte Transaktion beim ersten Fehler verworfen und alle übrigen Einträge mit ErrBatchAborted markiert.
+// Ist snapshotReason gesetzt, wird in derselben Transaktion vor dem ersten Eintrag ein Snapshot angelegt.
+func (s *PasswordService) runBatch(userID uint, mode string, ids []uint, snapshotReason string, apply func(tx *gorm.DB, syncRevision int64, i int) (*models.Password, error)) ([]BatchItemOutcome, bool, error) {
+	mode, err := batchMode(mode)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ids) == 0 || len(ids) > maxBatchItems {
+		return nil, false, fmt.Errorf("%w: zwischen 1 und %d Einträge erforderlich", ErrInvalidBatch, maxBatchItems)
+	}
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			return nil, false, fmt.Errorf("%w: Eintrag %d ist mehrfach enthalten", ErrInvalidBatch, id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	outcomes := make([]BatchItemOutcome, len(ids))
+	for i, id := range ids {
+		outcomes[i].ID = id
+	}
+
+	failed := false
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if snapshotReason != "" {
+			if _, err := s.Snapshots.capture(tx, userID, snapshotReason); err != nil {
+				return err
+			}
+		}
+
+		syncRevision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		for i := range ids {
+			var password *models.Password
+			itemErr := tx.Transaction(func(itemTx *gorm.DB) error {
+				var err error
+				password, err = apply(itemTx, syncRevision, i)
+				return err
+			})
+			outcomes[i].Password, outcomes[i].Err = password, itemErr
+
+			if itemErr != nil && mode == BatchModeAtomic {
+				failed = true
+				return errBatchRollback
+			}
+		}
+		return nil
+	})
+
+	if failed {
+		abortBatch(outcomes)
+		return outcomes, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return outcomes, true, nil
+}
+
+// batchMode prüft den angeforderten Modus und setzt den Standard atomic ein.
+func batchMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return BatchModeAtomic, nil
+	case BatchModeAtomic, BatchModeBestEffort:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: unbekannter Modus %q", ErrInvalidBatch, mode)
+	}
+}
+
+// abortBatch markiert nach dem Verwerfen eines atomaren Batches alle Einträge ohne eigenen Fehler
+// mit ErrBatchAborted; fehlgeschlagene Einträge behalten ihren Fehler.
+func abortBatch(outcomes []BatchItemOutcome) {
+	for i := range outcomes {
+		outcomes[i].Password = nil
+		if outcomes[i].Err == nil {
+			outcomes[i].Err = ErrBatchAborted
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+func TestBatchMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{"", BatchModeAtomic, false},
+		{BatchModeAtomic, BatchModeAtomic, false},
+		{BatchModeBestEffort, BatchModeBestEffort, false},
+		{"Atomic", "", true},
+		{"all_or_nothing", "", true},
+	}
+	for _, tt := range tests {
+		got, err := batchMode(tt.mode)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("batchMode(%q) = (%q, %v), erwartet %q", tt.mode, got, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidBatch) {
+			t.Errorf("batchMode(%q) Fehler = %v, erwartet ErrInvalidBatch", tt.mode, err)
+		}
+	}
+}
+
+func TestAbortBatch(t *testing.T) {
+	// Nach dem Verwerfen behält nur der fehlgeschlagene Eintrag seinen Fehler, alle anderen gelten als abgebrochen
+	outcomes := []BatchItemOutcome{
+		{ID: 1, Password: &models.Password{ID: 1}},
+		{ID: 2, Err: ErrPasswordNotFound},
+		{ID: 3},
+	}
+	abortBatch(outcomes)
+
+	want := []error{ErrBatchAborted, ErrPasswordNotFound, ErrBatchAborted}
+	for i, outcome := range outcomes {
+		if outcome.Password != nil {
+			t.Errorf("Eintrag %d: Password = %+v, erwartet nil", outcome.ID, outcome.Password)
+		}
+		if !errors.Is(outcome.Err, want[i]) {
+			t.Errorf("Eintrag %d: Fehler = %v, erwartet %v", outcome.ID, outcome.Err, want[i])
+		}
+	}
+}
+
+func TestRunBatchRejectsInvalidRequests(t *testing.T) {
+	// Ungültige Batches werden vor jedem Datenbankzugriff abgelehnt
+	service := &PasswordService{}
+	apply := func(tx *gorm.DB, syncRevision int64, i int) (*models.Password, error) {
+		t.Fatal("apply darf nicht aufgerufen werden")
+		return nil, nil
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		ids  []uint
+	}{
+		{"leer", BatchModeAtomic, nil},
+		{"zu viele Einträge", BatchModeAtomic, make([]uint, maxBatchItems+1)},
+		{"doppelte ID", BatchModeBestEffort, []uint{1, 2, 1}},
+		{"unbekannter Modus", "parallel", []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.runBatch(1, tt.mode, tt.ids, "", apply); !errors.Is(err, ErrInvalidBatch) {
+				t.Errorf("runBatch Fehler = %v, erwartet ErrInvalidBatch", err)
+			}
+		})
+	}
+}
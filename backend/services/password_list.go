@@ -0,0 +1,206 @@
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Grenzen für die Seitengröße beim Auflisten von Einträgen
+const (
+	defaultPasswordPageSize = 100
+	maxPasswordPageSize     = 1000
+)
+
+// ErrInvalidListQuery wird zurückgegeben, wenn Filter, Sortierung oder Cursor ungültig sind.
+var ErrInvalidListQuery = NewError(ErrValidation, "invalid_list_query", "Ungültige Listenabfrage")
+
+// Sortierbare Spalten der Eintragsliste
+var passwordSortColumns = map[string]string{
+	"created": "created_at",
+	"updated": "updated_at",
+	"url":     "website_url",
+}
+
+// Regulärer Ausdruck, der den Host (ohne Schema, Zugangsdaten, Port und Pfad) aus der Klartext-URL extrahiert.
+// Wird als Parameter übergeben, da die Fragezeichen sonst als Platzhalter interpretiert würden
+const websiteHostPattern = `^(?:[A-Za-z][A-Za-z0-9+.-]*://)?(?:[^@/]*@)?([^/:?#]+)`
+
+// PasswordPage ist eine Seite der Eintragsliste.
+type PasswordPage struct {
+	Items      []models.Password // Einträge der Seite
+	Total      int64             // Anzahl aller Einträge, die den Filtern entsprechen
+	NextCursor string            // Cursor der nächsten Seite (leer auf der letzten Seite)
+}
+
+// passwordCursor ist der dekodierte Inhalt eines Seiten-Cursors.
+// Sortierung und Richtung werden mitgeführt, damit ein Cursor nicht mit anderer Sortierung verwendet wird
+type passwordCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// ListPasswords liefert eine Seite der aktiven Einträge eines Benutzers.
+// Die Paginierung erfolgt über einen Keyset-Cursor (Sortierwert + ID), sodass Einfügungen
+// zwischen zwei Abfragen weder zu doppelten noch zu fehlenden Einträgen führen.
+func (s *PasswordService) ListPasswords(userID uint, query *schemas.PasswordListQuery) (*PasswordPage, error) {
+	sort := query.Sort
+	if sort == "" {
+		sort = "created"
+	}
+	column, ok := passwordSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: unbekannte Sortierung %q", ErrInvalidListQuery, sort)
+	}
+	order := strings.ToLower(query.Order)
+	if order == "" {
+		order = "asc"
+	}
+	if order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("%w: unbekannte Sortierrichtung %q", ErrInvalidListQuery, query.Order)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultPasswordPageSize
+	}
+	limit = min(limit, maxPasswordPageSize)
+
+	if query.Type != "" && !models.IsValidItemType(query.Type) {
+		return nil, ErrInvalidItemType
+	}
+
+	filtered := s.DB.Model(&models.Password{}).Where("user_id = ?", userID)
+	if query.Type != "" {
+		filtered = filtered.Where("type = ?", query.Type)
+	}
+	if host := strings.ToLower(strings.TrimSpace(query.Host)); host != "" {
+		// Einträge gleichwertiger Domains (z.B. youtube.com zu google.com) gehören ebenfalls dazu
+		hosts := []string{host}
+		if normalized, err := NormalizeURL(host); err == nil {
+			equivalent, err := equivalentDomains(s.DB, userID, normalized.Domain)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, equivalent...)
+		}
+		conditions := s.DB.Where("1 = 0")
+		for _, h := range hosts {
+			conditions = conditions.Or("lower(substring(website_url from ?)) = ? OR lower(substring(website_url from ?)) LIKE ?",
+				websiteHostPattern, h, websiteHostPattern, "%."+escapeLike(h))
+		}
+		filtered = filtered.Where(conditions)
+	}
+	if query.DomainIndex != "" {
+		indexes, err := ParseDomainIndexes(query.DomainIndex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidListQuery, err)
+		}
+		filtered = filtered.Where("(domain_index IN ? OR EXISTS (SELECT 1 FROM password_uris WHERE password_uris.password_id = passwords.id AND password_uris.domain_index IN ?))",
+			indexes, indexes)
+	}
+	for _, bound := range []struct {
+		value, condition string
+	}{
+		{query.CreatedAfter, "created_at >= ?"},
+		{query.CreatedBefore, "created_at < ?"},
+		{query.UpdatedAfter, "updated_at >= ?"},
+		{query.UpdatedBefore, "updated_at < ?"},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: ungültiger Zeitpunkt %q", ErrInvalidListQuery, bound.value)
+		}
+		filtered = filtered.Where(bound.condition, t)
+	}
+
+	page := &PasswordPage{}
+	if err := filtered.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Zählen der Passwörter für Benutzer %d: %w", userID, err)
+	}
+
+	items := filtered.Session(&gorm.Session{}).Scopes(preloadItemDetails)
+	if query.Cursor != "" {
+		value, id, err := parsePasswordCursor(query.Cursor, sort, order)
+		if err != nil {
+			return nil, err
+		}
+		comparison := ">"
+		if order == "desc" {
+			comparison = "<"
+		}
+		items = items.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, id)
+	}
+
+	// Eine Zeile mehr laden, um zu erkennen, ob es eine weitere Seite gibt
+	if err := items.Order(fmt.Sprintf("%s %s, id %s", column, order, order)).Limit(limit + 1).Find(&page.Items).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Passwörter für Benutzer %d: %w", userID, err)
+	}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := &page.Items[limit-1]
+		cursor := passwordCursor{Sort: sort, Order: order, ID: last.ID}
+		switch sort {
+		case "created":
+			cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+		case "updated":
+			cursor.Value = last.UpdatedAt.Format(time.RFC3339Nano)
+		case "url":
+			cursor.Value = last.WebsiteURL
+		}
+		page.NextCursor = encodePasswordCursor(cursor)
+	}
+
+	return page, nil
+}
+
+// escapeLike maskiert Platzhalterzeichen für die Verwendung in LIKE-Mustern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// encodePasswordCursor kodiert einen Cursor als URL-sicheren, für Clients undurchsichtigen String.
+func encodePasswordCursor(cursor passwordCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodePasswordCursor dekodiert einen mit encodePasswordCursor erzeugten Cursor.
+func decodePasswordCursor(value string) (*passwordCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor passwordCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// parsePasswordCursor dekodiert einen Cursor und prüft, dass er zu Sortierung und Richtung der Abfrage
+// passt. Liefert den Sortierwert (Zeitpunkt bzw. URL) und die ID des letzten Eintrags der vorigen Seite.
+func parsePasswordCursor(value, sort, order string) (interface{}, uint, error) {
+	cursor, err := decodePasswordCursor(value)
+	if err != nil || cursor.Sort != sort || cursor.Order != order || cursor.ID == 0 {
+		return nil, 0, fmt.Errorf("%w: ungültiger Cursor", ErrInvalidListQuery)
+	}
+	if sort == "url" {
+		return cursor.Value, cursor.ID, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: ungültiger Cursor", ErrInvalidListQuery)
+	}
+	return t, cursor.ID, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPasswordCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	tests := []struct {
+		name   string
+		cursor passwordCursor
+		want   interface{}
+	}{
+		{"erstellt aufsteigend", passwordCursor{Sort: "created", Order: "asc", Value: created.Format(time.RFC3339Nano), ID: 1}, created},
+		{"geändert absteigend", passwordCursor{Sort: "updated", Order: "desc", Value: created.Format(time.RFC3339Nano), ID: 42}, created},
+		{"URL", passwordCursor{Sort: "url", Order: "asc", Value: "https://example.com/?q=\"a\"&b", ID: 7}, "https://example.com/?q=\"a\"&b"},
+		{"leere URL", passwordCursor{Sort: "url", Order: "desc", Value: "", ID: 3}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodePasswordCursor(tt.cursor)
+			decoded, err := decodePasswordCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodePasswordCursor: %v", err)
+			}
+			if *decoded != tt.cursor {
+				t.Errorf("decodePasswordCursor = %+v, erwartet %+v", *decoded, tt.cursor)
+			}
+
+			value, id, err := parsePasswordCursor(encoded, tt.cursor.Sort, tt.cursor.Order)
+			if err != nil {
+				t.Fatalf("parsePasswordCursor: %v", err)
+			}
+			if id != tt.cursor.ID {
+				t.Errorf("ID = %d, erwartet %d", id, tt.cursor.ID)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, ok := value.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("Wert = %v, erwartet %v", value, want)
+				}
+			} else if value != tt.want {
+				t.Errorf("Wert = %v, erwartet %v", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePasswordCursorRejectsTampering(t *testing.T) {
+	valid := encodePasswordCursor(passwordCursor{Sort: "created", Order: "asc", Value: "2024-03-01T12:30:45Z", ID: 1})
+	raw := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+
+	tests := []struct {
+		name, cursor, sort, order string
+	}{
+		{"kein Base64", "!!", "created", "asc"},
+		{"Standard-Base64 mit Padding", base64.StdEncoding.EncodeToString([]byte(`{"s":"created","o":"asc","v":"2024-03-01T12:30:45Z","id":1}`)), "created", "asc"},
+		{"abgeschnitten", valid[:len(valid)-4], "created", "asc"},
+		{"kein JSON", raw("created|asc|1"), "created", "asc"},
+		{"falscher Typ der ID", raw(`{"s":"created","o":"asc","v":"2024-03-01T12:30:45Z","id":"1"}`), "created", "asc"},
+		{"negative ID", raw(`{"s":"created","o":"asc","v":"2024-03-01T12:30:45Z","id":-1}`), "created", "asc"},
+		{"ID fehlt", raw(`{"s":"created","o":"asc","v":"2024-03-01T12:30:45Z"}`), "created", "asc"},
+		{"andere Sortierung", valid, "updated", "asc"},
+		{"andere Richtung", valid, "created", "desc"},
+		{"Sortierung fehlt", raw(`{"o":"asc","v":"2024-03-01T12:30:45Z","id":1}`), "created", "asc"},
+		{"kein Zeitpunkt", raw(`{"s":"created","o":"asc","v":"gestern","id":1}`), "created", "asc"},
+		{"URL statt Zeitpunkt", raw(`{"s":"updated","o":"desc","v":"https://example.com","id":1}`), "updated", "desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parsePasswordCursor(tt.cursor, tt.sort, tt.order); !errors.Is(err, ErrInvalidListQuery) {
+				t.Errorf("parsePasswordCursor Fehler = %v, erwartet ErrInvalidListQuery", err)
+			}
+		})
+	}
+}
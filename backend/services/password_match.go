@@ -0,0 +1,374 @@
+package services
+
+import (
+	"backend/models"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Fehler bei der Suche passender Einträge
+var (
+	ErrInvalidMatchURL    = NewError(ErrValidation, "invalid_match_url", "Ungültige URL")
+	ErrInvalidDomainIndex = NewError(ErrValidation, "invalid_domain_index", "Ungültiger Blind-Index")
+)
+
+// Höchstzahl an Blind-Indizes pro Abfrage (aufgerufene und gleichwertige Domains)
+const maxDomainIndexes = 50
+
+// Spezifität der Abgleichsregeln; genauere Regeln werden bei der Auswahl bevorzugt.
+// Treffer über eine gleichwertige Domain (siehe DomainService) sind am wenigsten spezifisch
+const equivalentDomainSpecificity = 1
+
+var uriMatchSpecificity = map[string]int{
+	models.URIMatchDomain:     2,
+	models.URIMatchHost:       3,
+	models.URIMatchRegex:      4,
+	models.URIMatchStartsWith: 5,
+	models.URIMatchExact:      6,
+}
+
+// NormalizedURL ist eine für den Abgleich normalisierte URL.
+// Hostnamen liegen in ASCII-Form (Punycode) vor, ein führendes "www." und Standardports entfallen
+type NormalizedURL struct {
+	Raw    string // Ursprüngliche Eingabe (für reguläre Ausdrücke)
+	URL    string // Normalisierte URL ohne Zugangsdaten und Fragment
+	Host   string // Hostname inklusive abweichendem Port
+	Domain string // Registrierbare Domain laut Public Suffix List (bei IP-Adressen der Host selbst)
+}
+
+// PasswordMatch ist ein zur aufgerufenen URL passender Eintrag.
+type PasswordMatch struct {
+	Password    models.Password // Passender Eintrag
+	URI         string          // URI, über die der Eintrag gefunden wurde
+	Match       string          // Angewendete Abgleichsregel
+	Specificity int             // Spezifität der Regel (höher = genauer)
+}
+
+// NormalizeURL bringt eine URL in die für den Abgleich verwendete Form.
+// Fehlt das Schema, wird https angenommen; internationalisierte Domains werden in Punycode umgewandelt.
+func NormalizeURL(raw string) (*NormalizedURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: leere URL", ErrInvalidMatchURL)
+	}
+	input := raw
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMatchURL, err)
+	}
+
+	hostname := strings.TrimSuffix(parsed.Hostname(), ".")
+	if hostname == "" {
+		return nil, fmt.Errorf("%w: kein Host", ErrInvalidMatchURL)
+	}
+	ip := net.ParseIP(hostname)
+	if ip == nil {
+		if hostname, err = idna.Lookup.ToASCII(hostname); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMatchURL, err)
+		}
+		// "www." nur entfernen, wenn danach keine öffentliche Endung übrig bleibt (www.co.uk bleibt erhalten)
+		if trimmed := strings.TrimPrefix(hostname, "www."); trimmed != hostname {
+			if suffix, _ := publicsuffix.PublicSuffix(trimmed); suffix != trimmed {
+				hostname = trimmed
+			}
+		}
+	} else {
+		hostname = strings.ToLower(hostname)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	port := parsed.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	host := hostname
+	switch {
+	case port != "":
+		host = net.JoinHostPort(hostname, port)
+	case ip != nil && ip.To4() == nil:
+		host = "[" + hostname + "]"
+	}
+
+	domain := hostname
+	if ip == nil {
+		if registrable, err := publicsuffix.EffectiveTLDPlusOne(hostname); err == nil {
+			domain = registrable
+		}
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	normalized := scheme + "://" + host + path
+	if parsed.RawQuery != "" {
+		normalized += "?" + parsed.RawQuery
+	}
+
+	return &NormalizedURL{Raw: raw, URL: normalized, Host: host, Domain: domain}, nil
+}
+
+// MatchURI wendet die Abgleichsregel einer gespeicherten URI auf die aufgerufene URL an und
+// liefert die Spezifität des Treffers (0 = kein Treffer). Die Regel domain berücksichtigt zusätzlich
+// die gleichwertigen Domains der aufgerufenen URL. URIs mit der Regel never sowie nicht
+// auswertbare URIs passen nie.
+func MatchURI(uri, match string, target *NormalizedURL, equivalent []string) int {
+	return newURIMatcher(target, equivalent).match(uri, match)
+}
+
+// uriMatcher gleicht gespeicherte URIs mit einer aufgerufenen URL ab. Reguläre Ausdrücke und normalisierte
+// URIs werden für die Dauer einer Abfrage zwischengespeichert, da sie in vielen Einträgen wiederkehren.
+type uriMatcher struct {
+	target     *NormalizedURL
+	equivalent []string
+	patterns   map[string]*regexp.Regexp // Kompilierte Ausdrücke (nil = ungültig)
+	normalized map[string]*NormalizedURL // Normalisierte URIs (nil = nicht auswertbar)
+}
+
+// newURIMatcher erstellt einen uriMatcher für die aufgerufene URL und ihre gleichwertigen Domains.
+func newURIMatcher(target *NormalizedURL, equivalent []string) *uriMatcher {
+	return &uriMatcher{
+		target:     target,
+		equivalent: equivalent,
+		patterns:   make(map[string]*regexp.Regexp),
+		normalized: make(map[string]*NormalizedURL),
+	}
+}
+
+// match liefert die Spezifität des Treffers einer gespeicherten URI (siehe MatchURI).
+func (m *uriMatcher) match(uri, match string) int {
+	if match == models.URIMatchNever {
+		return 0
+	}
+	matched := false
+	if match == models.URIMatchRegex {
+		pattern := m.pattern(uri)
+		matched = pattern != nil && pattern.MatchString(m.target.Raw)
+	} else if stored := m.normalize(uri); stored != nil {
+		switch match {
+		case models.URIMatchDomain:
+			if stored.Domain != m.target.Domain && containsString(m.equivalent, stored.Domain) {
+				return equivalentDomainSpecificity
+			}
+			matched = stored.Domain == m.target.Domain
+		case models.URIMatchHost:
+			matched = stored.Host == m.target.Host
+		case models.URIMatchStartsWith:
+			matched = strings.HasPrefix(m.target.URL, stored.URL)
+		case models.URIMatchExact:
+			matched = stored.URL == m.target.URL
+		}
+	}
+	if !matched {
+		return 0
+	}
+	return uriMatchSpecificity[match]
+}
+
+// pattern liefert den kompilierten regulären Ausdruck einer URI (nil = ungültig).
+func (m *uriMatcher) pattern(uri string) *regexp.Regexp {
+	pattern, ok := m.patterns[uri]
+	if !ok {
+		pattern, _ = regexp.Compile(uri)
+		m.patterns[uri] = pattern
+	}
+	return pattern
+}
+
+// normalize liefert die normalisierte Form einer gespeicherten URI (nil = nicht auswertbar).
+func (m *uriMatcher) normalize(uri string) *NormalizedURL {
+	stored, ok := m.normalized[uri]
+	if !ok {
+		stored, _ = NormalizeURL(uri)
+		m.normalized[uri] = stored
+	}
+	return stored
+}
+
+// domainPatterns liefert LIKE-Muster für den Vorfilter in SQL. Jede URI, die über eine andere Regel als regex
+// zu einer der Domains passen kann, enthält deren Namen in ASCII- oder Unicode-Form, da ihr Host auf die
+// registrierbare Domain endet. Der Vorfilter darf mehr Einträge liefern, die genaue Prüfung übernimmt uriMatcher.
+func domainPatterns(domains []string) []string {
+	patterns := make([]string, 0, 2*len(domains))
+	for _, domain := range domains {
+		forms := []string{domain}
+		if unicode, err := idna.ToUnicode(domain); err == nil && unicode != domain {
+			forms = append(forms, unicode)
+		}
+		for _, form := range forms {
+			patterns = append(patterns, "%"+escapeLike(strings.ToLower(form))+"%")
+		}
+	}
+	return patterns
+}
+
+// likeAny verknüpft LIKE-Vergleiche einer Spalte mit allen Mustern per OR.
+func likeAny(column string, patterns []string) (string, []interface{}) {
+	conditions := make([]string, len(patterns))
+	args := make([]interface{}, len(patterns))
+	for i, pattern := range patterns {
+		conditions[i] = "lower(" + column + ") LIKE ?"
+		args[i] = pattern
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// MatchPasswords liefert die aktiven Einträge eines Benutzers, die zur aufgerufenen URL passen.
+// Pro Eintrag zählt die genaueste passende URI; Einträge ohne URIs werden über WebsiteURL
+// mit der Regel domain abgeglichen, jeweils inklusive gleichwertiger Domains.
+// Die Datenbank liefert nur Einträge, deren URIs eine der Domains enthalten oder reguläre Ausdrücke sind.
+// Sortiert wird nach Spezifität, dann nach letzter Verwendung.
+func (s *PasswordService) MatchPasswords(userID uint, rawURL string) (*NormalizedURL, []PasswordMatch, error) {
+	target, err := NormalizeURL(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	equivalent, err := equivalentDomains(s.DB, userID, target.Domain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patterns := domainPatterns(append([]string{target.Domain}, equivalent...))
+	websiteCondition, args := likeAny("website_url", patterns)
+	uriCondition, uriArgs := likeAny("password_uris.uri", patterns)
+	args = append(append(args, models.URIMatchRegex, models.URIMatchNever), uriArgs...)
+
+	var passwords []models.Password
+	if err := s.DB.Scopes(preloadItemDetails).
+		Where("user_id = ?", userID).
+		Where("(NOT EXISTS (SELECT 1 FROM password_uris WHERE password_uris.password_id = passwords.id) AND "+websiteCondition+")"+
+			" OR EXISTS (SELECT 1 FROM password_uris WHERE password_uris.password_id = passwords.id"+
+			" AND (password_uris.match = ? OR (password_uris.match <> ? AND "+uriCondition+")))", args...).
+		Find(&passwords).Error; err != nil {
+		return nil, nil, fmt.Errorf("Fehler beim Abrufen der Passwörter für Benutzer %d: %w", userID, err)
+	}
+
+	matcher := newURIMatcher(target, equivalent)
+	matches := []PasswordMatch{}
+	for _, password := range passwords {
+		candidates := password.URIs
+		if len(candidates) == 0 && password.WebsiteURL != "" {
+			candidates = []models.PasswordURI{{URI: password.WebsiteURL, Match: models.URIMatchDomain}}
+		}
+
+		best := PasswordMatch{}
+		for _, candidate := range candidates {
+			specificity := matcher.match(candidate.URI, candidate.Match)
+			if specificity <= best.Specificity {
+				continue
+			}
+			best = PasswordMatch{URI: candidate.URI, Match: candidate.Match, Specificity: specificity}
+		}
+		if best.Specificity > 0 {
+			best.Password = password
+			matches = append(matches, best)
+		}
+	}
+
+	sortPasswordMatches(matches)
+	return target, matches, nil
+}
+
+// MatchPasswordsByIndex liefert die aktiven Einträge, deren URL oder URIs einen der übergebenen
+// Blind-Indizes tragen. Der Server kennt die Domains nicht: Der Client berechnet den Index der
+// aufgerufenen Domain (erster Wert) und ggf. der gleichwertigen Domains (weitere Werte).
+// Regeln, die genauer als domain sind, kann nur der Client nach dem Entschlüsseln prüfen.
+func (s *PasswordService) MatchPasswordsByIndex(userID uint, indexes []string) ([]PasswordMatch, error) {
+	var passwords []models.Password
+	if err := s.DB.Scopes(preloadItemDetails).
+		Where("user_id = ? AND (domain_index IN ? OR EXISTS (SELECT 1 FROM password_uris WHERE password_uris.password_id = passwords.id AND password_uris.domain_index IN ?))", userID, indexes, indexes).
+		Find(&passwords).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Passwörter für Benutzer %d: %w", userID, err)
+	}
+
+	matches := []PasswordMatch{}
+	for _, password := range passwords {
+		candidates := password.URIs
+		if len(candidates) == 0 && password.DomainIndex != "" {
+			candidates = []models.PasswordURI{{DomainIndex: password.DomainIndex, Match: models.URIMatchDomain}}
+		}
+
+		best := PasswordMatch{}
+		for _, candidate := range candidates {
+			if candidate.Match == models.URIMatchNever || candidate.DomainIndex == "" || !containsString(indexes, candidate.DomainIndex) {
+				continue
+			}
+			specificity := equivalentDomainSpecificity
+			if candidate.DomainIndex == indexes[0] {
+				specificity = uriMatchSpecificity[models.URIMatchDomain]
+			}
+			if specificity > best.Specificity {
+				best = PasswordMatch{URI: candidate.URI, Match: candidate.Match, Specificity: specificity}
+			}
+		}
+		if best.Specificity > 0 {
+			best.Password = password
+			matches = append(matches, best)
+		}
+	}
+
+	sortPasswordMatches(matches)
+	return matches, nil
+}
+
+// ParseDomainIndexes zerlegt eine kommagetrennte Liste von Blind-Indizes und prüft ihr Format.
+func ParseDomainIndexes(value string) ([]string, error) {
+	indexes := uniqueStrings(strings.Split(strings.ToLower(value), ","))
+	if len(indexes) == 0 || len(indexes) > maxDomainIndexes {
+		return nil, fmt.Errorf("%w: 1 bis %d Werte erwartet", ErrInvalidDomainIndex, maxDomainIndexes)
+	}
+	for _, index := range indexes {
+		if !isHexHMAC(index) {
+			return nil, fmt.Errorf("%w: %q ist kein HMAC-SHA256 in Hex-Darstellung", ErrInvalidDomainIndex, index)
+		}
+	}
+	return indexes, nil
+}
+
+// sortPasswordMatches sortiert Treffer nach Spezifität, dann nach letzter Verwendung (neueste zuerst).
+func sortPasswordMatches(matches []PasswordMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Specificity != b.Specificity {
+			return a.Specificity > b.Specificity
+		}
+		if lastA, lastB := lastUsed(&a.Password), lastUsed(&b.Password); !lastA.Equal(lastB) {
+			return lastA.After(lastB)
+		}
+		return a.Password.ID < b.Password.ID
+	})
+}
+
+// MarkPasswordUsed hält fest, dass ein Eintrag verwendet wurde (z.B. beim automatischen Ausfüllen).
+// Die Verwendung ist kein Inhalt des Eintrags und erhöht daher weder Revision noch Tresor-Revision
+func (s *PasswordService) MarkPasswordUsed(passwordID, userID uint) (*models.Password, error) {
+	password, err := s.GetPasswordByID(passwordID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.DB.Model(password).UpdateColumn("last_used_at", now).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Speichern der Verwendung: %w", err)
+	}
+	password.LastUsedAt = &now
+	return password, nil
+}
+
+// lastUsed liefert den Zeitpunkt der letzten Verwendung eines Eintrags (nie verwendet = Nullzeit).
+func lastUsed(password *models.Password) time.Time {
+	if password.LastUsedAt == nil {
+		return time.Time{}
+	}
+	return *password.LastUsedAt
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"backend/i18n"
+	"backend/schemas"
+	"backend/security"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Regeln der Master-Passwort-Richtlinie (stabile Bezeichner für Clients)
+const (
+	PolicyRuleMinLength    = "min_length"
+	PolicyRuleEntropy      = "entropy"
+	PolicyRulePersonalInfo = "personal_info"
+	PolicyRuleBreached     = "breached"
+)
+
+// Mindestlänge von Benutzername bzw. E-Mail-Teilen, ab der sie im Passwort verboten sind
+const minPersonalInfoLength = 3
+
+// ErrWeakMasterPassword wird (über PasswordPolicyError) zurückgegeben, wenn ein Master-Passwort die Richtlinie verletzt.
+var ErrWeakMasterPassword = NewError(ErrValidation, "weak_master_password", "Master-Passwort erfüllt die Passwortrichtlinie nicht")
+
+// PasswordPolicyError enthält alle verletzten Regeln der Master-Passwort-Richtlinie.
+// Die Meldungen in Violations sind in der Standardsprache, Localized übersetzt sie.
+type PasswordPolicyError struct {
+	Violations []schemas.PasswordPolicyViolation
+	messages   []i18n.Message // Unübersetzte Meldungen, parallel zu Violations
+}
+
+// add ergänzt eine verletzte Regel.
+func (e *PasswordPolicyError) add(rule string, message i18n.Message) {
+	e.Violations = append(e.Violations, schemas.PasswordPolicyViolation{Rule: rule, Message: message.In(i18n.Default)})
+	e.messages = append(e.messages, message)
+}
+
+// Localized liefert die verletzten Regeln mit Meldungen in der Sprache locale.
+func (e *PasswordPolicyError) Localized(locale string) []schemas.PasswordPolicyViolation {
+	violations := make([]schemas.PasswordPolicyViolation, len(e.Violations))
+	copy(violations, e.Violations)
+	for i := range violations {
+		if i < len(e.messages) {
+			violations[i].Message = e.messages[i].In(locale)
+		}
+	}
+	return violations
+}
+
+// Error fasst die Verletzungen zu einer Meldung zusammen.
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%v: %s", ErrWeakMasterPassword, strings.Join(messages, "; "))
+}
+
+// Unwrap ermöglicht errors.Is(err, ErrWeakMasterPassword).
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakMasterPassword
+}
+
+// PasswordPolicy ist die konfigurierbare Richtlinie für Master-Passwörter.
+type PasswordPolicy struct {
+	MinLength          int            // Mindestlänge in Zeichen
+	MinEntropyBits     float64        // Mindestentropie laut security.EstimateStrength
+	ForbidPersonalInfo bool           // Benutzername und E-Mail dürfen nicht enthalten sein
+	CheckBreaches      bool           // Aus Datenlecks bekannte Passwörter ablehnen (nur mit Index)
+	Breach             *BreachService // Index kompromittierter Passwörter (optional)
+}
+
+// NewPasswordPolicy erstellt eine neue PasswordPolicy-Instanz.
+func NewPasswordPolicy(minLength int, minEntropyBits float64, forbidPersonalInfo, checkBreaches bool, breachService *BreachService) *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:          minLength,
+		MinEntropyBits:     minEntropyBits,
+		ForbidPersonalInfo: forbidPersonalInfo,
+		CheckBreaches:      checkBreaches,
+		Breach:             breachService,
+	}
+}
+
+// Check prüft ein Master-Passwort gegen alle Regeln und liefert bei Verstößen einen
+// PasswordPolicyError mit sämtlichen Verletzungen. Ohne Richtlinie wird jedes Passwort akzeptiert.
+func (p *PasswordPolicy) Check(password, username, email string) error {
+	if p == nil {
+		return nil
+	}
+	policyErr := &PasswordPolicyError{}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		policyErr.add(PolicyRuleMinLength, i18n.Msg("policy.min_length", p.MinLength))
+	}
+
+	if strength := security.EstimateStrength(password, username, email); strength.Bits < p.MinEntropyBits {
+		policyErr.add(PolicyRuleEntropy, i18n.Msg("policy.entropy", strength.Bits, p.MinEntropyBits))
+	}
+
+	if p.ForbidPersonalInfo && containsPersonalInfo(password, username, email) {
+		policyErr.add(PolicyRulePersonalInfo, i18n.Msg("policy.personal_info"))
+	}
+
+	if p.CheckBreaches && p.Breach.Available() {
+		count, err := p.Breach.PasswordCount(password)
+		if err != nil {
+			return fmt.Errorf("Fehler bei der Prüfung des Master-Passworts: %w", err)
+		}
+		if count > 0 {
+			policyErr.add(PolicyRuleBreached, i18n.Msg("policy.breached"))
+		}
+	}
+
+	if len(policyErr.Violations) > 0 {
+		return policyErr
+	}
+	return nil
+}
+
+// containsPersonalInfo prüft, ob das Passwort Benutzername, E-Mail-Adresse oder deren lokalen Teil enthält.
+func containsPersonalInfo(password, username, email string) bool {
+	password = strings.ToLower(password)
+	candidates := []string{username, email}
+	if local, _, found := strings.Cut(email, "@"); found {
+		candidates = append(candidates, local)
+	}
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if utf8.RuneCountInString(candidate) >= minPersonalInfoLength && strings.Contains(password, candidate) {
+			return true
+		}
+	}
+	return false
+}
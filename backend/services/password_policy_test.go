@@ -0,0 +1,31 @@
+package services
+
+import "testing"
+
+func TestContainsPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name                      string
+		password, username, email string
+		want                      bool
+	}{
+		{"Benutzername", "xx-alice-2024", "alice", "a@example.com", true},
+		{"Benutzername in anderer Schreibweise", "xxALICExx", "Alice", "", true},
+		{"Benutzername mit Leerzeichen", "xxalicexx", " alice ", "", true},
+		{"E-Mail-Adresse", "bob@example.com!", "someone", "bob@example.com", true},
+		{"lokaler Teil der E-Mail-Adresse", "Bobby-Tables-99", "someone", "bobby@example.com", true},
+		{"Domain der E-Mail-Adresse", "example.com-secret", "someone", "bob@example.com", false},
+		{"kurzer Benutzername", "xx-al-2024", "al", "", false},
+		{"kurzer lokaler Teil", "xx-bo-2024", "someone", "bo@example.com", false},
+		{"Umlaute zählen als ein Zeichen", "ÜÖß-geheim", "üöß", "", true},
+		{"zu kurz mit Umlauten", "xxüöxx", "üö", "", false},
+		{"keine Angaben", "correct horse battery staple", "", "", false},
+		{"nicht enthalten", "correct horse battery staple", "alice", "alice@example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsPersonalInfo(tt.password, tt.username, tt.email); got != tt.want {
+				t.Errorf("containsPersonalInfo(%q, %q, %q) = %v, erwartet %v", tt.password, tt.username, tt.email, got, tt.want)
+			}
+		})
+	}
+}
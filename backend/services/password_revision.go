@@ -0,0 +1,232 @@
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClientInfo beschreibt das Gerät bzw. die Sitzung, von der eine Änderung ausgeht.
+// Die Angaben werden zusammen mit jeder Revision gespeichert.
+type ClientInfo struct {
+	DeviceID  string // Vom Client gemeldete Geräte-ID (optional)
+	UserAgent string // User-Agent der Anfrage
+	IPAddress string // IP-Adresse der Anfrage
+}
+
+// RevisionRetention legt fest, wie lange Revisionen von Einträgen aufbewahrt werden.
+// Die jüngste Revision eines Eintrags bleibt immer erhalten.
+type RevisionRetention struct {
+	MaxPerItem int           // Maximale Anzahl Revisionen pro Eintrag (0 = unbegrenzt)
+	MaxAge     time.Duration // Maximales Alter einer Revision (0 = unbegrenzt)
+}
+
+// GetPasswordRevisions ruft alle aufbewahrten Revisionen eines Eintrags ab (neueste zuerst).
+func (s *PasswordService) GetPasswordRevisions(passwordID, userID uint) ([]models.PasswordRevision, error) {
+	// Sicherstellen, dass der Eintrag dem Benutzer gehört
+	if err := s.DB.Select("id").Where("id = ? AND user_id = ?", passwordID, userID).First(&models.Password{}).Error; err != nil {
+		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
+	}
+
+	var revisions []models.PasswordRevision
+	if err := s.DB.Where("password_id = ? AND user_id = ?", passwordID, userID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Revisionen von Passwort %d: %w", passwordID, err)
+	}
+	return revisions, nil
+}
+
+// RestorePasswordRevision setzt einen Eintrag auf den Stand einer früheren Revision zurück.
+// Die Wiederherstellung ist selbst eine Änderung und erzeugt eine neue Revision,
+// sodass auch sie rückgängig gemacht werden kann.
+func (s *PasswordService) RestorePasswordRevision(passwordID, userID uint, revision int, client ClientInfo) (*models.Password, error) {
+	var entry models.PasswordRevision
+	if err := s.DB.Where("password_id = ? AND user_id = ? AND revision = ?", passwordID, userID, revision).First(&entry).Error; err != nil {
+		return nil, fmt.Errorf("Revision %d von Passwort %d nicht gefunden: %w", revision, passwordID, notFound(err, ErrRevisionNotFound))
+	}
+
+	snapshot, err := DecodeRevisionSnapshot(&entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updatePassword(passwordID, userID, snapshotToUpdateRequest(snapshot), models.RevisionActionRestore, client)
+}
+
+// PurgeExpiredRevisions löscht Revisionen, die älter als die konfigurierte Aufbewahrungsdauer sind.
+// Die jüngste Revision jedes Eintrags bleibt erhalten, damit der aktuelle Stand nachvollziehbar ist.
+func (s *PasswordService) PurgeExpiredRevisions() (int64, error) {
+	if s.Revisions.MaxAge <= 0 {
+		return 0, nil
+	}
+
+	latest := s.DB.Model(&models.PasswordRevision{}).Select("MAX(id)").Group("password_id")
+	result := s.DB.Where("created_at < ? AND id NOT IN (?)", time.Now().Add(-s.Revisions.MaxAge), latest).
+		Delete(&models.PasswordRevision{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("Fehler beim Bereinigen abgelaufener Revisionen: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DecodeRevisionSnapshot liest den verschlüsselten Stand eines Eintrags aus einer Revision.
+func DecodeRevisionSnapshot(revision *models.PasswordRevision) (*schemas.PasswordSnapshot, error) {
+	var snapshot schemas.PasswordSnapshot
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("Revision %d von Passwort %d ist beschädigt: %w", revision.Revision, revision.PasswordID, err)
+	}
+	return &snapshot, nil
+}
+
+// recordRevisions legt für jeden Eintrag eine Revision mit seinem aktuellen Stand an
+// und kürzt danach die Revisionen der betroffenen Einträge auf MaxPerItem
+func (s *PasswordService) recordRevisions(tx *gorm.DB, passwords []models.Password, action string, client ClientInfo) error {
+	if len(passwords) == 0 {
+		return nil
+	}
+
+	revisions := make([]models.PasswordRevision, 0, len(passwords))
+	passwordIDs := make([]uint, 0, len(passwords))
+	for i := range passwords {
+		snapshot, err := json.Marshal(snapshotOf(&passwords[i]))
+		if err != nil {
+			return fmt.Errorf("Fehler beim Serialisieren von Passwort %d: %w", passwords[i].ID, err)
+		}
+		revisions = append(revisions, models.PasswordRevision{
+			PasswordID: passwords[i].ID,
+			UserID:     passwords[i].UserID,
+			Revision:   passwords[i].Revision,
+			Action:     action,
+			Snapshot:   string(snapshot),
+			DeviceID:   truncate(client.DeviceID, 128),
+			UserAgent:  truncate(client.UserAgent, 512),
+			IPAddress:  truncate(client.IPAddress, 64),
+		})
+		passwordIDs = append(passwordIDs, passwords[i].ID)
+	}
+
+	if err := tx.CreateInBatches(revisions, 1000).Error; err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Revisionen: %w", err)
+	}
+
+	// Neu angelegte Einträge haben nur eine Revision, dort gibt es nichts zu kürzen
+	if s.Revisions.MaxPerItem <= 0 || action == models.RevisionActionCreate {
+		return nil
+	}
+	for _, passwordID := range passwordIDs {
+		retained := tx.Model(&models.PasswordRevision{}).Select("id").
+			Where("password_id = ?", passwordID).Order("revision DESC").Limit(s.Revisions.MaxPerItem)
+		if err := tx.Where("password_id = ? AND id NOT IN (?)", passwordID, retained).Delete(&models.PasswordRevision{}).Error; err != nil {
+			return fmt.Errorf("Fehler beim Kürzen der Revisionen von Passwort %d: %w", passwordID, err)
+		}
+	}
+	return nil
+}
+
+// snapshotOf erfasst den verschlüsselten Inhalt eines Eintrags inklusive benutzerdefinierter Felder und URIs.
+func snapshotOf(password *models.Password) schemas.PasswordSnapshot {
+	fields := make([]schemas.CustomFieldRequest, 0, len(password.Fields))
+	for _, field := range password.Fields {
+		fields = append(fields, schemas.CustomFieldRequest{
+			Type:           field.Type,
+			EncryptedName:  field.EncryptedName,
+			NameIV:         field.NameIV,
+			NameTag:        field.NameTag,
+			EncryptedValue: field.EncryptedValue,
+			ValueIV:        field.ValueIV,
+			ValueTag:       field.ValueTag,
+			LinkedTo:       field.LinkedTo,
+		})
+	}
+
+	var uris []schemas.URIRequest
+	for _, uri := range password.URIs {
+		uris = append(uris, schemas.URIRequest{
+			URI:          uri.URI,
+			Match:        uri.Match,
+			EncryptedURI: uri.EncryptedURI,
+			URIIV:        uri.URIIV,
+			URITag:       uri.URITag,
+			DomainIndex:  uri.DomainIndex,
+		})
+	}
+
+	return schemas.PasswordSnapshot{
+		WebsiteURL:        password.WebsiteURL,
+		EncryptedURL:      password.EncryptedURL,
+		URLIV:             password.URLIV,
+		URLTag:            password.URLTag,
+		DomainIndex:       password.DomainIndex,
+		EncryptedUsername: password.EncryptedUsername,
+		UsernameIV:        password.UsernameIV,
+		UsernameTag:       password.UsernameTag,
+		EncryptedPassword: password.EncryptedPassword,
+		PasswordIV:        password.PasswordIV,
+		PasswordTag:       password.PasswordTag,
+		Fingerprint:       password.Fingerprint,
+		EncryptedNotes:    password.EncryptedNotes,
+		NotesIV:           password.NotesIV,
+		NotesTag:          password.NotesTag,
+		Type:              password.Type,
+		EncryptedName:     password.EncryptedName,
+		NameIV:            password.NameIV,
+		NameTag:           password.NameTag,
+		EncryptedData:     password.EncryptedData,
+		DataIV:            password.DataIV,
+		DataTag:           password.DataTag,
+		DataVersion:       password.DataVersion,
+		Fields:            fields,
+		URIs:              uris,
+	}
+}
+
+// snapshotToUpdateRequest wandelt einen gespeicherten Stand in eine vollständige Aktualisierung um,
+// die alle Inhaltsfelder sowie die komplette Feld- und URI-Liste ersetzt
+func snapshotToUpdateRequest(snapshot *schemas.PasswordSnapshot) *schemas.UpdatePasswordRequest {
+	fields := snapshot.Fields
+	if fields == nil {
+		fields = []schemas.CustomFieldRequest{}
+	}
+	uris := snapshot.URIs
+	if uris == nil {
+		uris = []schemas.URIRequest{}
+	}
+
+	return &schemas.UpdatePasswordRequest{
+		WebsiteURL:        &snapshot.WebsiteURL,
+		EncryptedURL:      &snapshot.EncryptedURL,
+		URLIV:             &snapshot.URLIV,
+		URLTag:            &snapshot.URLTag,
+		DomainIndex:       &snapshot.DomainIndex,
+		EncryptedUsername: &snapshot.EncryptedUsername,
+		UsernameIV:        &snapshot.UsernameIV,
+		UsernameTag:       &snapshot.UsernameTag,
+		EncryptedPassword: &snapshot.EncryptedPassword,
+		PasswordIV:        &snapshot.PasswordIV,
+		PasswordTag:       &snapshot.PasswordTag,
+		Fingerprint:       &snapshot.Fingerprint,
+		EncryptedNotes:    &snapshot.EncryptedNotes,
+		NotesIV:           &snapshot.NotesIV,
+		NotesTag:          &snapshot.NotesTag,
+		EncryptedName:     &snapshot.EncryptedName,
+		NameIV:            &snapshot.NameIV,
+		NameTag:           &snapshot.NameTag,
+		EncryptedData:     &snapshot.EncryptedData,
+		DataIV:            &snapshot.DataIV,
+		DataTag:           &snapshot.DataTag,
+		DataVersion:       &snapshot.DataVersion,
+		Fields:            &fields,
+		URIs:              &uris,
+	}
+}
+
+// truncate kürzt einen vom Client gelieferten Wert auf die Spaltenlänge.
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
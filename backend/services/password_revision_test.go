@@ -0,0 +1,139 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+// ptr liefert einen Zeiger auf value (für optionale Felder von Aktualisierungen).
+func ptr(value string) *string {
+	return &value
+}
+
+// currentItem liefert einen Eintrag, dessen Inhaltsfelder alle vom gespeicherten Stand abweichen.
+func currentItem(itemType string) models.Password {
+	changed := time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)
+	return models.Password{
+		ID: 1, UserID: 7, Type: itemType, Revision: 9, PasswordChangedAt: &changed,
+		WebsiteURL: "https://neu.example.org", EncryptedURL: "neu:url", URLIV: "neu", URLTag: "neu", DomainIndex: strings.Repeat("a", 64),
+		EncryptedUsername: "neu:user", UsernameIV: "neu", UsernameTag: "neu",
+		EncryptedPassword: "neu:secret", PasswordIV: "neu", PasswordTag: "neu", Fingerprint: strings.Repeat("b", 64),
+		EncryptedNotes: "neu:notes", NotesIV: "neu", NotesTag: "neu",
+		EncryptedName: "neu:name", NameIV: "neu", NameTag: "neu",
+		EncryptedData: "neu:data", DataIV: "neu", DataTag: "neu", DataVersion: 2,
+		Fields: []models.PasswordField{{Type: models.FieldTypeText, EncryptedName: "neu:feld", NameIV: "neu", NameTag: "neu"}},
+		URIs:   []models.PasswordURI{{URI: "neu.example.org", Match: models.URIMatchExact}},
+	}
+}
+
+func TestRestoreRevisionRoundTrip(t *testing.T) {
+	for _, stored := range legacyItems() {
+		t.Run(stored.Type, func(t *testing.T) {
+			encoded, err := json.Marshal(snapshotOf(&stored))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapshot, err := DecodeRevisionSnapshot(&models.PasswordRevision{PasswordID: stored.ID, Revision: 3, Snapshot: string(encoded)})
+			if err != nil {
+				t.Fatalf("DecodeRevisionSnapshot: %v", err)
+			}
+
+			current := currentItem(stored.Type)
+			req := snapshotToUpdateRequest(snapshot)
+			previous, changed := applyPasswordUpdate(&current, req)
+
+			// Felder und URIs ersetzt der Service anhand der Anfrage; alle übrigen Inhalte
+			// müssen nach dem Zurücksetzen dem gespeicherten Stand entsprechen, auch leere
+			got, want := snapshotOf(&current), *snapshot
+			got.Fields, got.URIs, want.Fields, want.URIs = nil, nil, nil, nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("zurückgesetzter Stand = %+v, erwartet %+v", got, want)
+			}
+			if req.Fields == nil || !reflect.DeepEqual(*req.Fields, snapshotOf(&stored).Fields) {
+				t.Errorf("Felder = %v, erwartet %v", req.Fields, snapshotOf(&stored).Fields)
+			}
+			if req.URIs == nil || len(*req.URIs) != len(stored.URIs) {
+				t.Errorf("URIs = %v, erwartet %d", req.URIs, len(stored.URIs))
+			}
+
+			// Der bisherige Chiffretext des Passworts landet im Verlauf
+			if !changed || previous.EncryptedPassword != "neu:secret" || previous.ValidFrom != current.PasswordChangedAt {
+				t.Errorf("Verlauf = %+v, geändert = %v", previous, changed)
+			}
+		})
+	}
+}
+
+func TestSnapshotToUpdateRequestClearsLists(t *testing.T) {
+	// Ein Stand ohne Felder und URIs muss die aktuellen Listen leeren, nicht unverändert lassen
+	snapshot, err := DecodeRevisionSnapshot(&models.PasswordRevision{Snapshot: `{"type":"note","encrypted_data":"data"}`})
+	if err != nil {
+		t.Fatalf("DecodeRevisionSnapshot: %v", err)
+	}
+	req := snapshotToUpdateRequest(snapshot)
+	if req.Fields == nil || len(*req.Fields) != 0 || req.URIs == nil || len(*req.URIs) != 0 {
+		t.Errorf("Felder = %v, URIs = %v, erwartet leere Listen", req.Fields, req.URIs)
+	}
+}
+
+func TestDecodeRevisionSnapshotCorrupt(t *testing.T) {
+	_, err := DecodeRevisionSnapshot(&models.PasswordRevision{PasswordID: 4, Revision: 2, Snapshot: `{"type":`})
+	if err == nil || !strings.Contains(err.Error(), "Revision 2 von Passwort 4") {
+		t.Errorf("DecodeRevisionSnapshot = %v, erwartet Fehler mit Revision und Eintrag", err)
+	}
+}
+
+func TestApplyPasswordUpdateFingerprint(t *testing.T) {
+	newPassword, newFingerprint := "anders", strings.Repeat("C", 64)
+	tests := []struct {
+		name            string
+		password        *string
+		fingerprint     *string
+		wantChanged     bool
+		wantFingerprint string
+	}{
+		{name: "nur Notizen", wantFingerprint: strings.Repeat("b", 64)},
+		{name: "neues Passwort ohne Fingerabdruck", password: &newPassword, wantChanged: true},
+		{name: "neues Passwort mit Fingerabdruck", password: &newPassword, fingerprint: &newFingerprint, wantChanged: true, wantFingerprint: strings.Repeat("c", 64)},
+		{name: "gleiches Passwort", password: ptr("neu:secret"), wantFingerprint: strings.Repeat("b", 64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := currentItem(models.ItemTypeLogin)
+			snapshot := snapshotOf(&current)
+			req := snapshotToUpdateRequest(&snapshot)
+			req.EncryptedNotes = ptr("geändert")
+			if tt.password != nil {
+				req.EncryptedPassword = tt.password
+			}
+			req.Fingerprint = tt.fingerprint
+
+			_, changed := applyPasswordUpdate(&current, req)
+			if changed != tt.wantChanged || current.Fingerprint != tt.wantFingerprint {
+				t.Errorf("geändert = %v, Fingerabdruck = %q, erwartet %v, %q", changed, current.Fingerprint, tt.wantChanged, tt.wantFingerprint)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value string
+		max   int
+		want  string
+	}{
+		{"", 3, ""},
+		{"abc", 3, "abc"},
+		{"abcd", 3, "abc"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.value, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, erwartet %q", tt.value, tt.max, got, tt.want)
+		}
+	}
+}
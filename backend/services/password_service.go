@@ -6,42 +6,90 @@ package services
 import (
 	"backend/models"
 	"backend/schemas"
+	"backend/validation"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// Anzahl früherer Passwörter, die pro Eintrag im Verlauf aufbewahrt werden
+const passwordHistoryLimit = 10
+
+// Fehler bei der Validierung von typisierten Tresor-Einträgen
+var (
+	ErrInvalidItemType    = NewError(ErrValidation, "invalid_item_type", "Unbekannter Eintragstyp")
+	ErrMissingItemPayload = NewError(ErrValidation, "missing_item_payload", "Verschlüsselter Payload (encrypted_data, data_iv, data_tag, data_version) ist für diesen Eintragstyp erforderlich")
+	ErrInvalidCustomField = NewError(ErrValidation, "invalid_custom_field", "Ungültiges benutzerdefiniertes Feld")
+	ErrInvalidURI         = NewError(ErrValidation, "invalid_uri", "Ungültige URI")
+	ErrInvalidWebsiteURL  = NewError(ErrValidation, "invalid_website_url", "Ungültige Website-URL")
+	ErrInvalidFingerprint = NewError(ErrValidation, "invalid_fingerprint", "Ungültiger Passwort-Fingerabdruck")
+)
+
+// Länge eines vom Client berechneten HMAC-SHA256 (Blind-Index, Fingerabdruck) in Hex-Darstellung
+const hmacHexLength = 64
+
+// Grenzen für die URIs eines Eintrags
+const (
+	maxURIsPerItem = 50
+	maxURILength   = 2048
+)
+
+// ErrRevisionConflict wird zurückgegeben, wenn ein Eintrag seit dem Lesen durch den Client geändert wurde.
+var ErrRevisionConflict = NewError(ErrConflict, "revision_conflict", "Eintrag wurde zwischenzeitlich geändert")
+
+// RevisionConflictError enthält den aktuellen Stand eines Eintrags, dessen Revision nicht
+// der vom Client erwarteten entspricht. errors.Is(err, ErrRevisionConflict) ist dafür wahr.
+type RevisionConflictError struct {
+	Current *models.Password // Aktueller Stand auf dem Server
+}
+
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("%v: Passwort %d hat Revision %d", ErrRevisionConflict, e.Current.ID, e.Current.Revision)
+}
+
+func (e *RevisionConflictError) Unwrap() error {
+	return ErrRevisionConflict
+}
+
 // PasswordService verwaltet alle passwortbezogenen Datenbankoperationen
 // Arbeitet ausschließlich mit bereits verschlüsselten Daten (Zero-Knowledge)
 type PasswordService struct {
-	DB *gorm.DB // Datenbankverbindung für Passwort-CRUD-Operationen
+	DB          *gorm.DB           // Datenbankverbindung für Passwort-CRUD-Operationen
+	Attachments *AttachmentService // Dienst für Anhänge, die zusammen mit dem Eintrag gelöscht werden
+	Snapshots   *SnapshotService   // Dienst für Tresor-Snapshots vor Massenoperationen
+	Revisions   RevisionRetention  // Aufbewahrung der Revisionen von Einträgen
+	Tombstones  time.Duration      // Aufbewahrungsdauer von Tombstones für die Delta-Synchronisation (0 = unbegrenzt)
 }
 
 // NewPasswordService erstellt eine neue PasswordService-Instanz.
-func NewPasswordService(db *gorm.DB) *PasswordService {
-	return &PasswordService{DB: db}
+func NewPasswordService(db *gorm.DB, attachmentService *AttachmentService, snapshotService *SnapshotService, revisions RevisionRetention, tombstoneRetention time.Duration) *PasswordService {
+	return &PasswordService{DB: db, Attachments: attachmentService, Snapshots: snapshotService, Revisions: revisions, Tombstones: tombstoneRetention}
 }
 
 // CreatePassword erstellt neuen verschlüsselten Passwort-Eintrag
 // Alle sensiblen Daten werden bereits client-seitig verschlüsselt übergeben
 // Speichert IV und Tag für AES-GCM-Entschlüsselung
-func (s *PasswordService) CreatePassword(userID uint, req *schemas.CreatePasswordRequest) (*models.Password, error) {
-	password := &models.Password{
-		UserID:            userID,                // Verknüpfung zum Benutzer
-		WebsiteURL:        req.WebsiteURL,        // Klartext-URL für Zuordnung
-		EncryptedUsername: req.EncryptedUsername, // AES-verschlüsselter Benutzername
-		UsernameIV:        req.UsernameIV,        // Initialisierungsvektor für Username
-		UsernameTag:       req.UsernameTag,       // Authentifizierungs-Tag für Username
-		EncryptedPassword: req.EncryptedPassword, // AES-verschlüsseltes Passwort
-		PasswordIV:        req.PasswordIV,        // Initialisierungsvektor für Passwort
-		PasswordTag:       req.PasswordTag,       // Authentifizierungs-Tag für Passwort
-		EncryptedNotes:    req.EncryptedNotes,    // AES-verschlüsselte Notizen (optional)
-		NotesIV:           req.NotesIV,           // Initialisierungsvektor für Notizen
-		NotesTag:          req.NotesTag,          // Authentifizierungs-Tag für Notizen
+func (s *PasswordService) CreatePassword(userID uint, req *schemas.CreatePasswordRequest, client ClientInfo) (*models.Password, error) {
+	password, err := newPasswordFromRequest(userID, req)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.DB.Create(password).Error; err != nil {
-		return nil, fmt.Errorf("Fehler beim Erstellen des Passworts: %w", err)
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if password.SyncRevision, err = nextVaultRevision(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Create(password).Error; err != nil {
+			return fmt.Errorf("Fehler beim Erstellen des Passworts: %w", err)
+		}
+		// Erste Revision mit dem Ausgangsstand anlegen
+		return s.recordRevisions(tx, []models.Password{*password}, models.RevisionActionCreate, client)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return password, nil
@@ -50,44 +98,96 @@ func (s *PasswordService) CreatePassword(userID uint, req *schemas.CreatePasswor
 // BatchCreatePasswords erstellt viele Passwörter in optimierter Transaktion
 // Verwendet GORM's CreateInBatches für bessere Performance bei großen Datenmengen
 // Batch-Größe von 1000 balanciert Speicher und Geschwindigkeit
-func (s *PasswordService) BatchCreatePasswords(userID uint, req *schemas.BatchCreatePasswordRequest) ([]models.Password, error) {
-	var passwordsToCreate []models.Password
-	for _, p := range req.Passwords {
-		passwordsToCreate = append(passwordsToCreate, models.Password{
-			UserID:            userID,
-			WebsiteURL:        p.WebsiteURL,
-			EncryptedUsername: p.EncryptedUsername,
-			UsernameIV:        p.UsernameIV,
-			UsernameTag:       p.UsernameTag,
-			EncryptedPassword: p.EncryptedPassword,
-			PasswordIV:        p.PasswordIV,
-			PasswordTag:       p.PasswordTag,
-			EncryptedNotes:    p.EncryptedNotes,
-			NotesIV:           p.NotesIV,
-			NotesTag:          p.NotesTag,
-		})
+// Vor dem Import wird ein Snapshot des Tresors angelegt, um ihn bei Bedarf zurücksetzen zu können
+// Ungültige Einträge werden pro Index gemeldet; im Modus atomic wird dann nichts importiert,
+// im Modus best_effort werden die gültigen Einträge trotzdem angelegt
+func (s *PasswordService) BatchCreatePasswords(userID uint, req *schemas.BatchCreatePasswordRequest, client ClientInfo) ([]BatchItemOutcome, bool, error) {
+	mode, err := batchMode(req.Mode)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(req.Passwords) == 0 {
+		return nil, false, fmt.Errorf("%w: mindestens ein Eintrag erforderlich", ErrInvalidBatch)
+	}
+
+	outcomes := make([]BatchItemOutcome, len(req.Passwords))
+	passwordsToCreate := make([]models.Password, 0, len(req.Passwords))
+	positions := make([]int, 0, len(req.Passwords)) // Index in der Anfrage je anzulegendem Eintrag
+	invalid := false
+	for i := range req.Passwords {
+		outcomes[i].ClientRef = req.Passwords[i].ClientRef
+		// Einträge werden einzeln geprüft, damit Fehler pro Index gemeldet werden können
+		if err := validation.Struct(&req.Passwords[i].CreatePasswordRequest); err != nil {
+			outcomes[i].Err = err
+			invalid = true
+			continue
+		}
+		password, err := newPasswordFromRequest(userID, &req.Passwords[i].CreatePasswordRequest)
+		if err != nil {
+			outcomes[i].Err = err
+			invalid = true
+			continue
+		}
+		passwordsToCreate = append(passwordsToCreate, *password)
+		positions = append(positions, i)
+	}
+
+	if invalid && mode == BatchModeAtomic {
+		abortBatch(outcomes)
+		return outcomes, false, nil
+	}
+	if len(passwordsToCreate) == 0 {
+		return outcomes, false, nil
 	}
 
 	// Transaktion für atomare Batch-Operation starten
-	err := s.DB.Transaction(func(tx *gorm.DB) error {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.Snapshots.capture(tx, userID, models.SnapshotReasonBatchImport); err != nil {
+			return err
+		}
+
+		// Alle Einträge des Imports gehören zur selben Tresor-Revision
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+		for i := range passwordsToCreate {
+			passwordsToCreate[i].SyncRevision = revision
+		}
+
 		// Optimierte Massen-Einfügung: 1000er-Batches reduzieren Memory-Usage
 		if err := tx.CreateInBatches(passwordsToCreate, 1000).Error; err != nil {
 			return fmt.Errorf("Fehler beim Massen-Erstellen der Passwörter: %w", err)
 		}
-		return nil
+		return s.recordRevisions(tx, passwordsToCreate, models.RevisionActionCreate, client)
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return passwordsToCreate, nil
+	// Neue Server-IDs den Positionen der Anfrage zuordnen
+	for j, i := range positions {
+		outcomes[i].ID = passwordsToCreate[j].ID
+		outcomes[i].Password = &passwordsToCreate[j]
+	}
+	return outcomes, true, nil
 }
 
 // GetPasswordsByUserID ruft alle Passwörter für einen bestimmten Benutzer ab.
-func (s *PasswordService) GetPasswordsByUserID(userID uint) ([]models.Password, error) {
+// Ist itemType gesetzt, werden nur Einträge dieses Typs zurückgegeben.
+func (s *PasswordService) GetPasswordsByUserID(userID uint, itemType string) ([]models.Password, error) {
+	if itemType != "" && !models.IsValidItemType(itemType) {
+		return nil, ErrInvalidItemType
+	}
+
+	query := s.DB.Scopes(preloadItemDetails).Where("user_id = ?", userID)
+	if itemType != "" {
+		query = query.Where("type = ?", itemType)
+	}
+
 	var passwords []models.Password
-	if err := s.DB.Where("user_id = ?", userID).Find(&passwords).Error; err != nil {
+	if err := query.Find(&passwords).Error; err != nil {
 		return nil, fmt.Errorf("Fehler beim Abrufen der Passwörter für Benutzer %d: %w", userID, err)
 	}
 	return passwords, nil
@@ -96,24 +196,165 @@ func (s *PasswordService) GetPasswordsByUserID(userID uint) ([]models.Password,
 // GetPasswordByID ruft ein einzelnes Passwort anhand seiner ID und der Benutzer-ID ab.
 func (s *PasswordService) GetPasswordByID(passwordID, userID uint) (*models.Password, error) {
 	var password models.Password
-	if err := s.DB.Where("id = ? AND user_id = ?", passwordID, userID).First(&password).Error; err != nil {
-		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden oder Fehler beim Abrufen: %w", passwordID, userID, err)
+	if err := s.DB.Scopes(preloadItemDetails).Where("id = ? AND user_id = ?", passwordID, userID).First(&password).Error; err != nil {
+		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden oder Fehler beim Abrufen: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
 	}
 	return &password, nil
 }
 
 // UpdatePassword aktualisiert einen bestehenden Passwort-Eintrag.
-func (s *PasswordService) UpdatePassword(passwordID, userID uint, req *schemas.UpdatePasswordRequest) (*models.Password, error) {
+func (s *PasswordService) UpdatePassword(passwordID, userID uint, req *schemas.UpdatePasswordRequest, client ClientInfo) (*models.Password, error) {
+	return s.updatePassword(passwordID, userID, req, models.RevisionActionUpdate, client)
+}
+
+// updatePassword wendet eine Teilaktualisierung an und legt dafür eine neue Revision an.
+// action unterscheidet normale Bearbeitungen von Wiederherstellungen früherer Revisionen.
+// Lesen, Prüfen und Speichern laufen in einer Transaktion unter der Sperre der Tresor-Revision,
+// sodass parallele Änderungen desselben Benutzers nacheinander angewendet werden
+func (s *PasswordService) updatePassword(passwordID, userID uint, req *schemas.UpdatePasswordRequest, action string, client ClientInfo) (*models.Password, error) {
+	var password *models.Password
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		syncRevision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+		password, err = s.updatePasswordTx(tx, syncRevision, passwordID, userID, req, action, client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return password, nil
+}
+
+// updatePasswordTx führt die Aktualisierung eines Eintrags innerhalb einer bestehenden Transaktion aus.
+// Der Aufrufer hat die Tresor-Revision syncRevision bereits vergeben (und damit den Benutzer gesperrt)
+func (s *PasswordService) updatePasswordTx(tx *gorm.DB, syncRevision int64, passwordID, userID uint, req *schemas.UpdatePasswordRequest, action string, client ClientInfo) (*models.Password, error) {
 	var password models.Password
 	// Das vorhandene Passwort abrufen, um sicherzustellen, dass es dem Benutzer gehört
-	if err := s.DB.Where("id = ? AND user_id = ?", passwordID, userID).First(&password).Error; err != nil {
-		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden oder Fehler beim Aktualisieren: %w", passwordID, userID, err)
+	if err := tx.Scopes(preloadItemDetails).Where("id = ? AND user_id = ?", passwordID, userID).First(&password).Error; err != nil {
+		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden oder Fehler beim Aktualisieren: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
+	}
+
+	// Änderung ablehnen, wenn der Client einen veralteten Stand bearbeitet hat
+	if req.ExpectedRevision != nil && *req.ExpectedRevision != password.Revision {
+		return nil, &RevisionConflictError{Current: &password}
+	}
+
+	previous, passwordChanged := applyPasswordUpdate(&password, req)
+
+	// Typabhängige Pflichtfelder und URL nach der Teilaktualisierung erneut prüfen
+	if err := validateItemPayload(&password); err != nil {
+		return nil, err
+	}
+
+	// Neue Feld- und URI-Listen vor dem Speichern validieren
+	var fields []models.PasswordField
+	if req.Fields != nil {
+		var err error
+		if fields, err = newPasswordFields(*req.Fields); err != nil {
+			return nil, err
+		}
+	}
+	var uris []models.PasswordURI
+	if req.URIs != nil {
+		var err error
+		if uris, err = newPasswordURIs(*req.URIs); err != nil {
+			return nil, err
+		}
+	}
+
+	if passwordChanged {
+		now := time.Now()
+		password.PasswordChangedAt = &now
 	}
+	password.Revision++
+	password.SyncRevision = syncRevision
+
+	// Ersetztes Passwort im Verlauf ablegen (nur wenn tatsächlich eines gesetzt war)
+	if passwordChanged && previous.EncryptedPassword != "" {
+		if err := appendPasswordHistory(tx, previous); err != nil {
+			return nil, err
+		}
+	}
+
+	// Passwort in der Datenbank speichern (Felder werden separat behandelt)
+	if err := tx.Omit("Fields", "URIs").Save(&password).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Aktualisieren des Passworts: %w", err)
+	}
+
+	if req.Fields != nil {
+		// Benutzerdefinierte Felder vollständig ersetzen, damit die Reihenfolge der Anfrage gilt
+		if err := tx.Where("password_id = ?", password.ID).Delete(&models.PasswordField{}).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Entfernen der benutzerdefinierten Felder: %w", err)
+		}
+		for i := range fields {
+			fields[i].PasswordID = password.ID
+		}
+		if len(fields) > 0 {
+			if err := tx.Create(&fields).Error; err != nil {
+				return nil, fmt.Errorf("Fehler beim Speichern der benutzerdefinierten Felder: %w", err)
+			}
+		}
+		password.Fields = fields
+	}
+
+	if req.URIs != nil {
+		// URIs ebenfalls vollständig ersetzen
+		if err := tx.Where("password_id = ?", password.ID).Delete(&models.PasswordURI{}).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Entfernen der URIs: %w", err)
+		}
+		for i := range uris {
+			uris[i].PasswordID = password.ID
+		}
+		if len(uris) > 0 {
+			if err := tx.Create(&uris).Error; err != nil {
+				return nil, fmt.Errorf("Fehler beim Speichern der URIs: %w", err)
+			}
+		}
+		password.URIs = uris
+	}
+
+	// Neuen Stand als Revision festhalten
+	if err := s.recordRevisions(tx, []models.Password{password}, action, client); err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+// applyPasswordUpdate überträgt die gesetzten Felder der Anfrage auf den Eintrag.
+// Gibt das bisherige Passwort als Verlaufseintrag zurück und ob sich das Passwort geändert hat
+func applyPasswordUpdate(password *models.Password, req *schemas.UpdatePasswordRequest) (*models.PasswordHistory, bool) {
+	// Vorheriges Passwort für den Verlauf merken, bevor es überschrieben wird
+	previous := &models.PasswordHistory{
+		PasswordID:        password.ID,
+		UserID:            password.UserID,
+		EncryptedPassword: password.EncryptedPassword,
+		PasswordIV:        password.PasswordIV,
+		PasswordTag:       password.PasswordTag,
+		ValidFrom:         password.PasswordChangedAt,
+	}
+	passwordChanged := (req.EncryptedPassword != nil && *req.EncryptedPassword != password.EncryptedPassword) ||
+		(req.PasswordIV != nil && *req.PasswordIV != password.PasswordIV) ||
+		(req.PasswordTag != nil && *req.PasswordTag != password.PasswordTag)
 
 	// Felder nur aktualisieren, wenn sie im Anfrage-Payload vorhanden sind
 	if req.WebsiteURL != nil {
 		password.WebsiteURL = *req.WebsiteURL
 	}
+	if req.EncryptedURL != nil {
+		password.EncryptedURL = *req.EncryptedURL
+	}
+	if req.URLIV != nil {
+		password.URLIV = *req.URLIV
+	}
+	if req.URLTag != nil {
+		password.URLTag = *req.URLTag
+	}
+	if req.DomainIndex != nil {
+		password.DomainIndex = strings.ToLower(*req.DomainIndex)
+	}
 	if req.EncryptedUsername != nil {
 		password.EncryptedUsername = *req.EncryptedUsername
 	}
@@ -132,6 +373,12 @@ func (s *PasswordService) UpdatePassword(passwordID, userID uint, req *schemas.U
 	if req.PasswordTag != nil {
 		password.PasswordTag = *req.PasswordTag
 	}
+	if req.Fingerprint != nil {
+		password.Fingerprint = strings.ToLower(*req.Fingerprint)
+	} else if passwordChanged {
+		// Ohne neuen Fingerabdruck passt der alte nicht mehr zum Passwort
+		password.Fingerprint = ""
+	}
 	if req.EncryptedNotes != nil {
 		password.EncryptedNotes = *req.EncryptedNotes
 	}
@@ -141,20 +388,493 @@ func (s *PasswordService) UpdatePassword(passwordID, userID uint, req *schemas.U
 	if req.NotesTag != nil {
 		password.NotesTag = *req.NotesTag
 	}
+	if req.EncryptedName != nil {
+		password.EncryptedName = *req.EncryptedName
+	}
+	if req.NameIV != nil {
+		password.NameIV = *req.NameIV
+	}
+	if req.NameTag != nil {
+		password.NameTag = *req.NameTag
+	}
+	if req.EncryptedData != nil {
+		password.EncryptedData = *req.EncryptedData
+	}
+	if req.DataIV != nil {
+		password.DataIV = *req.DataIV
+	}
+	if req.DataTag != nil {
+		password.DataTag = *req.DataTag
+	}
+	if req.DataVersion != nil {
+		password.DataVersion = *req.DataVersion
+	}
 
-	// Passwort in der Datenbank speichern
-	if err := s.DB.Save(password).Error; err != nil {
-		return nil, fmt.Errorf("Fehler beim Aktualisieren des Passworts: %w", err)
+	return previous, passwordChanged
+}
+
+// GetPasswordHistory ruft die früheren Passwörter eines Eintrags ab (neueste zuerst).
+func (s *PasswordService) GetPasswordHistory(passwordID, userID uint) ([]models.PasswordHistory, error) {
+	// Sicherstellen, dass der Eintrag dem Benutzer gehört
+	if err := s.DB.Select("id").Where("id = ? AND user_id = ?", passwordID, userID).First(&models.Password{}).Error; err != nil {
+		return nil, fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
 	}
 
-	return &password, nil
+	var history []models.PasswordHistory
+	if err := s.DB.Where("password_id = ? AND user_id = ?", passwordID, userID).Order("id DESC").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Passwort-Verlaufs von Passwort %d: %w", passwordID, err)
+	}
+	return history, nil
+}
+
+// DeletePassword verschiebt einen Passwort-Eintrag in den Papierkorb (Soft Delete).
+// Felder, Verlauf und Anhänge bleiben erhalten, damit der Eintrag wiederhergestellt werden kann.
+// Ist expectedRevision gesetzt, wird nur gelöscht, wenn der Eintrag noch diese Revision hat.
+func (s *PasswordService) DeletePassword(passwordID, userID uint, expectedRevision *int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+		return trashPasswordTx(tx, revision, passwordID, userID, expectedRevision)
+	})
 }
 
-// DeletePassword löscht einen Passwort-Eintrag aus der Datenbank.
-func (s *PasswordService) DeletePassword(passwordID, userID uint) error {
+// trashPasswordTx verschiebt einen Eintrag innerhalb einer bestehenden Transaktion in den Papierkorb.
+func trashPasswordTx(tx *gorm.DB, syncRevision int64, passwordID, userID uint, expectedRevision *int) error {
+	var password models.Password
+	if err := tx.Scopes(preloadItemDetails).Where("id = ? AND user_id = ?", passwordID, userID).First(&password).Error; err != nil {
+		return fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht gefunden: %w", passwordID, userID, notFound(err, ErrPasswordNotFound))
+	}
+	if expectedRevision != nil && *expectedRevision != password.Revision {
+		return &RevisionConflictError{Current: &password}
+	}
+
 	// Löschen, um sicherzustellen, dass nur das eigene Passwort gelöscht wird
-	if err := s.DB.Where("id = ? AND user_id = ?", passwordID, userID).Delete(&models.Password{}).Error; err != nil {
+	if err := tx.Model(&password).Updates(map[string]interface{}{"deleted_at": time.Now(), "sync_revision": syncRevision}).Error; err != nil {
 		return fmt.Errorf("Fehler beim Löschen des Passworts mit ID %d für Benutzer %d: %w", passwordID, userID, err)
 	}
 	return nil
 }
+
+// GetTrash ruft alle Einträge im Papierkorb eines Benutzers ab (zuletzt gelöschte zuerst).
+func (s *PasswordService) GetTrash(userID uint) ([]models.Password, error) {
+	var passwords []models.Password
+	if err := s.DB.Unscoped().Scopes(preloadItemDetails).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").Find(&passwords).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Papierkorbs für Benutzer %d: %w", userID, err)
+	}
+	return passwords, nil
+}
+
+// RestorePassword holt einen Eintrag aus dem Papierkorb zurück.
+func (s *PasswordService) RestorePassword(passwordID, userID uint) (*models.Password, error) {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Model(&models.Password{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", passwordID, userID).
+			Updates(map[string]interface{}{"deleted_at": nil, "sync_revision": revision})
+		if result.Error != nil {
+			return fmt.Errorf("Fehler beim Wiederherstellen des Passworts mit ID %d: %w", passwordID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht im Papierkorb: %w", passwordID, userID, ErrPasswordNotInTrash.Wrap(gorm.ErrRecordNotFound))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPasswordByID(passwordID, userID)
+}
+
+// DeletePasswordPermanently löscht einen Eintrag aus dem Papierkorb endgültig,
+// zusammen mit Feldern, Verlauf und Anhängen.
+func (s *PasswordService) DeletePasswordPermanently(passwordID, userID uint) error {
+	var attachmentKeys []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// Nur Einträge im Papierkorb dürfen endgültig gelöscht werden
+		var count int64
+		if err := tx.Unscoped().Model(&models.Password{}).
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", passwordID, userID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("Fehler beim Abrufen des Passworts mit ID %d: %w", passwordID, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("Passwort mit ID %d für Benutzer %d nicht im Papierkorb: %w", passwordID, userID, ErrPasswordNotInTrash.Wrap(gorm.ErrRecordNotFound))
+		}
+
+		if err := recordTombstones(tx, []uint{passwordID}); err != nil {
+			return err
+		}
+		keys, err := purgePasswords(tx, []uint{passwordID})
+		attachmentKeys = keys
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Attachments.removeObjects(attachmentKeys)
+	return nil
+}
+
+// EmptyTrash löscht alle Einträge im Papierkorb eines Benutzers endgültig
+// und gibt die Anzahl der gelöschten Einträge zurück.
+func (s *PasswordService) EmptyTrash(userID uint) (int, error) {
+	var ids []uint
+	if err := s.DB.Unscoped().Model(&models.Password{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("Fehler beim Abrufen des Papierkorbs für Benutzer %d: %w", userID, err)
+	}
+	if err := s.purgeInBatches(ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// PurgeExpiredTrash löscht Einträge endgültig, deren Aufbewahrungsdauer im Papierkorb abgelaufen ist.
+// Die Dauer wird pro Benutzer über TrashRetentionDays konfiguriert (0 = nie automatisch löschen).
+func (s *PasswordService) PurgeExpiredTrash() (int, error) {
+	var ids []uint
+	if err := s.DB.Unscoped().Model(&models.Password{}).
+		Joins("JOIN users ON users.id = passwords.user_id").
+		Where("passwords.deleted_at IS NOT NULL AND users.trash_retention_days > 0").
+		Where("passwords.deleted_at < NOW() - make_interval(days => users.trash_retention_days)").
+		Pluck("passwords.id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("Fehler beim Ermitteln abgelaufener Papierkorb-Einträge: %w", err)
+	}
+	if err := s.purgeInBatches(ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purgeInBatches löscht Einträge endgültig in Transaktionen zu je 1000 IDs,
+// damit große Papierkörbe keine langen Sperren verursachen
+func (s *PasswordService) purgeInBatches(ids []uint) error {
+	for _, batch := range chunkIDs(ids, 1000) {
+		var attachmentKeys []string
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			if err := recordTombstones(tx, batch); err != nil {
+				return err
+			}
+			keys, err := purgePasswords(tx, batch)
+			attachmentKeys = keys
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		s.Attachments.removeObjects(attachmentKeys)
+	}
+	return nil
+}
+
+// chunkIDs teilt ids in aufeinanderfolgende Blöcke von höchstens size IDs auf.
+func chunkIDs(ids []uint, size int) [][]uint {
+	chunks := make([][]uint, 0, (len(ids)+size-1)/size)
+	for start := 0; start < len(ids); start += size {
+		chunks = append(chunks, ids[start:min(start+size, len(ids))])
+	}
+	return chunks
+}
+
+// newPasswordFromRequest baut aus einer Erstellungsanfrage ein Password-Modell
+// Ohne Typangabe wird ein Login angelegt, damit ältere Clients unverändert funktionieren
+func newPasswordFromRequest(userID uint, req *schemas.CreatePasswordRequest) (*models.Password, error) {
+	itemType := req.Type
+	if itemType == "" {
+		itemType = models.ItemTypeLogin
+	}
+
+	now := time.Now()
+	password := &models.Password{
+		UserID:            userID,                // Verknüpfung zum Benutzer
+		Type:              itemType,              // Eintragstyp (Standard: login)
+		WebsiteURL:        req.WebsiteURL,        // Klartext-URL für Zuordnung (optional)
+		EncryptedURL:      req.EncryptedURL,      // AES-verschlüsselte URL (alternativ zur Klartext-URL)
+		URLIV:             req.URLIV,             // Initialisierungsvektor für URL
+		URLTag:            req.URLTag,            // Authentifizierungs-Tag für URL
+		DomainIndex:       req.DomainIndex,       // Blind-Index der Domain für die Zuordnung
+		EncryptedUsername: req.EncryptedUsername, // AES-verschlüsselter Benutzername
+		UsernameIV:        req.UsernameIV,        // Initialisierungsvektor für Username
+		UsernameTag:       req.UsernameTag,       // Authentifizierungs-Tag für Username
+		EncryptedPassword: req.EncryptedPassword, // AES-verschlüsseltes Passwort
+		PasswordIV:        req.PasswordIV,        // Initialisierungsvektor für Passwort
+		PasswordTag:       req.PasswordTag,       // Authentifizierungs-Tag für Passwort
+		Fingerprint:       req.Fingerprint,       // HMAC-Fingerabdruck des Passworts (optional)
+		EncryptedNotes:    req.EncryptedNotes,    // AES-verschlüsselte Notizen (optional)
+		NotesIV:           req.NotesIV,           // Initialisierungsvektor für Notizen
+		NotesTag:          req.NotesTag,          // Authentifizierungs-Tag für Notizen
+		EncryptedName:     req.EncryptedName,     // AES-verschlüsselter Anzeigename (optional)
+		NameIV:            req.NameIV,            // Initialisierungsvektor für Anzeigename
+		NameTag:           req.NameTag,           // Authentifizierungs-Tag für Anzeigename
+		EncryptedData:     req.EncryptedData,     // AES-verschlüsselter, typabhängiger Payload
+		DataIV:            req.DataIV,            // Initialisierungsvektor für Payload
+		DataTag:           req.DataTag,           // Authentifizierungs-Tag für Payload
+		DataVersion:       req.DataVersion,       // Version des Payload-Formats
+		PasswordChangedAt: &now,                  // Passwortalter beginnt mit der Erstellung
+		Revision:          1,                     // Erste Revision des Eintrags
+	}
+	password.DomainIndex = strings.ToLower(password.DomainIndex)
+	password.Fingerprint = strings.ToLower(password.Fingerprint)
+
+	if err := validateItemPayload(password); err != nil {
+		return nil, err
+	}
+
+	// Benutzerdefinierte Felder und URIs werden von GORM zusammen mit dem Eintrag angelegt
+	fields, err := newPasswordFields(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	password.Fields = fields
+
+	uris, err := newPasswordURIs(req.URIs)
+	if err != nil {
+		return nil, err
+	}
+	password.URIs = uris
+
+	return password, nil
+}
+
+// validateItemPayload prüft Typ, URL, Fingerabdruck und typabhängige Pflichtfelder eines Eintrags
+// Logins dürfen ohne Payload auskommen, alle anderen Typen benötigen einen vollständigen Payload
+func validateItemPayload(password *models.Password) error {
+	if !models.IsValidItemType(password.Type) {
+		return ErrInvalidItemType
+	}
+	if err := validateWebsiteURL(password); err != nil {
+		return err
+	}
+	if password.Fingerprint != "" && !isHexHMAC(password.Fingerprint) {
+		return fmt.Errorf("%w: fingerprint muss ein HMAC-SHA256 in Hex-Darstellung sein", ErrInvalidFingerprint)
+	}
+	if password.Type == models.ItemTypeLogin {
+		return nil
+	}
+	if password.EncryptedData == "" || password.DataIV == "" || password.DataTag == "" || password.DataVersion < 1 {
+		return ErrMissingItemPayload
+	}
+	return nil
+}
+
+// validateWebsiteURL prüft, dass die URL entweder im Klartext oder vollständig verschlüsselt
+// (mit IV und Tag) vorliegt und ein gesetzter Blind-Index das erwartete Format hat.
+func validateWebsiteURL(password *models.Password) error {
+	if password.EncryptedURL != "" {
+		if password.WebsiteURL != "" {
+			return fmt.Errorf("%w: website_url und encrypted_url schließen sich aus", ErrInvalidWebsiteURL)
+		}
+		if password.URLIV == "" || password.URLTag == "" {
+			return fmt.Errorf("%w: verschlüsselte URL mit IV und Tag erforderlich", ErrInvalidWebsiteURL)
+		}
+	}
+	if password.DomainIndex != "" && !isHexHMAC(password.DomainIndex) {
+		return fmt.Errorf("%w: domain_index muss ein HMAC-SHA256 in Hex-Darstellung sein", ErrInvalidWebsiteURL)
+	}
+	return nil
+}
+
+// isHexHMAC prüft, ob ein Wert ein HMAC-SHA256 in Hex-Darstellung (Kleinbuchstaben) ist.
+func isHexHMAC(value string) bool {
+	if len(value) != hmacHexLength {
+		return false
+	}
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newPasswordFields baut aus den angefragten Feldern die Modelle in Anfragereihenfolge
+// und prüft Feldtyp sowie die zum Typ gehörenden Pflichtangaben
+func newPasswordFields(reqs []schemas.CustomFieldRequest) ([]models.PasswordField, error) {
+	fields := make([]models.PasswordField, 0, len(reqs))
+	for i, f := range reqs {
+		fieldType := f.Type
+		if fieldType == "" {
+			fieldType = models.FieldTypeText
+		}
+
+		if f.EncryptedName == "" || f.NameIV == "" || f.NameTag == "" {
+			return nil, fmt.Errorf("%w %d: verschlüsselter Name mit IV und Tag erforderlich", ErrInvalidCustomField, i)
+		}
+
+		switch fieldType {
+		case models.FieldTypeText, models.FieldTypeHidden, models.FieldTypeBoolean:
+			// Wert ist optional, muss aber vollständig sein, wenn er gesetzt ist
+			if f.EncryptedValue != "" && (f.ValueIV == "" || f.ValueTag == "") {
+				return nil, fmt.Errorf("%w %d: IV und Tag für den Wert erforderlich", ErrInvalidCustomField, i)
+			}
+			if f.LinkedTo != "" {
+				return nil, fmt.Errorf("%w %d: linked_to ist nur für verknüpfte Felder erlaubt", ErrInvalidCustomField, i)
+			}
+		case models.FieldTypeLinked:
+			if f.LinkedTo != models.LinkedFieldUsername && f.LinkedTo != models.LinkedFieldPassword {
+				return nil, fmt.Errorf("%w %d: linked_to muss username oder password sein", ErrInvalidCustomField, i)
+			}
+			if f.EncryptedValue != "" {
+				return nil, fmt.Errorf("%w %d: verknüpfte Felder haben keinen eigenen Wert", ErrInvalidCustomField, i)
+			}
+		default:
+			return nil, fmt.Errorf("%w %d: unbekannter Feldtyp %q", ErrInvalidCustomField, i, fieldType)
+		}
+
+		fields = append(fields, models.PasswordField{
+			Position:       i,
+			Type:           fieldType,
+			EncryptedName:  f.EncryptedName,
+			NameIV:         f.NameIV,
+			NameTag:        f.NameTag,
+			EncryptedValue: f.EncryptedValue,
+			ValueIV:        f.ValueIV,
+			ValueTag:       f.ValueTag,
+			LinkedTo:       f.LinkedTo,
+		})
+	}
+	return fields, nil
+}
+
+// newPasswordURIs baut aus den angefragten URIs die Modelle in Anfragereihenfolge
+// Ohne Abgleichsregel gilt domain; reguläre Ausdrücke müssen kompilierbar sein
+func newPasswordURIs(reqs []schemas.URIRequest) ([]models.PasswordURI, error) {
+	if len(reqs) > maxURIsPerItem {
+		return nil, fmt.Errorf("%w: höchstens %d URIs pro Eintrag", ErrInvalidURI, maxURIsPerItem)
+	}
+
+	uris := make([]models.PasswordURI, 0, len(reqs))
+	for i, u := range reqs {
+		match := u.Match
+		if match == "" {
+			match = models.URIMatchDomain
+		}
+		if !models.IsValidURIMatch(match) {
+			return nil, fmt.Errorf("%w %d: unbekannte Abgleichsregel %q", ErrInvalidURI, i, match)
+		}
+
+		domainIndex := strings.ToLower(u.DomainIndex)
+		if domainIndex != "" && !isHexHMAC(domainIndex) {
+			return nil, fmt.Errorf("%w %d: domain_index muss ein HMAC-SHA256 in Hex-Darstellung sein", ErrInvalidURI, i)
+		}
+
+		uri := strings.TrimSpace(u.URI)
+		if u.EncryptedURI != "" {
+			// Verschlüsselte URIs kann der Server nicht prüfen, nur ihre Vollständigkeit
+			if uri != "" || u.URIIV == "" || u.URITag == "" {
+				return nil, fmt.Errorf("%w %d: verschlüsselte URI mit IV und Tag und ohne Klartext erforderlich", ErrInvalidURI, i)
+			}
+			uris = append(uris, models.PasswordURI{
+				Position:     i,
+				Match:        match,
+				EncryptedURI: u.EncryptedURI,
+				URIIV:        u.URIIV,
+				URITag:       u.URITag,
+				DomainIndex:  domainIndex,
+			})
+			continue
+		}
+		if uri == "" || len(uri) > maxURILength {
+			return nil, fmt.Errorf("%w %d: URI muss zwischen 1 und %d Zeichen lang sein", ErrInvalidURI, i, maxURILength)
+		}
+		if match == models.URIMatchRegex {
+			if _, err := regexp.Compile(uri); err != nil {
+				return nil, fmt.Errorf("%w %d: ungültiger regulärer Ausdruck: %v", ErrInvalidURI, i, err)
+			}
+		}
+
+		uris = append(uris, models.PasswordURI{
+			Position:    i,
+			URI:         uri,
+			Match:       match,
+			DomainIndex: domainIndex,
+		})
+	}
+	return uris, nil
+}
+
+// appendPasswordHistory legt ein ersetztes Passwort im Verlauf ab und entfernt
+// die ältesten Einträge, sobald mehr als passwordHistoryLimit Versionen vorhanden sind
+func appendPasswordHistory(tx *gorm.DB, entry *models.PasswordHistory) error {
+	if err := tx.Create(entry).Error; err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Passwort-Verlaufs: %w", err)
+	}
+
+	retained := tx.Model(&models.PasswordHistory{}).Select("id").
+		Where("password_id = ?", entry.PasswordID).Order("id DESC").Limit(passwordHistoryLimit)
+	if err := tx.Where("password_id = ? AND id NOT IN (?)", entry.PasswordID, retained).Delete(&models.PasswordHistory{}).Error; err != nil {
+		return fmt.Errorf("Fehler beim Kürzen des Passwort-Verlaufs: %w", err)
+	}
+	return nil
+}
+
+// orderFieldsByPosition sortiert vorgeladene benutzerdefinierte Felder bzw. URIs nach ihrer Position.
+func orderFieldsByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// preloadItemDetails lädt benutzerdefinierte Felder und URIs eines Eintrags in Anzeigereihenfolge.
+func preloadItemDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Fields", orderFieldsByPosition).Preload("URIs", orderFieldsByPosition)
+}
+
+// deleteUserVault entfernt alle Tresor-Daten eines Benutzers inklusive abhängiger Tabellen,
+// Papierkorb, Snapshots und Tombstones. Wird beim Löschen des Accounts innerhalb einer Transaktion aufgerufen
+func deleteUserVault(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.VaultSnapshot{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Tresor-Snapshots: %w", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.PasswordTombstone{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Tombstones: %w", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der gespeicherten Idempotenz-Antworten: %w", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.ImportJob{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Importe: %w", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserDomainGroup{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der eigenen Domain-Gruppen: %w", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.ExcludedDomainGroup{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Domain-Ausschlüsse: %w", err)
+	}
+	ownedPasswords := tx.Unscoped().Model(&models.Password{}).Select("id").Where("user_id = ?", userID)
+	return purgePasswords(tx, ownedPasswords)
+}
+
+// purgePasswords löscht Einträge endgültig (auch aus dem Papierkorb) samt Feldern, Verlauf,
+// Revisionen und Anhang-Metadaten. passwordIDs ist eine ID-Liste oder eine Unterabfrage.
+// Gibt die Speicherschlüssel der Anhänge zurück, die nach dem Commit entfernt werden müssen
+func purgePasswords(tx *gorm.DB, passwordIDs interface{}) ([]string, error) {
+	if err := tx.Where("password_id IN (?)", passwordIDs).Delete(&models.PasswordField{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der benutzerdefinierten Felder: %w", err)
+	}
+	if err := tx.Where("password_id IN (?)", passwordIDs).Delete(&models.PasswordURI{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der URIs: %w", err)
+	}
+	if err := tx.Where("password_id IN (?)", passwordIDs).Delete(&models.PasswordHistory{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen des Passwort-Verlaufs: %w", err)
+	}
+	if err := tx.Where("password_id IN (?)", passwordIDs).Delete(&models.PasswordRevision{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Revisionen: %w", err)
+	}
+	attachmentKeys, err := deleteAttachmentRows(tx, passwordIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("id IN (?)", passwordIDs).Delete(&models.Password{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Löschen der Passwörter: %w", err)
+	}
+	return attachmentKeys, nil
+}
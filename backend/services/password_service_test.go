@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/schemas"
+)
+
+func TestChunkIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []uint
+		size int
+		want [][]uint
+	}{
+		{name: "leer", ids: nil, size: 2, want: [][]uint{}},
+		{name: "ein Block", ids: []uint{1, 2}, size: 2, want: [][]uint{{1, 2}}},
+		{name: "letzter Block kürzer", ids: []uint{1, 2, 3, 4, 5}, size: 2, want: [][]uint{{1, 2}, {3, 4}, {5}}},
+		{name: "kleiner als Blockgröße", ids: []uint{7}, size: 1000, want: [][]uint{{7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkIDs(tt.ids, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkIDs(%v, %d) = %v, erwartet %v", tt.ids, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateItemPayloadFingerprint(t *testing.T) {
+	tests := []struct {
+		name        string
+		fingerprint string
+		wantErr     bool
+	}{
+		{name: "ohne Fingerabdruck"},
+		{name: "gültig", fingerprint: strings.Repeat("0a", 32)},
+		{name: "zu kurz", fingerprint: strings.Repeat("0a", 31), wantErr: true},
+		{name: "zu lang", fingerprint: strings.Repeat("0a", 33), wantErr: true},
+		{name: "Großbuchstaben", fingerprint: strings.Repeat("0A", 32), wantErr: true},
+		{name: "kein Hex", fingerprint: strings.Repeat("0g", 32), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateItemPayload(&models.Password{Type: models.ItemTypeLogin, Fingerprint: tt.fingerprint})
+			if tt.wantErr != errors.Is(err, ErrInvalidFingerprint) || (!tt.wantErr && err != nil) {
+				t.Errorf("validateItemPayload(%q) = %v, Fehler erwartet: %v", tt.fingerprint, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPasswordFromRequestNormalizesFingerprint(t *testing.T) {
+	// Clients dürfen den Fingerabdruck in Großbuchstaben senden, gespeichert wird Kleinschreibung
+	req := &schemas.CreatePasswordRequest{Fingerprint: strings.Repeat("AB", 32)}
+	password, err := newPasswordFromRequest(1, req)
+	if err != nil {
+		t.Fatalf("newPasswordFromRequest: %v", err)
+	}
+	if password.Fingerprint != strings.Repeat("ab", 32) {
+		t.Errorf("Fingerabdruck = %q, erwartet Kleinschreibung", password.Fingerprint)
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"backend/models"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Grenzen für die Seitengröße der Delta-Synchronisation
+const (
+	defaultSyncPageSize = 500
+	maxSyncPageSize     = 1000
+)
+
+// Fehler der Delta-Synchronisation
+var (
+	ErrInvalidSyncCursor  = NewError(ErrValidation, "invalid_sync_cursor", "Ungültiger Sync-Cursor")
+	ErrFullResyncRequired = NewError(ErrConflict, "full_resync_required", "Der Sync-Cursor ist älter als die aufbewahrten Löschungen, eine vollständige Synchronisation (since=0) ist erforderlich")
+)
+
+// SyncChanges enthält eine Seite der Änderungen am Tresor eines Benutzers seit einer Tresor-Revision.
+type SyncChanges struct {
+	Revision   int64                      // Tresor-Revision, bis zu der alle Änderungen geliefert wurden (Cursor für since)
+	Items      []models.Password          // Angelegte oder geänderte Einträge (inkl. Papierkorb)
+	Tombstones []models.PasswordTombstone // Endgültig gelöschte Einträge
+	HasMore    bool                       // Weitere Seiten vorhanden
+	NextCursor string                     // Cursor der nächsten Seite (nur bei HasMore)
+}
+
+// syncCursor ist die Position nach der zuletzt gelieferten Änderung. Änderungen sind nach
+// (sync_revision, Art, id) geordnet, innerhalb einer Revision kommen Einträge vor Tombstones.
+// Upper hält die Tresor-Revision der ersten Seite fest, damit alle Seiten denselben Stand liefern
+type syncCursor struct {
+	Full      bool  `json:"f"`  // Erstsynchronisation (ohne Tombstones)
+	Upper     int64 `json:"u"`  // Tresor-Revision zu Beginn der Synchronisation
+	Revision  int64 `json:"r"`  // Tresor-Revision der letzten gelieferten Änderung
+	Tombstone bool  `json:"t"`  // Letzte gelieferte Änderung war ein Tombstone
+	ID        uint  `json:"id"` // ID des Eintrags bzw. Tombstones (0 = Revision vollständig geliefert)
+}
+
+// completed liefert die Tresor-Revision, bis zu der alle Änderungen geliefert wurden.
+func (c syncCursor) completed() int64 {
+	if c.ID == 0 {
+		return c.Revision
+	}
+	return c.Revision - 1
+}
+
+// GetChangesSince liefert eine Seite der Änderungen nach der Tresor-Revision since bzw. nach der
+// Position eines Cursors der vorigen Seite. Mit since = 0 wird der komplette Tresor geliefert (Erstsynchronisation).
+// Alle Lesezugriffe sehen denselben Datenbankstand; Revisionen werden pro Benutzer unter Zeilensperre
+// vergeben und damit in Commit-Reihenfolge sichtbar, alles bis zur Obergrenze ist daher vollständig enthalten.
+// Liegt since vor bereits bereinigten Tombstones, wird ErrFullResyncRequired zurückgegeben.
+func (s *PasswordService) GetChangesSince(userID uint, since int64, cursor string, limit int) (*SyncChanges, error) {
+	if limit <= 0 {
+		limit = defaultSyncPageSize
+	}
+	limit = min(limit, maxSyncPageSize)
+
+	after := syncCursor{Full: since == 0, Revision: since, Tombstone: true}
+	if cursor != "" {
+		decoded, err := decodeSyncCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = *decoded
+	}
+
+	var items []models.Password
+	var tombstones []models.PasswordTombstone
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("vault_revision", "tombstone_horizon").First(&user, userID).Error; err != nil {
+			return fmt.Errorf("Fehler beim Lesen der Tresor-Revision für Benutzer %d: %w", userID, notFound(err, ErrUserNotFound))
+		}
+		if cursor == "" {
+			after.Upper = user.VaultRevision
+		}
+		if !after.Full && after.completed() < user.TombstoneHorizon {
+			return ErrFullResyncRequired
+		}
+
+		query := tx.Unscoped().Scopes(preloadItemDetails).
+			Where("user_id = ? AND sync_revision <= ?", userID, after.Upper)
+		if after.Tombstone {
+			query = query.Where("sync_revision > ?", after.Revision)
+		} else {
+			query = query.Where("(sync_revision, id) > (?, ?)", after.Revision, after.ID)
+		}
+		// Eine Zeile mehr laden, um zu erkennen, ob es eine weitere Seite gibt
+		if err := query.Order("sync_revision ASC, id ASC").Limit(limit + 1).Find(&items).Error; err != nil {
+			return fmt.Errorf("Fehler beim Abrufen der geänderten Einträge für Benutzer %d: %w", userID, err)
+		}
+
+		// Bei der Erstsynchronisation hat der Client keine Einträge, die entfernt werden müssten
+		if after.Full {
+			return nil
+		}
+		query = tx.Where("user_id = ? AND sync_revision <= ?", userID, after.Upper)
+		switch {
+		case !after.Tombstone:
+			query = query.Where("sync_revision >= ?", after.Revision)
+		case after.ID == 0:
+			query = query.Where("sync_revision > ?", after.Revision)
+		default:
+			query = query.Where("(sync_revision, id) > (?, ?)", after.Revision, after.ID)
+		}
+		if err := query.Order("sync_revision ASC, id ASC").Limit(limit + 1).Find(&tombstones).Error; err != nil {
+			return fmt.Errorf("Fehler beim Abrufen der gelöschten Einträge für Benutzer %d: %w", userID, err)
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	changes, last := mergeSyncChanges(items, tombstones, limit)
+	changes.Revision = after.Upper
+	if changes.HasMore {
+		last.Full, last.Upper = after.Full, after.Upper
+		changes.Revision = last.completed()
+		changes.NextCursor = encodeSyncCursor(last)
+	}
+	return changes, nil
+}
+
+// mergeSyncChanges führt die nach (sync_revision, id) sortierten Einträge und Tombstones zu einer Seite
+// mit höchstens limit Änderungen zusammen und liefert die Position der letzten übernommenen Änderung.
+func mergeSyncChanges(items []models.Password, tombstones []models.PasswordTombstone, limit int) (*SyncChanges, syncCursor) {
+	changes := &SyncChanges{}
+	var last syncCursor
+	i, j := 0, 0
+	for len(changes.Items)+len(changes.Tombstones) < limit && (i < len(items) || j < len(tombstones)) {
+		// Innerhalb einer Revision kommen Einträge vor Tombstones
+		if j == len(tombstones) || (i < len(items) && items[i].SyncRevision <= tombstones[j].SyncRevision) {
+			changes.Items = append(changes.Items, items[i])
+			last = syncCursor{Revision: items[i].SyncRevision, ID: items[i].ID}
+			i++
+		} else {
+			changes.Tombstones = append(changes.Tombstones, tombstones[j])
+			last = syncCursor{Revision: tombstones[j].SyncRevision, Tombstone: true, ID: tombstones[j].ID}
+			j++
+		}
+	}
+	changes.HasMore = i < len(items) || j < len(tombstones)
+	return changes, last
+}
+
+// encodeSyncCursor kodiert einen Sync-Cursor als URL-sicheren, für Clients undurchsichtigen String.
+func encodeSyncCursor(cursor syncCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeSyncCursor dekodiert einen mit encodeSyncCursor erzeugten Cursor und prüft seine Position.
+func decodeSyncCursor(value string) (*syncCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+	var cursor syncCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+	if cursor.ID == 0 || cursor.Revision <= 0 || cursor.Revision > cursor.Upper {
+		return nil, ErrInvalidSyncCursor
+	}
+	return &cursor, nil
+}
+
+// PurgeExpiredTombstones löscht Tombstones, die älter als die Aufbewahrungsdauer sind, und hält je Benutzer
+// die höchste bereinigte Tresor-Revision fest. Clients mit älterem Cursor müssen vollständig synchronisieren.
+func (s *PasswordService) PurgeExpiredTombstones() (int64, error) {
+	if s.Tombstones <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.Tombstones)
+	var purged int64
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE users SET tombstone_horizon = expired.revision
+			FROM (SELECT user_id, MAX(sync_revision) AS revision FROM password_tombstones WHERE created_at < ? GROUP BY user_id) AS expired
+			WHERE users.id = expired.user_id AND users.tombstone_horizon < expired.revision`, cutoff).Error; err != nil {
+			return fmt.Errorf("Fehler beim Speichern der bereinigten Tresor-Revisionen: %w", err)
+		}
+		result := tx.Where("created_at < ?", cutoff).Delete(&models.PasswordTombstone{})
+		if result.Error != nil {
+			return fmt.Errorf("Fehler beim Bereinigen abgelaufener Tombstones: %w", result.Error)
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// nextVaultRevision erhöht den Änderungszähler des Tresors und gibt den neuen Wert zurück.
+// Die Zeile des Benutzers bleibt bis zum Ende der Transaktion gesperrt, sodass Revisionen
+// in derselben Reihenfolge sichtbar werden, in der sie vergeben wurden
+func nextVaultRevision(tx *gorm.DB, userID uint) (int64, error) {
+	var revision int64
+	result := tx.Raw("UPDATE users SET vault_revision = vault_revision + 1 WHERE id = ? RETURNING vault_revision", userID).Scan(&revision)
+	if result.Error != nil {
+		return 0, fmt.Errorf("Fehler beim Erhöhen der Tresor-Revision für Benutzer %d: %w", userID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("Benutzer %d nicht gefunden: %w", userID, ErrUserNotFound.Wrap(gorm.ErrRecordNotFound))
+	}
+	return revision, nil
+}
+
+// recordTombstones legt für endgültig zu löschende Einträge Tombstones an, jeweils mit einer
+// neuen Tresor-Revision ihres Besitzers. Muss vor purgePasswords in derselben Transaktion laufen
+func recordTombstones(tx *gorm.DB, passwordIDs []uint) error {
+	if len(passwordIDs) == 0 {
+		return nil
+	}
+
+	var owners []models.Password
+	if err := tx.Unscoped().Select("id", "user_id").Where("id IN ?", passwordIDs).Order("user_id ASC, id ASC").Find(&owners).Error; err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der zu löschenden Einträge: %w", err)
+	}
+
+	revisions := make(map[uint]int64)
+	tombstones := make([]models.PasswordTombstone, 0, len(owners))
+	for _, owner := range owners {
+		revision, ok := revisions[owner.UserID]
+		if !ok {
+			var err error
+			if revision, err = nextVaultRevision(tx, owner.UserID); err != nil {
+				return err
+			}
+			revisions[owner.UserID] = revision
+		}
+		tombstones = append(tombstones, models.PasswordTombstone{
+			UserID:       owner.UserID,
+			PasswordID:   owner.ID,
+			SyncRevision: revision,
+		})
+	}
+
+	if len(tombstones) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(tombstones, 1000).Error; err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Tombstones: %w", err)
+	}
+	return nil
+}
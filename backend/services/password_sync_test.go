@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"backend/models"
+)
+
+func TestMergeSyncChanges(t *testing.T) {
+	item := func(revision int64, id uint) models.Password {
+		return models.Password{ID: id, SyncRevision: revision}
+	}
+	tombstone := func(revision int64, id uint) models.PasswordTombstone {
+		return models.PasswordTombstone{ID: id, SyncRevision: revision}
+	}
+	// Revision 3 enthält zwei Einträge und einen Tombstone, Revision 5 nur einen Tombstone
+	items := []models.Password{item(2, 10), item(3, 4), item(3, 7), item(6, 1)}
+	tombstones := []models.PasswordTombstone{tombstone(3, 2), tombstone(5, 3)}
+
+	tests := []struct {
+		name           string
+		limit          int
+		wantItems      []uint
+		wantTombstones []uint
+		wantHasMore    bool
+		wantLast       syncCursor
+		wantCompleted  int64
+	}{
+		{"alles", 10, []uint{10, 4, 7, 1}, []uint{2, 3}, false, syncCursor{Revision: 6, ID: 1}, 5},
+		{"genau alles", 6, []uint{10, 4, 7, 1}, []uint{2, 3}, false, syncCursor{Revision: 6, ID: 1}, 5},
+		{"mitten in Revision 3", 2, []uint{10, 4}, nil, true, syncCursor{Revision: 3, ID: 4}, 2},
+		{"Einträge vor Tombstones", 3, []uint{10, 4, 7}, nil, true, syncCursor{Revision: 3, ID: 7}, 2},
+		{"nach Tombstone", 4, []uint{10, 4, 7}, []uint{2}, true, syncCursor{Revision: 3, Tombstone: true, ID: 2}, 2},
+		{"nur Tombstone in Revision 5", 5, []uint{10, 4, 7}, []uint{2, 3}, true, syncCursor{Revision: 5, Tombstone: true, ID: 3}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, last := mergeSyncChanges(items, tombstones, tt.limit)
+			var gotItems, gotTombstones []uint
+			for _, item := range changes.Items {
+				gotItems = append(gotItems, item.ID)
+			}
+			for _, tombstone := range changes.Tombstones {
+				gotTombstones = append(gotTombstones, tombstone.ID)
+			}
+			if !reflect.DeepEqual(gotItems, tt.wantItems) || !reflect.DeepEqual(gotTombstones, tt.wantTombstones) {
+				t.Errorf("Einträge %v, Tombstones %v; erwartet %v, %v", gotItems, gotTombstones, tt.wantItems, tt.wantTombstones)
+			}
+			if changes.HasMore != tt.wantHasMore {
+				t.Errorf("HasMore = %v, erwartet %v", changes.HasMore, tt.wantHasMore)
+			}
+			if last != tt.wantLast {
+				t.Errorf("Position = %+v, erwartet %+v", last, tt.wantLast)
+			}
+			if got := last.completed(); got != tt.wantCompleted {
+				t.Errorf("completed() = %d, erwartet %d", got, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestSyncCursor(t *testing.T) {
+	cursor := syncCursor{Upper: 42, Revision: 17, Tombstone: true, ID: 9}
+	decoded, err := decodeSyncCursor(encodeSyncCursor(cursor))
+	if err != nil {
+		t.Fatalf("decodeSyncCursor: %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("decodeSyncCursor = %+v, erwartet %+v", *decoded, cursor)
+	}
+
+	for _, invalid := range []string{
+		"!!",
+		encodeSyncCursor(syncCursor{Upper: 42, Revision: 17}),        // Ohne ID
+		encodeSyncCursor(syncCursor{Upper: 42, Revision: 0, ID: 1}),  // Ohne Revision
+		encodeSyncCursor(syncCursor{Upper: 16, Revision: 17, ID: 1}), // Jenseits der Obergrenze
+	} {
+		if _, err := decodeSyncCursor(invalid); !errors.Is(err, ErrInvalidSyncCursor) {
+			t.Errorf("decodeSyncCursor(%q) Fehler = %v, erwartet ErrInvalidSyncCursor", invalid, err)
+		}
+	}
+}
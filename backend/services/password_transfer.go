@@ -0,0 +1,231 @@
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"backend/validation"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// Größen für Streaming-Import und -Export. Pro Block wird eine Transaktion geschrieben bzw. eine Seite gelesen,
+// sodass der Speicherbedarf unabhängig von der Größe des Tresors bleibt
+const (
+	transferChunkSize  = 1000
+	maxImportLineBytes = 1024 * 1024
+	maxImportErrors    = 1000
+)
+
+// ErrInvalidImportLine wird für Zeilen gemeldet, die kein gültiges JSON-Objekt enthalten.
+var ErrInvalidImportLine = NewError(ErrValidation, "invalid_import_line", "Zeile enthält kein gültiges JSON-Objekt")
+
+// ImportLineError beschreibt eine ungültige Zeile eines Streaming-Imports.
+type ImportLineError struct {
+	Line      int    // Zeilennummer (ab 1)
+	ClientRef string // Referenz des Clients, falls lesbar
+	Err       error  // Grund, warum die Zeile nicht importiert wurde
+}
+
+// ImportResult enthält den Endstand eines Streaming-Imports.
+type ImportResult struct {
+	Job             *models.ImportJob // Fortschritt und Zähler des Imports
+	Errors          []ImportLineError // Ungültige Zeilen (höchstens maxImportErrors)
+	ErrorsTruncated bool              // Ob weitere ungültige Zeilen nicht aufgeführt sind
+}
+
+// ImportPasswords liest Einträge zeilenweise als NDJSON (je Zeile ein Objekt im Format von
+// BatchCreatePasswordItem) und legt sie blockweise mit CreateInBatches an. Ungültige Zeilen werden
+// übersprungen und gemeldet. Jeder Block wird in einer eigenen Transaktion übernommen und der
+// Fortschritt im ImportJob festgehalten; bricht der Import ab, bleiben bereits übernommene Blöcke
+// erhalten und job.Processed gibt an, wie viele Einträge der Client nicht erneut senden muss.
+// Vor dem ersten Block wird ein Snapshot des Tresors angelegt.
+func (s *PasswordService) ImportPasswords(userID uint, r io.Reader, client ClientInfo) (*ImportResult, error) {
+	result := &ImportResult{Job: &models.ImportJob{UserID: userID, Status: models.ImportStatusRunning}}
+	job := result.Job
+	if err := s.DB.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Anlegen des Imports: %w", err)
+	}
+
+	if err := s.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := s.Snapshots.capture(tx, userID, models.SnapshotReasonBatchImport)
+		return err
+	}); err != nil {
+		return result, s.failImport(job, err)
+	}
+
+	chunk := make([]models.Password, 0, transferChunkSize)
+	pending := 0 // Gelesene, aber noch nicht übernommene Zeilen
+	flush := func() error {
+		if len(chunk) > 0 {
+			if err := s.importChunk(userID, chunk, client); err != nil {
+				return err
+			}
+		}
+		job.Processed += pending
+		job.Created += len(chunk)
+		chunk, pending = chunk[:0], 0
+		return s.DB.Model(job).Updates(map[string]interface{}{
+			"processed": job.Processed, "created": job.Created, "failed": job.Failed,
+		}).Error
+	}
+
+	err := scanImportLines(r, func(line int, data []byte) error {
+		pending++
+		password, clientRef, err := parseImportLine(userID, data)
+		if err != nil {
+			result.addError(line, clientRef, err)
+			return nil
+		}
+		chunk = append(chunk, *password)
+
+		if len(chunk) == transferChunkSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return result, s.failImport(job, err)
+	}
+
+	job.Status = models.ImportStatusCompleted
+	if err := s.DB.Model(job).Update("status", job.Status).Error; err != nil {
+		return result, fmt.Errorf("Fehler beim Abschließen des Imports: %w", err)
+	}
+	return result, nil
+}
+
+// scanImportLines ruft fn für jede nicht leere Zeile mit ihrer Nummer (ab 1) auf und bricht beim ersten
+// Fehler von fn ab. Eine Zeile über maxImportLineBytes beendet das Lesen mit ihrer Zeilennummer
+func scanImportLines(r io.Reader, fn func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := fn(line, data); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = fmt.Errorf("Zeile %d überschreitet %d Bytes: %w", line+1, maxImportLineBytes, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// parseImportLine dekodiert und prüft eine Zeile eines Streaming-Imports. Die Referenz des Clients
+// wird auch bei ungültigen Einträgen zurückgegeben, sofern die Zeile gültiges JSON enthält
+func parseImportLine(userID uint, data []byte) (*models.Password, string, error) {
+	var item schemas.BatchCreatePasswordItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImportLine, err)
+	}
+	if err := validation.Struct(&item.CreatePasswordRequest); err != nil {
+		return nil, item.ClientRef, err
+	}
+	password, err := newPasswordFromRequest(userID, &item.CreatePasswordRequest)
+	if err != nil {
+		return nil, item.ClientRef, err
+	}
+	return password, item.ClientRef, nil
+}
+
+// importChunk legt einen Block von Einträgen in einer Transaktion mit gemeinsamer Tresor-Revision an.
+func (s *PasswordService) importChunk(userID uint, passwords []models.Password, client ClientInfo) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+		for i := range passwords {
+			passwords[i].SyncRevision = revision
+		}
+
+		if err := tx.CreateInBatches(passwords, transferChunkSize).Error; err != nil {
+			return fmt.Errorf("Fehler beim Importieren der Passwörter: %w", err)
+		}
+		return s.recordRevisions(tx, passwords, models.RevisionActionCreate, client)
+	})
+}
+
+// failImport markiert einen Import als abgebrochen und gibt den auslösenden Fehler zurück.
+func (s *PasswordService) failImport(job *models.ImportJob, cause error) error {
+	job.Status = models.ImportStatusFailed
+	job.Error = truncate(cause.Error(), 512)
+	if err := s.DB.Model(job).Updates(map[string]interface{}{"status": job.Status, "error": job.Error}).Error; err != nil {
+		return fmt.Errorf("%w (Status des Imports konnte nicht gespeichert werden: %v)", cause, err)
+	}
+	return cause
+}
+
+// addError zählt eine ungültige Zeile und merkt sie sich, solange das Limit nicht erreicht ist.
+func (r *ImportResult) addError(line int, clientRef string, err error) {
+	r.Job.Failed++
+	if len(r.Errors) >= maxImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, ImportLineError{Line: line, ClientRef: clientRef, Err: err})
+}
+
+// GetImportJobs ruft die letzten Importe des Benutzers ab (neueste zuerst).
+func (s *PasswordService) GetImportJobs(userID uint, limit int) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	if err := s.DB.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Importe für Benutzer %d: %w", userID, err)
+	}
+	return jobs, nil
+}
+
+// GetImportJob ruft den Fortschritt eines Imports des Benutzers ab.
+func (s *PasswordService) GetImportJob(jobID, userID uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := s.DB.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("Import %d für Benutzer %d nicht gefunden: %w", jobID, userID, notFound(err, ErrImportNotFound))
+	}
+	return &job, nil
+}
+
+// CountPasswords zählt die aktiven Einträge (ohne Papierkorb) des Benutzers.
+func (s *PasswordService) CountPasswords(userID uint) (int64, error) {
+	var count int64
+	if err := s.DB.Model(&models.Password{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("Fehler beim Zählen der Passwörter für Benutzer %d: %w", userID, err)
+	}
+	return count, nil
+}
+
+// ExportPasswords liest die aktiven Einträge des Benutzers seitenweise in ID-Reihenfolge
+// und übergibt jede Seite an fn. Es wird nie mehr als eine Seite gleichzeitig geladen.
+func (s *PasswordService) ExportPasswords(userID uint, fn func([]models.Password) error) error {
+	var lastID uint
+	for {
+		var page []models.Password
+		if err := s.DB.Scopes(preloadItemDetails).Where("user_id = ? AND id > ?", userID, lastID).
+			Order("id ASC").Limit(transferChunkSize).Find(&page).Error; err != nil {
+			return fmt.Errorf("Fehler beim Exportieren der Passwörter für Benutzer %d: %w", userID, err)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		lastID = page[len(page)-1].ID
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/schemas"
+	"backend/validation"
+)
+
+func TestScanImportLines(t *testing.T) {
+	input := "{\"a\":1}\n\n  \t\n{\"b\":2}\r\n   {\"c\":3}   \n"
+	var lines []int
+	var data []string
+	err := scanImportLines(strings.NewReader(input), func(line int, value []byte) error {
+		lines = append(lines, line)
+		data = append(data, string(value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scanImportLines: %v", err)
+	}
+	// Leerzeilen zählen mit, werden aber nicht gemeldet
+	if want := []int{1, 4, 5}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Zeilen = %v, erwartet %v", lines, want)
+	}
+	if want := []string{`{"a":1}`, `{"b":2}`, `{"c":3}`}; !reflect.DeepEqual(data, want) {
+		t.Errorf("Inhalte = %q, erwartet %q", data, want)
+	}
+}
+
+func TestScanImportLinesStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := scanImportLines(strings.NewReader("1\n2\n3\n"), func(line int, value []byte) error {
+		calls++
+		if line == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Errorf("scanImportLines = %v nach %d Aufrufen, erwartet stop nach 2", err, calls)
+	}
+}
+
+func TestScanImportLinesTooLong(t *testing.T) {
+	input := "{}\n" + strings.Repeat("x", maxImportLineBytes+1) + "\n{}\n"
+	err := scanImportLines(strings.NewReader(input), func(int, []byte) error { return nil })
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), "Zeile 2 ") {
+		t.Errorf("scanImportLines Fehler = %v, erwartet bufio.ErrTooLong für Zeile 2", err)
+	}
+}
+
+func TestParseImportLine(t *testing.T) {
+	iv, ciphertext, tag := b64(0x01, schemas.GCMIVSize), b64(0x02, 5), b64(0x03, schemas.GCMTagSize)
+	login := fmt.Sprintf(`"encrypted_username":%q,"username_iv":%q,"username_tag":%q,"encrypted_password":%q,"password_iv":%q,"password_tag":%q`,
+		ciphertext, iv, tag, ciphertext, iv, tag)
+
+	tests := []struct {
+		name    string
+		line    string
+		wantRef string
+		wantErr error
+	}{
+		{"gültig", `{"client_ref":"a1",` + login + `}`, "a1", nil},
+		{"ohne Referenz", `{` + login + `}`, "", nil},
+		{"kein JSON", `client_ref=a1`, "", ErrInvalidImportLine},
+		{"abgeschnitten", `{"client_ref":"a1",`, "", ErrInvalidImportLine},
+		{"falscher Typ", `{"client_ref":"a1","encrypted_password":5}`, "", ErrInvalidImportLine},
+		{"Pflichtfeld fehlt", `{"client_ref":"a2","encrypted_username":"` + ciphertext + `"}`, "a2", validation.ErrInvalidRequest},
+		{"ungültiger Fingerabdruck", `{"client_ref":"a3",` + login + `,"fingerprint":"abc"}`, "a3", ErrInvalidFingerprint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, ref, err := parseImportLine(7, []byte(tt.line))
+			if ref != tt.wantRef {
+				t.Errorf("client_ref = %q, erwartet %q", ref, tt.wantRef)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || password != nil {
+					t.Errorf("parseImportLine = (%v, %v), erwartet Fehler %v", password, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportLine: %v", err)
+			}
+			if password.UserID != 7 || password.Type != models.ItemTypeLogin || password.EncryptedPassword != ciphertext {
+				t.Errorf("parseImportLine = %+v", password)
+			}
+		})
+	}
+}
+
+func TestImportResultAddError(t *testing.T) {
+	result := &ImportResult{Job: &models.ImportJob{}}
+	for line := 1; line <= maxImportErrors+2; line++ {
+		result.addError(line, "", ErrInvalidImportLine)
+	}
+	// Alle ungültigen Zeilen werden gezählt, aber nur bis zum Limit aufgeführt
+	if result.Job.Failed != maxImportErrors+2 || len(result.Errors) != maxImportErrors || !result.ErrorsTruncated {
+		t.Errorf("failed = %d, %d Fehler, truncated = %v", result.Job.Failed, len(result.Errors), result.ErrorsTruncated)
+	}
+	if first := result.Errors[0]; first.Line != 1 || !errors.Is(first.Err, ErrInvalidImportLine) {
+		t.Errorf("erster Fehler = %+v", first)
+	}
+}
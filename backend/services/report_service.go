@@ -0,0 +1,163 @@
+package services
+
+import (
+	"backend/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Standard- und Höchstwert für das Alter, ab dem ein Passwort als veraltet gilt
+const (
+	defaultMaxPasswordAgeDays = 365
+	maxPasswordAgeDays        = 10 * 365
+)
+
+// ErrInvalidReportQuery wird zurückgegeben, wenn die Parameter eines Berichts ungültig sind.
+var ErrInvalidReportQuery = NewError(ErrValidation, "invalid_report_query", "Ungültige Berichtsabfrage")
+
+// Altersklassen des Passwort-Altersberichts (Untergrenze in Tagen, letzte Klasse ohne Obergrenze)
+var passwordAgeBuckets = []AgeBucket{
+	{Label: "under_90_days", MinDays: 0},
+	{Label: "90_to_365_days", MinDays: 90},
+	{Label: "1_to_2_years", MinDays: 365},
+	{Label: "over_2_years", MinDays: 730},
+}
+
+// ReportService erstellt Berichte zum Zustand eines Tresors, ohne Klartext zu kennen.
+type ReportService struct {
+	DB *gorm.DB // Datenbankverbindung für Auswertungen
+}
+
+// NewReportService erstellt eine neue ReportService-Instanz.
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{DB: db}
+}
+
+// ReuseReport ist das Ergebnis der Suche nach wiederverwendeten Passwörtern.
+type ReuseReport struct {
+	Groups    [][]uint // IDs der Einträge mit gleichem Fingerabdruck (je Gruppe aufsteigend)
+	Checked   int64    // Anzahl der Logins mit Fingerabdruck
+	Unchecked int64    // Anzahl der Logins ohne Fingerabdruck (nicht prüfbar)
+}
+
+// AgeBucket ist eine Altersklasse des Passwort-Altersberichts.
+type AgeBucket struct {
+	Label   string // Bezeichnung der Klasse
+	MinDays int    // Untergrenze in Tagen (einschließlich)
+	Count   int    // Anzahl der Einträge in der Klasse
+}
+
+// StaleItem ist ein Eintrag, dessen Passwort älter als die erlaubte Höchstdauer ist.
+type StaleItem struct {
+	ID                uint       // ID des Eintrags
+	PasswordChangedAt *time.Time // Zeitpunkt der letzten Passwortänderung
+	AgeDays           int        // Alter des Passworts in Tagen
+}
+
+// AgeReport ist das Ergebnis des Passwort-Altersberichts.
+type AgeReport struct {
+	MaxAgeDays int         // Alter in Tagen, ab dem ein Passwort als veraltet gilt
+	Buckets    []AgeBucket // Verteilung der Logins auf die Altersklassen
+	Unknown    int         // Logins ohne Zeitpunkt der letzten Passwortänderung
+	Stale      []StaleItem // Veraltete Einträge, älteste zuerst
+}
+
+// GetReuseReport gruppiert die aktiven Einträge eines Benutzers nach ihrem Passwort-Fingerabdruck.
+// Der Fingerabdruck ist ein vom Client berechneter HMAC, der Server vergleicht nur Gleichheit.
+func (s *ReportService) GetReuseReport(userID uint) (*ReuseReport, error) {
+	report := &ReuseReport{Groups: [][]uint{}}
+
+	logins := s.DB.Model(&models.Password{}).Where("user_id = ? AND type = ?", userID, models.ItemTypeLogin)
+	if err := logins.Session(&gorm.Session{}).Where("fingerprint <> ''").Count(&report.Checked).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Zählen der Einträge für Benutzer %d: %w", userID, err)
+	}
+	if err := logins.Session(&gorm.Session{}).Where("fingerprint IS NULL OR fingerprint = ''").Count(&report.Unchecked).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Zählen der Einträge für Benutzer %d: %w", userID, err)
+	}
+
+	reused := s.DB.Model(&models.Password{}).Select("fingerprint").
+		Where("user_id = ? AND fingerprint <> ''", userID).
+		Group("fingerprint").Having("COUNT(*) > 1")
+	var rows []fingerprintRow
+	if err := s.DB.Model(&models.Password{}).Select("id, fingerprint").
+		Where("user_id = ? AND fingerprint IN (?)", userID, reused).
+		Order("fingerprint ASC, id ASC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen wiederverwendeter Passwörter für Benutzer %d: %w", userID, err)
+	}
+
+	report.Groups = groupByFingerprint(rows)
+	return report, nil
+}
+
+// fingerprintRow ist ein Eintrag mit seinem Passwort-Fingerabdruck.
+type fingerprintRow struct {
+	ID          uint
+	Fingerprint string
+}
+
+// groupByFingerprint fasst nach Fingerabdruck sortierte Zeilen zu Gruppen von IDs zusammen.
+func groupByFingerprint(rows []fingerprintRow) [][]uint {
+	groups := [][]uint{}
+	for i, row := range rows {
+		if i == 0 || rows[i-1].Fingerprint != row.Fingerprint {
+			groups = append(groups, []uint{})
+		}
+		last := len(groups) - 1
+		groups[last] = append(groups[last], row.ID)
+	}
+	return groups
+}
+
+// GetAgeReport ermittelt das Alter der Passwörter aller aktiven Logins eines Benutzers anhand
+// des Zeitpunkts der letzten Passwortänderung. maxAgeDays <= 0 verwendet den Standardwert.
+func (s *ReportService) GetAgeReport(userID uint, maxAgeDays int) (*AgeReport, error) {
+	if maxAgeDays <= 0 {
+		maxAgeDays = defaultMaxPasswordAgeDays
+	}
+	if maxAgeDays > maxPasswordAgeDays {
+		return nil, fmt.Errorf("%w: max_age_days darf höchstens %d sein", ErrInvalidReportQuery, maxPasswordAgeDays)
+	}
+
+	var rows []passwordChangeRow
+	if err := s.DB.Model(&models.Password{}).Select("id, password_changed_at").
+		Where("user_id = ? AND type = ?", userID, models.ItemTypeLogin).
+		Order("password_changed_at ASC, id ASC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Passwortänderungen für Benutzer %d: %w", userID, err)
+	}
+	return buildAgeReport(rows, maxAgeDays, time.Now()), nil
+}
+
+// passwordChangeRow ist ein Eintrag mit dem Zeitpunkt seiner letzten Passwortänderung.
+type passwordChangeRow struct {
+	ID                uint
+	PasswordChangedAt *time.Time
+}
+
+// buildAgeReport verteilt die nach Änderungszeitpunkt sortierten Zeilen auf die Altersklassen
+// und sammelt die Einträge, deren Passwort zum Zeitpunkt now mindestens maxAgeDays alt ist.
+func buildAgeReport(rows []passwordChangeRow, maxAgeDays int, now time.Time) *AgeReport {
+	report := &AgeReport{
+		MaxAgeDays: maxAgeDays,
+		Buckets:    append([]AgeBucket(nil), passwordAgeBuckets...),
+		Stale:      []StaleItem{},
+	}
+	for _, row := range rows {
+		if row.PasswordChangedAt == nil {
+			report.Unknown++
+			continue
+		}
+		ageDays := int(now.Sub(*row.PasswordChangedAt).Hours() / 24)
+		for i := len(report.Buckets) - 1; i >= 0; i-- {
+			if ageDays >= report.Buckets[i].MinDays {
+				report.Buckets[i].Count++
+				break
+			}
+		}
+		if ageDays >= maxAgeDays {
+			report.Stale = append(report.Stale, StaleItem{ID: row.ID, PasswordChangedAt: row.PasswordChangedAt, AgeDays: ageDays})
+		}
+	}
+	return report
+}
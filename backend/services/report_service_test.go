@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupByFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []fingerprintRow
+		want [][]uint
+	}{
+		{name: "keine Wiederverwendung", want: [][]uint{}},
+		{
+			name: "zwei Gruppen",
+			rows: []fingerprintRow{{1, "aa"}, {4, "aa"}, {2, "bb"}, {3, "bb"}, {5, "bb"}},
+			want: [][]uint{{1, 4}, {2, 3, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupByFingerprint(tt.rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupByFingerprint = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildAgeReport(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+	rows := []passwordChangeRow{
+		{ID: 1, PasswordChangedAt: daysAgo(800)},
+		{ID: 2, PasswordChangedAt: daysAgo(400)},
+		{ID: 3, PasswordChangedAt: daysAgo(365)},
+		{ID: 4, PasswordChangedAt: daysAgo(364)},
+		{ID: 5, PasswordChangedAt: daysAgo(90)},
+		{ID: 6, PasswordChangedAt: daysAgo(0)},
+		{ID: 7},
+	}
+
+	report := buildAgeReport(rows, 365, now)
+
+	counts := make([]int, len(report.Buckets))
+	for i, bucket := range report.Buckets {
+		counts[i] = bucket.Count
+	}
+	if want := []int{1, 2, 2, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("Altersklassen = %v, erwartet %v", counts, want)
+	}
+	if report.Unknown != 1 || report.MaxAgeDays != 365 {
+		t.Errorf("unknown = %d, max_age_days = %d, erwartet 1, 365", report.Unknown, report.MaxAgeDays)
+	}
+
+	// Die Grenze zählt bereits als veraltet, die Reihenfolge der Zeilen bleibt erhalten
+	var stale []uint
+	for _, item := range report.Stale {
+		stale = append(stale, item.ID)
+	}
+	if want := []uint{1, 2, 3}; !reflect.DeepEqual(stale, want) || report.Stale[0].AgeDays != 800 {
+		t.Errorf("veraltet = %v (%+v), erwartet %v", stale, report.Stale, want)
+	}
+
+	// Der Bericht darf die gemeinsamen Altersklassen nicht verändern
+	for _, bucket := range passwordAgeBuckets {
+		if bucket.Count != 0 {
+			t.Fatalf("passwordAgeBuckets verändert: %+v", passwordAgeBuckets)
+		}
+	}
+}
+
+func TestGetAgeReportRejectsMaxAge(t *testing.T) {
+	// Die Prüfung erfolgt vor der Datenbankabfrage
+	service := &ReportService{}
+	if _, err := service.GetAgeReport(1, maxPasswordAgeDays+1); !errors.Is(err, ErrInvalidReportQuery) {
+		t.Errorf("GetAgeReport = %v, erwartet ErrInvalidReportQuery", err)
+	}
+}
@@ -0,0 +1,380 @@
+// SnapshotService - Verwaltet Snapshots des kompletten Tresors eines Benutzers
+// Snapshots enthalten nur bereits verschlüsselte Daten und erlauben das atomare Zurücksetzen
+// des Tresors, z.B. nachdem ein fehlerhafter Client Einträge mit falschem Schlüssel gespeichert hat
+package services
+
+import (
+	"backend/models"
+	"backend/schemas"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Version des serialisierten Snapshot-Formats
+const vaultSnapshotVersion = 1
+
+// SnapshotService legt Tresor-Snapshots an und hält ihre Anzahl pro Benutzer begrenzt
+type SnapshotService struct {
+	DB    *gorm.DB // Datenbankverbindung für Snapshots
+	Limit int      // Maximale Anzahl aufbewahrter Snapshots pro Benutzer (0 = unbegrenzt)
+}
+
+// NewSnapshotService erstellt eine neue SnapshotService-Instanz.
+func NewSnapshotService(db *gorm.DB, limit int) *SnapshotService {
+	return &SnapshotService{DB: db, Limit: limit}
+}
+
+// vaultSnapshotData ist der serialisierte Inhalt eines Snapshots
+type vaultSnapshotData struct {
+	Version int                 `json:"version"`
+	Items   []vaultSnapshotItem `json:"items"`
+}
+
+// vaultSnapshotItem enthält einen Eintrag samt Metadaten und Passwort-Verlauf
+type vaultSnapshotItem struct {
+	ID                uint                     `json:"id"`
+	Revision          int                      `json:"revision"`
+	PasswordChangedAt *time.Time               `json:"password_changed_at"`
+	DeletedAt         *time.Time               `json:"deleted_at"`
+	CreatedAt         time.Time                `json:"created_at"`
+	Item              schemas.PasswordSnapshot `json:"item"`
+	History           []vaultSnapshotHistory   `json:"history"`
+}
+
+// vaultSnapshotHistory ist ein früheres Passwort eines Eintrags im Snapshot
+type vaultSnapshotHistory struct {
+	EncryptedPassword string     `json:"encrypted_password"`
+	PasswordIV        string     `json:"password_iv"`
+	PasswordTag       string     `json:"password_tag"`
+	ValidFrom         *time.Time `json:"valid_from"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// CreateSnapshot legt auf Anfrage des Benutzers einen Snapshot seines Tresors an.
+func (s *SnapshotService) CreateSnapshot(userID uint) (*models.VaultSnapshot, error) {
+	var snapshot *models.VaultSnapshot
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		snapshot, err = s.capture(tx, userID, models.SnapshotReasonManual)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetSnapshots ruft die Metadaten aller Snapshots eines Benutzers ab (neueste zuerst).
+func (s *SnapshotService) GetSnapshots(userID uint) ([]models.VaultSnapshot, error) {
+	var snapshots []models.VaultSnapshot
+	if err := s.DB.Omit("Data").Where("user_id = ?", userID).Order("id DESC").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Snapshots für Benutzer %d: %w", userID, err)
+	}
+	return snapshots, nil
+}
+
+// capture serialisiert alle Einträge des Benutzers (inklusive Papierkorb) innerhalb der Transaktion
+// und entfernt danach die ältesten Snapshots über dem Limit
+func (s *SnapshotService) capture(tx *gorm.DB, userID uint, reason string) (*models.VaultSnapshot, error) {
+	items, err := collectVaultItems(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := vaultSnapshotData{Version: vaultSnapshotVersion, Items: items}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Serialisieren des Tresors für Benutzer %d: %w", userID, err)
+	}
+
+	snapshot := &models.VaultSnapshot{
+		UserID:    userID,
+		Reason:    reason,
+		ItemCount: len(data.Items),
+		Data:      string(encoded),
+	}
+	if err := tx.Create(snapshot).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Speichern des Snapshots: %w", err)
+	}
+
+	if s.Limit > 0 {
+		retained := tx.Model(&models.VaultSnapshot{}).Select("id").
+			Where("user_id = ?", userID).Order("id DESC").Limit(s.Limit)
+		if err := tx.Where("user_id = ? AND id NOT IN (?)", userID, retained).Delete(&models.VaultSnapshot{}).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Entfernen alter Snapshots: %w", err)
+		}
+	}
+
+	// Inhalt wird vom Aufrufer nicht mehr benötigt
+	snapshot.Data = ""
+	return snapshot, nil
+}
+
+// collectVaultItems liest alle Einträge des Benutzers (inklusive Papierkorb) samt Feldern und Passwort-Verlauf.
+func collectVaultItems(tx *gorm.DB, userID uint) ([]vaultSnapshotItem, error) {
+	var passwords []models.Password
+	if err := tx.Unscoped().Scopes(preloadItemDetails).
+		Where("user_id = ?", userID).Order("id ASC").Find(&passwords).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen des Tresors für Benutzer %d: %w", userID, err)
+	}
+
+	var history []models.PasswordHistory
+	if err := tx.Where("user_id = ?", userID).Order("id ASC").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen des Passwort-Verlaufs für Benutzer %d: %w", userID, err)
+	}
+	historyByPassword := make(map[uint][]vaultSnapshotHistory)
+	for _, entry := range history {
+		historyByPassword[entry.PasswordID] = append(historyByPassword[entry.PasswordID], vaultSnapshotHistory{
+			EncryptedPassword: entry.EncryptedPassword,
+			PasswordIV:        entry.PasswordIV,
+			PasswordTag:       entry.PasswordTag,
+			ValidFrom:         entry.ValidFrom,
+			CreatedAt:         entry.CreatedAt,
+		})
+	}
+
+	items := make([]vaultSnapshotItem, 0, len(passwords))
+	for i := range passwords {
+		item := vaultSnapshotItem{
+			ID:                passwords[i].ID,
+			Revision:          passwords[i].Revision,
+			PasswordChangedAt: passwords[i].PasswordChangedAt,
+			CreatedAt:         passwords[i].CreatedAt,
+			Item:              snapshotOf(&passwords[i]),
+			History:           historyByPassword[passwords[i].ID],
+		}
+		if passwords[i].DeletedAt.Valid {
+			deletedAt := passwords[i].DeletedAt.Time
+			item.DeletedAt = &deletedAt
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// load liest und dekodiert einen Snapshot des Benutzers.
+func (s *SnapshotService) load(tx *gorm.DB, userID, snapshotID uint) (*vaultSnapshotData, error) {
+	var snapshot models.VaultSnapshot
+	if err := tx.Where("id = ? AND user_id = ?", snapshotID, userID).First(&snapshot).Error; err != nil {
+		return nil, fmt.Errorf("Snapshot %d für Benutzer %d nicht gefunden: %w", snapshotID, userID, notFound(err, ErrSnapshotNotFound))
+	}
+
+	var data vaultSnapshotData
+	if err := json.Unmarshal([]byte(snapshot.Data), &data); err != nil {
+		return nil, fmt.Errorf("Snapshot %d ist beschädigt: %w", snapshotID, err)
+	}
+	if data.Version != vaultSnapshotVersion {
+		return nil, fmt.Errorf("Snapshot %d hat unbekannte Formatversion %d", snapshotID, data.Version)
+	}
+	return &data, nil
+}
+
+// RollbackVault setzt den gesamten Tresor eines Benutzers atomar auf einen Snapshot zurück.
+// Vorher wird der aktuelle Stand selbst als Snapshot gesichert, damit das Zurücksetzen umkehrbar ist.
+// Einträge, die im Snapshot fehlen, werden endgültig gelöscht; alle anderen erhalten eine neue Revision.
+// Gibt die Anzahl der wiederhergestellten Einträge und den Sicherungs-Snapshot zurück.
+func (s *PasswordService) RollbackVault(userID, snapshotID uint, client ClientInfo) (int, *models.VaultSnapshot, error) {
+	var (
+		restored       []models.Password
+		backup         *models.VaultSnapshot
+		attachmentKeys []string
+	)
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// Benutzerzeile sperren, damit parallele Änderungen nicht mit dem Zurücksetzen kollidieren
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("Fehler beim Sperren des Benutzers %d: %w", userID, notFound(err, ErrUserNotFound))
+		}
+
+		data, err := s.Snapshots.load(tx, userID, snapshotID)
+		if err != nil {
+			return err
+		}
+
+		if backup, err = s.Snapshots.capture(tx, userID, models.SnapshotReasonRollback); err != nil {
+			return err
+		}
+
+		// Aktuelle Revisionsstände merken, damit Revisionsnummern fortlaufend bleiben
+		var current []models.Password
+		if err := tx.Unscoped().Select("id", "revision").Where("user_id = ?", userID).Find(&current).Error; err != nil {
+			return fmt.Errorf("Fehler beim Lesen des Tresors für Benutzer %d: %w", userID, err)
+		}
+		currentRevisions := make(map[uint]int, len(current))
+		for _, password := range current {
+			currentRevisions[password.ID] = password.Revision
+		}
+
+		// Einträge, die es zum Zeitpunkt des Snapshots nicht gab, endgültig entfernen
+		inSnapshot := make(map[uint]bool, len(data.Items))
+		for _, item := range data.Items {
+			inSnapshot[item.ID] = true
+		}
+		var obsolete []uint
+		for id := range currentRevisions {
+			if !inSnapshot[id] {
+				obsolete = append(obsolete, id)
+			}
+		}
+		if len(obsolete) > 0 {
+			if err := recordTombstones(tx, obsolete); err != nil {
+				return err
+			}
+			if attachmentKeys, err = purgePasswords(tx, obsolete); err != nil {
+				return err
+			}
+		}
+
+		// Alle zurückgeschriebenen Einträge gehören zur selben Tresor-Revision
+		revision, err := nextVaultRevision(tx, userID)
+		if err != nil {
+			return err
+		}
+		for i := range data.Items {
+			password, err := s.restoreSnapshotItem(tx, userID, &data.Items[i], currentRevisions, revision)
+			if err != nil {
+				return fmt.Errorf("Eintrag %d: %w", data.Items[i].ID, err)
+			}
+			restored = append(restored, *password)
+		}
+
+		return s.recordRevisions(tx, restored, models.RevisionActionRestore, client)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.Attachments.removeObjects(attachmentKeys)
+	return len(restored), backup, nil
+}
+
+// restoreSnapshotItem schreibt einen Eintrag aus einem Snapshot mit seiner ursprünglichen ID zurück
+// und ersetzt benutzerdefinierte Felder und Passwort-Verlauf. Gelöschte Einträge werden neu angelegt
+func (s *PasswordService) restoreSnapshotItem(tx *gorm.DB, userID uint, item *vaultSnapshotItem, currentRevisions map[uint]int, syncRevision int64) (*models.Password, error) {
+	password := passwordFromSnapshot(userID, item)
+	password.Revision = max(item.Revision, currentRevisions[item.ID]) + 1
+	password.SyncRevision = syncRevision
+
+	if err := tx.Where("password_id = ?", item.ID).Delete(&models.PasswordField{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Entfernen der benutzerdefinierten Felder: %w", err)
+	}
+	if err := tx.Where("password_id = ?", item.ID).Delete(&models.PasswordURI{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Entfernen der URIs: %w", err)
+	}
+	if err := tx.Where("password_id = ?", item.ID).Delete(&models.PasswordHistory{}).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Entfernen des Passwort-Verlaufs: %w", err)
+	}
+
+	// Save aktualisiert vorhandene Zeilen (auch im Papierkorb) und legt fehlende neu an.
+	// Der Zeitpunkt der letzten Verwendung ist kein Inhalt und bleibt erhalten
+	if err := tx.Unscoped().Omit("Fields", "URIs", "LastUsedAt").Save(password).Error; err != nil {
+		return nil, fmt.Errorf("Fehler beim Zurückschreiben des Eintrags: %w", err)
+	}
+
+	for i := range password.Fields {
+		password.Fields[i].PasswordID = password.ID
+	}
+	if len(password.Fields) > 0 {
+		if err := tx.Create(&password.Fields).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Speichern der benutzerdefinierten Felder: %w", err)
+		}
+	}
+	for i := range password.URIs {
+		password.URIs[i].PasswordID = password.ID
+	}
+	if len(password.URIs) > 0 {
+		if err := tx.Create(&password.URIs).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Speichern der URIs: %w", err)
+		}
+	}
+
+	if len(item.History) > 0 {
+		history := make([]models.PasswordHistory, 0, len(item.History))
+		for _, entry := range item.History {
+			history = append(history, models.PasswordHistory{
+				PasswordID:        password.ID,
+				UserID:            userID,
+				EncryptedPassword: entry.EncryptedPassword,
+				PasswordIV:        entry.PasswordIV,
+				PasswordTag:       entry.PasswordTag,
+				ValidFrom:         entry.ValidFrom,
+				CreatedAt:         entry.CreatedAt,
+			})
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return nil, fmt.Errorf("Fehler beim Speichern des Passwort-Verlaufs: %w", err)
+		}
+	}
+
+	return password, nil
+}
+
+// passwordFromSnapshot baut einen Eintrag samt Feldern und URIs so auf, wie er beim Anlegen des Snapshots
+// gespeichert war. Die Inhalte werden bewusst nicht erneut validiert: Sie waren damals gültig, und ein
+// Snapshot aus der Zeit vor einer Regeländerung muss sich trotzdem vollständig zurückspielen lassen
+func passwordFromSnapshot(userID uint, item *vaultSnapshotItem) *models.Password {
+	snapshot := &item.Item
+	password := &models.Password{
+		ID:                item.ID,
+		UserID:            userID,
+		Type:              snapshot.Type,
+		WebsiteURL:        snapshot.WebsiteURL,
+		EncryptedURL:      snapshot.EncryptedURL,
+		URLIV:             snapshot.URLIV,
+		URLTag:            snapshot.URLTag,
+		DomainIndex:       snapshot.DomainIndex,
+		EncryptedUsername: snapshot.EncryptedUsername,
+		UsernameIV:        snapshot.UsernameIV,
+		UsernameTag:       snapshot.UsernameTag,
+		EncryptedPassword: snapshot.EncryptedPassword,
+		PasswordIV:        snapshot.PasswordIV,
+		PasswordTag:       snapshot.PasswordTag,
+		Fingerprint:       snapshot.Fingerprint,
+		EncryptedNotes:    snapshot.EncryptedNotes,
+		NotesIV:           snapshot.NotesIV,
+		NotesTag:          snapshot.NotesTag,
+		EncryptedName:     snapshot.EncryptedName,
+		NameIV:            snapshot.NameIV,
+		NameTag:           snapshot.NameTag,
+		EncryptedData:     snapshot.EncryptedData,
+		DataIV:            snapshot.DataIV,
+		DataTag:           snapshot.DataTag,
+		DataVersion:       snapshot.DataVersion,
+		PasswordChangedAt: item.PasswordChangedAt,
+		Revision:          item.Revision,
+		CreatedAt:         item.CreatedAt,
+	}
+	if item.DeletedAt != nil {
+		password.DeletedAt = gorm.DeletedAt{Time: *item.DeletedAt, Valid: true}
+	}
+
+	for i, field := range snapshot.Fields {
+		password.Fields = append(password.Fields, models.PasswordField{
+			Position:       i,
+			Type:           field.Type,
+			EncryptedName:  field.EncryptedName,
+			NameIV:         field.NameIV,
+			NameTag:        field.NameTag,
+			EncryptedValue: field.EncryptedValue,
+			ValueIV:        field.ValueIV,
+			ValueTag:       field.ValueTag,
+			LinkedTo:       field.LinkedTo,
+		})
+	}
+	for i, uri := range snapshot.URIs {
+		password.URIs = append(password.URIs, models.PasswordURI{
+			Position:     i,
+			URI:          uri.URI,
+			Match:        uri.Match,
+			EncryptedURI: uri.EncryptedURI,
+			URIIV:        uri.URIIV,
+			URITag:       uri.URITag,
+			DomainIndex:  uri.DomainIndex,
+		})
+	}
+	return password
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/validation"
+
+	"gorm.io/gorm"
+)
+
+// legacyItems enthält je Eintragstyp einen gespeicherten Stand, dessen Chiffretext nicht mehr den
+// heutigen Formatregeln entspricht (kein Base64, IV und Tag zu kurz)
+func legacyItems() []models.Password {
+	changed := time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC)
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	var items []models.Password
+	for i, itemType := range []string{models.ItemTypeLogin, models.ItemTypeNote, models.ItemTypeCard, models.ItemTypeIdentity, models.ItemTypeAPIKey, models.ItemTypeSSHKey} {
+		item := models.Password{
+			ID:                uint(i + 1),
+			UserID:            7,
+			Type:              itemType,
+			EncryptedName:     "alt:name",
+			NameIV:            "iv",
+			NameTag:           "tag",
+			Revision:          i + 3,
+			PasswordChangedAt: &changed,
+			CreatedAt:         created,
+		}
+		if itemType == models.ItemTypeLogin {
+			item.WebsiteURL = "https://example.com"
+			item.EncryptedUsername, item.UsernameIV, item.UsernameTag = "alt:user", "iv", "tag"
+			item.EncryptedPassword, item.PasswordIV, item.PasswordTag = "alt:secret", "iv", "tag"
+			item.Fields = []models.PasswordField{
+				{Position: 0, Type: models.FieldTypeHidden, EncryptedName: "alt:pin", NameIV: "iv", NameTag: "tag", EncryptedValue: "alt:1234", ValueIV: "iv", ValueTag: "tag"},
+				{Position: 1, Type: models.FieldTypeLinked, EncryptedName: "alt:login", NameIV: "iv", NameTag: "tag", LinkedTo: models.LinkedFieldUsername},
+			}
+			item.URIs = []models.PasswordURI{
+				{Position: 0, URI: "example.com", Match: models.URIMatchDomain},
+				{Position: 1, Match: models.URIMatchHost, EncryptedURI: "alt:uri", URIIV: "iv", URITag: "tag"},
+			}
+		} else {
+			item.EncryptedData, item.DataIV, item.DataTag, item.DataVersion = "alt:data", "iv", "tag", 1
+		}
+		if itemType == models.ItemTypeNote {
+			item.DeletedAt = gorm.DeletedAt{Time: created.Add(time.Hour), Valid: true}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestPasswordFromSnapshotRestoresStoredItems(t *testing.T) {
+	for _, stored := range legacyItems() {
+		t.Run(stored.Type, func(t *testing.T) {
+			item := vaultSnapshotItem{
+				ID:                stored.ID,
+				Revision:          stored.Revision,
+				PasswordChangedAt: stored.PasswordChangedAt,
+				CreatedAt:         stored.CreatedAt,
+				Item:              snapshotOf(&stored),
+			}
+			if stored.DeletedAt.Valid {
+				item.DeletedAt = &stored.DeletedAt.Time
+			}
+
+			// Die heutigen Regeln lehnen den Stand ab, das Zurückspielen darf daran nicht scheitern
+			if err := validation.Struct(&item.Item); err == nil {
+				t.Fatalf("Testdaten werden von den aktuellen Regeln akzeptiert")
+			}
+
+			encoded, err := json.Marshal(vaultSnapshotData{Version: vaultSnapshotVersion, Items: []vaultSnapshotItem{item}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var data vaultSnapshotData
+			if err := json.Unmarshal(encoded, &data); err != nil {
+				t.Fatal(err)
+			}
+
+			got := passwordFromSnapshot(stored.UserID, &data.Items[0])
+			if !reflect.DeepEqual(*got, stored) {
+				t.Errorf("passwordFromSnapshot = %+v, erwartet %+v", *got, stored)
+			}
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package services
 
 import (
+	"backend/models"
 	"encoding/base64"
-	"errors"
 	"fmt"
 
 	"github.com/pquerna/otp/totp"
@@ -16,6 +16,12 @@ type TwoFAService struct {
 	UserService *UserService // Abhängigkeit zum Benutzerdienst
 }
 
+// Fehler bei Einrichtung und Prüfung der Zwei-Faktor-Authentifizierung
+var (
+	ErrTwoFANotInitiated = NewError(ErrValidation, "two_fa_not_initiated", "2FA-Einrichtung wurde nicht gestartet")
+	ErrInvalidTwoFACode  = NewError(ErrUnauthorized, "invalid_two_fa_code", "Ungültiger 2FA-Code")
+)
+
 // NewTwoFAService erstellt eine neue TwoFAService-Instanz.
 func NewTwoFAService(db *gorm.DB, userService *UserService) *TwoFAService {
 	return &TwoFAService{DB: db, UserService: userService}
@@ -24,9 +30,9 @@ func NewTwoFAService(db *gorm.DB, userService *UserService) *TwoFAService {
 // GenerateTwoFASecret generiert ein neues TOTP-Geheimnis und gibt das Geheimnis und den QR-Code als Base64-Bild zurück.
 func (s *TwoFAService) GenerateTwoFASecret(userID uint) (secret string, qrCodeBase64 string, err error) {
 	// Benutzer anhand der ID abrufen
-	user, err := s.UserService.GetUserByID(userID)
+	user, err := s.user(userID)
 	if err != nil {
-		return "", "", fmt.Errorf("Benutzer nicht gefunden: %w", err)
+		return "", "", err
 	}
 
 	// Neues TOTP-Schlüssel generieren
@@ -63,14 +69,14 @@ func (s *TwoFAService) GenerateTwoFASecret(userID uint) (secret string, qrCodeBa
 // VerifyTwoFACode verifiziert den bereitgestellten TOTP-Code anhand des gespeicherten Geheimnisses des Benutzers.
 func (s *TwoFAService) VerifyTwoFACode(userID uint, code string) (bool, error) {
 	// Benutzer anhand der ID abrufen
-	user, err := s.UserService.GetUserByID(userID)
+	user, err := s.user(userID)
 	if err != nil {
-		return false, fmt.Errorf("Benutzer nicht gefunden: %w", err)
+		return false, err
 	}
 
 	// Prüfen, ob das Geheimnis fehlt (während Setup kann 2FA noch nicht aktiviert sein)
 	if user.TwoFASecret == "" {
-		return false, errors.New("2FA-Geheimnis nicht gefunden")
+		return false, ErrTwoFANotInitiated
 	}
 
 	// TOTP-Code verifizieren
@@ -82,9 +88,9 @@ func (s *TwoFAService) VerifyTwoFACode(userID uint, code string) (bool, error) {
 // EnableTwoFA aktiviert die Zwei-Faktor-Authentifizierung für den Benutzer.
 func (s *TwoFAService) EnableTwoFA(userID uint) error {
 	// Benutzer anhand der ID abrufen
-	user, err := s.UserService.GetUserByID(userID)
+	user, err := s.user(userID)
 	if err != nil {
-		return fmt.Errorf("Benutzer nicht gefunden: %w", err)
+		return err
 	}
 
 	user.TwoFAEnabled = true // 2FA-Flag auf true setzen
@@ -95,12 +101,40 @@ func (s *TwoFAService) EnableTwoFA(userID uint) error {
 // DisableTwoFA deaktiviert die Zwei-Faktor-Authentifizierung für den Benutzer.
 func (s *TwoFAService) DisableTwoFA(userID uint) error {
 	// Benutzer anhand der ID abrufen
-	user, err := s.UserService.GetUserByID(userID)
+	user, err := s.user(userID)
 	if err != nil {
-		return fmt.Errorf("Benutzer nicht gefunden: %w", err)
+		return err
 	}
 
 	user.TwoFAEnabled = false // 2FA-Flag auf false setzen
 	user.TwoFASecret = ""     // Geheimnis löschen
 	return s.DB.Save(user).Error
 }
+
+// VerifyLoginCode prüft den TOTP-Code bei der Anmeldung und liefert den Benutzer.
+// Unbekannte Benutzer, Benutzer ohne aktive 2FA und falsche Codes sind nicht unterscheidbar (ErrInvalidTwoFACode).
+func (s *TwoFAService) VerifyLoginCode(username, code string) (*models.User, error) {
+	user, err := s.UserService.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Benutzers: %w", err)
+	}
+	if user == nil || !user.TwoFAEnabled || user.TwoFASecret == "" {
+		return nil, ErrInvalidTwoFACode
+	}
+	if !totp.Validate(code, user.TwoFASecret) {
+		return nil, ErrInvalidTwoFACode
+	}
+	return user, nil
+}
+
+// user lädt einen Benutzer und meldet ErrUserNotFound, wenn er nicht existiert.
+func (s *TwoFAService) user(userID uint) (*models.User, error) {
+	user, err := s.UserService.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Benutzers: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
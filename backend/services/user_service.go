@@ -8,19 +8,46 @@ import (
 	"gorm.io/gorm"
 )
 
+// MaxTrashRetentionDays is the longest trash retention period a user can configure.
+const MaxTrashRetentionDays = 3650
+
+// Names of the unique indexes GORM creates for models.User (idx_<table>_<column>).
+const (
+	usernameUniqueIndex = "idx_users_username"
+	emailUniqueIndex    = "idx_users_email"
+)
+
+// ErrInvalidTrashRetention is returned when the requested trash retention period is out of range.
+var ErrInvalidTrashRetention = NewError(ErrValidation, "invalid_trash_retention", "Aufbewahrungsdauer des Papierkorbs muss zwischen 0 und 3650 Tagen liegen")
+
 // UserService handles user-related database operations.
 type UserService struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	Attachments *AttachmentService // Used to remove attachment objects when an account is deleted
 }
 
 // NewUserService creates a new UserService instance.
-func NewUserService(db *gorm.DB) *UserService {
-	return &UserService{DB: db}
+func NewUserService(db *gorm.DB, attachmentService *AttachmentService) *UserService {
+	return &UserService{DB: db, Attachments: attachmentService}
 }
 
 // CreateUser creates a new user in the database.
+// A concurrent registration with the same username or email address yields ErrUsernameTaken or ErrEmailTaken.
 func (s *UserService) CreateUser(user *models.User) error {
-	return s.DB.Create(user).Error
+	return userConflict(s.DB.Create(user).Error)
+}
+
+// userConflict maps a unique violation on the username or email index to ErrUsernameTaken or
+// ErrEmailTaken. The pre-checks in RegisterUser and UpdateUserProfile cannot rule out a concurrent
+// request taking the same value, so the database constraint has the final say.
+func userConflict(err error) error {
+	switch uniqueViolation(err) {
+	case usernameUniqueIndex:
+		return ErrUsernameTaken.Wrap(err)
+	case emailUniqueIndex:
+		return ErrEmailTaken.Wrap(err)
+	}
+	return err
 }
 
 // GetUserByUsername retrieves a user by their username.
@@ -79,37 +106,78 @@ func (s *UserService) GetUserByID(userID uint) (*models.User, error) {
 func (s *UserService) UpdateUserProfile(userID uint, req *schemas.UpdateProfileRequest) (*models.User, error) {
 	var user models.User
 	if err := s.DB.First(&user, userID).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("user not found: %w", notFound(err, ErrUserNotFound))
 	}
 
-	if req.Username != nil {
+	// Usernames and email addresses must stay unique
+	if req.Username != nil && *req.Username != user.Username {
+		existing, err := s.GetUserByUsername(*req.Username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check username: %w", err)
+		}
+		if existing != nil {
+			return nil, ErrUsernameTaken
+		}
 		user.Username = *req.Username
 	}
-	if req.Email != nil {
+	if req.Email != nil && *req.Email != user.Email {
+		existing, err := s.GetUserByEmail(*req.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check email address: %w", err)
+		}
+		if existing != nil {
+			return nil, ErrEmailTaken
+		}
 		user.Email = *req.Email
 	}
+	if req.TrashRetentionDays != nil {
+		if !validTrashRetention(*req.TrashRetentionDays) {
+			return nil, ErrInvalidTrashRetention
+		}
+		user.TrashRetentionDays = *req.TrashRetentionDays
+	}
+	if req.Locale != nil {
+		user.Locale = *req.Locale
+	}
 
 	// Handle password update separately if needed, as it involves hashing
 	// For now, assuming password changes are handled by a dedicated auth service
 
-	if err := s.DB.Save(&user).Error; err != nil {
+	if err := userConflict(s.DB.Save(&user).Error); err != nil {
 		return nil, fmt.Errorf("failed to update user profile: %w", err)
 	}
 
 	return &user, nil
 }
 
+// validTrashRetention reports whether days is an allowed trash retention period (0 disables the purge).
+func validTrashRetention(days int) bool {
+	return days >= 0 && days <= MaxTrashRetentionDays
+}
+
 // DeleteUserAccount deletes a user's account from the database.
 func (s *UserService) DeleteUserAccount(userID uint) error {
-	// Delete associated passwords first to avoid foreign key constraint issues
-	if err := s.DB.Where("user_id = ?", userID).Delete(&models.Password{}).Error; err != nil {
-		return fmt.Errorf("failed to delete user's passwords: %w", err)
-	}
+	var attachmentKeys []string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// Delete associated vault data first to avoid foreign key constraint issues
+		keys, err := deleteUserVault(tx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user's vault: %w", err)
+		}
+		attachmentKeys = keys
+
+		// Then delete the user
+		if err := tx.Delete(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("failed to delete user account: %w", err)
+		}
 
-	// Then delete the user
-	if err := s.DB.Delete(&models.User{}, userID).Error; err != nil {
-		return fmt.Errorf("failed to delete user account: %w", err)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	// Attachment objects can only be removed once the metadata is gone for good
+	s.Attachments.removeObjects(attachmentKeys)
 	return nil
 }
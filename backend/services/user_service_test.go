@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestUserConflict(t *testing.T) {
+	other := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no error", nil, nil},
+		{"username index", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: usernameUniqueIndex}, ErrUsernameTaken},
+		{"email index", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: emailUniqueIndex}, ErrEmailTaken},
+		{"wrapped email index", fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: emailUniqueIndex}), ErrEmailTaken},
+		{"other unique index", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "idx_users_other"}, nil},
+		{"other SQLSTATE", &pgconn.PgError{Code: "23502", ConstraintName: usernameUniqueIndex}, nil},
+		{"other error", other, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := userConflict(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Errorf("userConflict(%v) = %v, want the unchanged error", tt.err, got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) || !errors.Is(got, ErrConflict) {
+				t.Errorf("userConflict(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+			var pgErr *pgconn.PgError
+			if !errors.As(got, &pgErr) {
+				t.Errorf("userConflict(%v) dropped the database error", tt.err)
+			}
+		})
+	}
+}
+
+func TestValidTrashRetention(t *testing.T) {
+	tests := []struct {
+		days int
+		want bool
+	}{
+		{0, true}, // never purge automatically
+		{1, true},
+		{30, true},
+		{MaxTrashRetentionDays, true},
+		{MaxTrashRetentionDays + 1, false},
+		{-1, false},
+	}
+	for _, tt := range tests {
+		if got := validTrashRetention(tt.days); got != tt.want {
+			t.Errorf("validTrashRetention(%d) = %v, want %v", tt.days, got, tt.want)
+		}
+	}
+}
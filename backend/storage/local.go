@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage speichert Anhänge als Dateien unterhalb eines Basisverzeichnisses.
+type LocalStorage struct {
+	Dir string // Basisverzeichnis für alle Objekte
+}
+
+// NewLocalStorage erstellt eine neue LocalStorage-Instanz und legt das Basisverzeichnis an.
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("Speicherverzeichnis %s konnte nicht angelegt werden: %w", dir, err)
+	}
+	return &LocalStorage{Dir: dir}, nil
+}
+
+// Put schreibt das Objekt zunächst in eine temporäre Datei und benennt sie danach atomar um,
+// damit abgebrochene Uploads keine halben Dateien hinterlassen.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("Verzeichnis für %s konnte nicht angelegt werden: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("temporäre Datei konnte nicht angelegt werden: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Nach erfolgreichem Umbenennen ein No-op
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size))
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("Fehler beim Schreiben von %s: %w", key, err)
+	}
+	if written != size {
+		tmp.Close()
+		return fmt.Errorf("unvollständiger Upload für %s: %d von %d Bytes", key, written, size)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Fehler beim Synchronisieren von %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Fehler beim Schließen von %s: %w", key, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get öffnet die Datei des Objekts zum Lesen.
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete entfernt die Datei des Objekts.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Fehler beim Löschen von %s: %w", key, err)
+	}
+	return nil
+}
+
+// path bildet den Schlüssel auf einen Pfad im Basisverzeichnis ab und verhindert Path-Traversal.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("ungültiger Speicherschlüssel %q", key)
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
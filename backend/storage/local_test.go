@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStorage(filepath.Join(t.TempDir(), "anhaenge"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	if err := store.Put(ctx, "1/2/abc", strings.NewReader("chiffretext"), 11); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := store.Get(ctx, "1/2/abc")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "chiffretext" {
+		t.Errorf("Get = %q, %v, erwartet \"chiffretext\"", data, err)
+	}
+
+	if err := store.Delete(ctx, "1/2/abc"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "1/2/abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get nach Delete = %v, erwartet ErrNotFound", err)
+	}
+	// Fehlende Objekte gelten beim Löschen nicht als Fehler
+	if err := store.Delete(ctx, "1/2/abc"); err != nil {
+		t.Errorf("erneutes Delete = %v, erwartet nil", err)
+	}
+}
+
+func TestLocalStoragePutIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	store := &LocalStorage{Dir: dir}
+
+	if err := store.Put(context.Background(), "1/2/abc", strings.NewReader("kurz"), 10); err == nil {
+		t.Fatal("Put mit zu wenig Daten erwartet Fehler")
+	}
+	// Weder das Objekt noch die temporäre Datei dürfen zurückbleiben
+	entries, err := os.ReadDir(filepath.Join(dir, "1", "2"))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Verzeichnis enthält %d Einträge, erwartet keine", len(entries))
+	}
+}
+
+func TestLocalStoragePutCanceled(t *testing.T) {
+	store := &LocalStorage{Dir: t.TempDir()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := store.Put(ctx, "1/2/abc", strings.NewReader("daten"), 5); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Put = %v, erwartet context.Canceled", err)
+	}
+	if _, err := store.Get(context.Background(), "1/2/abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get = %v, erwartet ErrNotFound", err)
+	}
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	store := &LocalStorage{Dir: "/daten"}
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "1/2/abc", want: "/daten/1/2/abc"},
+		{key: "1/../2/abc", want: "/daten/2/abc"},
+		{key: "", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../geheim", wantErr: true},
+		{key: "1/../../geheim", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := store.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("path(%q) = %q, erwartet Fehler", tt.key, got)
+				}
+				return
+			}
+			if err != nil || got != filepath.FromSlash(tt.want) {
+				t.Errorf("path(%q) = %q, %v, erwartet %q", tt.key, got, err, tt.want)
+			}
+		})
+	}
+}
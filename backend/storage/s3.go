@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config enthält die Verbindungsdaten für einen S3-kompatiblen Object Store.
+type S3Config struct {
+	Endpoint  string // Host und Port, z.B. "localhost:9000" für eine lokale MinIO-Instanz
+	AccessKey string // Access-Key-ID
+	SecretKey string // Secret-Access-Key
+	Bucket    string // Bucket für alle Anhänge
+	Region    string // Region (optional, bei MinIO meist leer)
+	UseSSL    bool   // TLS für die Verbindung verwenden
+}
+
+// S3Storage speichert Anhänge als Objekte in einem S3-kompatiblen Bucket.
+type S3Storage struct {
+	Client *minio.Client // Client für den Object Store
+	Bucket string        // Ziel-Bucket
+}
+
+// NewS3Storage erstellt eine neue S3Storage-Instanz und legt den Bucket an, falls er fehlt.
+func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("S3-Client konnte nicht erstellt werden: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("Bucket %s konnte nicht geprüft werden: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("Bucket %s konnte nicht angelegt werden: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &S3Storage{Client: client, Bucket: cfg.Bucket}, nil
+}
+
+// Put lädt das Objekt mit bekannter Größe in den Bucket hoch.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		return fmt.Errorf("Fehler beim Hochladen von %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get öffnet das Objekt zum Lesen. Der Stat-Aufruf stellt sicher, dass fehlende
+// Objekte sofort als ErrNotFound erkannt werden und nicht erst beim ersten Read.
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen von %s: %w", key, err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("Fehler beim Abrufen von %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+// Delete entfernt das Objekt aus dem Bucket.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("Fehler beim Löschen von %s: %w", key, err)
+	}
+	return nil
+}
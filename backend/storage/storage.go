@@ -0,0 +1,24 @@
+// Speicher-Abstraktion für verschlüsselte Dateianhänge
+// Der Server speichert ausschließlich Chiffretext, den Clients hochladen
+// Treiber: lokales Dateisystem und S3-kompatible Object Stores (z.B. MinIO)
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound wird zurückgegeben, wenn ein Objekt im Speicher nicht existiert.
+var ErrNotFound = errors.New("objekt nicht gefunden")
+
+// Storage definiert die Operationen, die ein Speicher-Treiber für Anhänge bereitstellen muss.
+// Schlüssel sind relative, mit "/" getrennte Pfade, die vom Service vergeben werden.
+type Storage interface {
+	// Put speichert genau size Bytes aus r unter dem angegebenen Schlüssel
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get öffnet das Objekt zum Lesen; der Aufrufer muss den Reader schließen
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete entfernt das Objekt; fehlende Objekte gelten nicht als Fehler
+	Delete(ctx context.Context, key string) error
+}
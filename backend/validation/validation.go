@@ -0,0 +1,353 @@
+// Validierung von Anfragekörpern
+// Dekodiert JSON streng (unbekannte Felder werden abgelehnt) und prüft die validate-Tags der
+// schemas-Strukturen mit go-playground/validator; Fehler werden feldgenau als schemas.FieldError gemeldet
+package validation
+
+import (
+	"backend/i18n"
+	"backend/models"
+	"backend/schemas"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalidRequest ist der gemeinsame Fehler aller Validierungsfehler (errors.Is).
+var ErrInvalidRequest = errors.New("Ungültige Anfrage")
+
+// Error enthält alle feldbezogenen Fehler einer Anfrage.
+// Die Meldungen in Fields sind in der Standardsprache, Localized übersetzt sie.
+type Error struct {
+	Fields   []schemas.FieldError
+	messages []i18n.Message // Unübersetzte Meldungen, parallel zu Fields
+}
+
+// add ergänzt einen Feldfehler.
+func (e *Error) add(field, rule, param string, message i18n.Message) *Error {
+	e.Fields = append(e.Fields, schemas.FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: message.In(i18n.Default),
+	})
+	e.messages = append(e.messages, message)
+	return e
+}
+
+// Localized liefert die Feldfehler mit Meldungen in der Sprache locale.
+func (e *Error) Localized(locale string) []schemas.FieldError {
+	fields := make([]schemas.FieldError, len(e.Fields))
+	copy(fields, e.Fields)
+	for i := range fields {
+		if i < len(e.messages) {
+			fields[i].Message = e.messages[i].In(locale)
+		}
+	}
+	return fields
+}
+
+// Error fasst die Feldfehler zu einer Meldung zusammen.
+func (e *Error) Error() string {
+	return e.In(i18n.Default)
+}
+
+// In fasst die Feldfehler in der Sprache locale zu einer Meldung zusammen.
+func (e *Error) In(locale string) string {
+	fields := e.Localized(locale)
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return fmt.Sprintf("%s: %s", i18n.T(locale, "validation.invalid_request"), strings.Join(messages, "; "))
+}
+
+// Unwrap ermöglicht errors.Is(err, ErrInvalidRequest).
+func (e *Error) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// validate ist die gemeinsame, threadsichere Validator-Instanz (cacht die Strukturinformationen).
+var validate = newValidator()
+
+// newValidator konfiguriert den Validator: Feldnamen aus den JSON-Tags und Regeln,
+// die mehrere Felder betreffen und sich nicht als Tag ausdrücken lassen.
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterStructValidation(validateCreatePassword, schemas.CreatePasswordRequest{})
+	v.RegisterStructValidation(validateUpdatePassword, schemas.UpdatePasswordRequest{})
+	return v
+}
+
+// Struct prüft die validate-Tags einer Struktur und liefert bei Verstößen einen *Error.
+func Struct(value interface{}) error {
+	err := validate.Struct(value)
+	if err == nil {
+		return nil
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	result := &Error{}
+	for _, fieldError := range fieldErrors {
+		result.add(fieldPath(fieldError.Namespace()), fieldError.Tag(), fieldError.Param(), message(fieldError))
+	}
+	return result
+}
+
+// DecodeJSON dekodiert genau ein JSON-Objekt in value. Unbekannte Felder, falsche Typen und
+// nachfolgende Daten werden abgelehnt; die Prüfung der validate-Tags erfolgt separat mit Struct.
+func DecodeJSON(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: nach dem JSON-Objekt folgen weitere Daten", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// decodeError übersetzt Fehler von encoding/json in Validierungsfehler.
+func decodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: leerer Anfragekörper", ErrInvalidRequest)
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("%w: ungültiges JSON an Position %d", ErrInvalidRequest, syntaxError.Offset)
+	case errors.As(err, &typeError):
+		return (&Error{}).add(jsonFieldPath(typeError.Field), "type", typeError.Type.String(),
+			i18n.Msg("validation.type", typeError.Type.String(), typeError.Value))
+	}
+	// encoding/json meldet unbekannte Felder nur als Text ("json: unknown field \"name\"")
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		return (&Error{}).add(strings.Trim(field, `"`), "unknown", "", i18n.Msg("validation.unknown"))
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+}
+
+// jsonFieldPath bringt den Feldpfad von encoding/json in die Form der Struct-Validierung
+// ("items.0.count" → "items[0].count"), damit Clients Fehler einheitlich zuordnen können.
+func jsonFieldPath(path string) string {
+	var result strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			result.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			result.WriteByte('.')
+		}
+		result.WriteString(part)
+	}
+	return result.String()
+}
+
+// fieldPath entfernt den Strukturnamen am Anfang des Namespace ("RegisterRequest.email" → "email").
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// message liefert die (noch unübersetzte) Beschreibung eines Regelverstoßes.
+func message(fieldError validator.FieldError) i18n.Message {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required", "required_with", "required_without":
+		return i18n.Msg("validation.required")
+	case "email":
+		return i18n.Msg("validation.email")
+	case "numeric":
+		return i18n.Msg("validation.numeric")
+	case "encrypted":
+		return i18n.Msg("validation.encrypted", param)
+	case "oneof":
+		return i18n.Msg("validation.oneof", strings.ReplaceAll(param, " ", ", "))
+	case "min", "max", "len":
+		switch fieldError.Kind() {
+		case reflect.String:
+			return i18n.Msg("validation."+fieldError.Tag()+".string", param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return i18n.Msg("validation."+fieldError.Tag()+".items", param)
+		default:
+			return i18n.Msg("validation."+fieldError.Tag()+".value", param)
+		}
+	}
+	return i18n.Msg("validation.rule", fieldError.Tag())
+}
+
+// encryptedField beschreibt die Felder eines verschlüsselten Wertes (JSON- und Go-Namen) für Fehlermeldungen.
+type encryptedField struct {
+	value, iv, tag           string
+	field, ivField, tagField string
+	name, ivName, tagName    string
+}
+
+// validateEncrypted prüft das Format eines gesetzten verschlüsselten Wertes (Base64, Algorithmus,
+// Länge von IV und Tag) und meldet Fehler am betroffenen Feld mit der Regel "encrypted".
+func validateEncrypted(sl validator.StructLevel, f encryptedField) {
+	_, err := schemas.ParseEncryptedField(f.value, f.iv, f.tag)
+	var encErr *schemas.EncryptedStringError
+	if !errors.As(err, &encErr) {
+		return
+	}
+	switch encErr.Part {
+	case schemas.EncPartIV:
+		sl.ReportError(f.iv, f.ivField, f.ivName, "encrypted", encErr.Reason)
+	case schemas.EncPartTag:
+		sl.ReportError(f.tag, f.tagField, f.tagName, "encrypted", encErr.Reason)
+	default:
+		sl.ReportError(f.value, f.field, f.name, "encrypted", encErr.Reason)
+	}
+}
+
+// validateCreatePassword prüft Regeln über mehrere Felder eines neuen Eintrags: Logins brauchen
+// verschlüsselten Benutzernamen und verschlüsseltes Passwort jeweils mit IV (entfällt in der
+// versionierten Darstellung). Ein leerer Tag ist zulässig, wenn er wie bei der Web Crypto API an den
+// Ciphertext angehängt ist. Alle gesetzten verschlüsselten Werte müssen gültig formatiert sein.
+func validateCreatePassword(sl validator.StructLevel) {
+	req := sl.Current().Interface().(schemas.CreatePasswordRequest)
+	username := encryptedField{req.EncryptedUsername, req.UsernameIV, req.UsernameTag,
+		"encrypted_username", "username_iv", "username_tag", "EncryptedUsername", "UsernameIV", "UsernameTag"}
+	password := encryptedField{req.EncryptedPassword, req.PasswordIV, req.PasswordTag,
+		"encrypted_password", "password_iv", "password_tag", "EncryptedPassword", "PasswordIV", "PasswordTag"}
+
+	reported := make(map[string]bool)
+	if req.Type == "" || req.Type == models.ItemTypeLogin {
+		for _, secret := range []encryptedField{username, password} {
+			if secret.value == "" && secret.tag == "" {
+				sl.ReportError(secret.value, secret.field, secret.name, "required", "")
+				reported[secret.field] = true
+			} else if secret.iv == "" && !schemas.IsVersionedEncryptedString(secret.value) {
+				sl.ReportError(secret.iv, secret.ivField, secret.ivName, "required", "")
+				reported[secret.field] = true
+			}
+		}
+	}
+
+	fields := []encryptedField{
+		{req.EncryptedURL, req.URLIV, req.URLTag, "encrypted_url", "url_iv", "url_tag", "EncryptedURL", "URLIV", "URLTag"},
+		username,
+		password,
+		{req.EncryptedNotes, req.NotesIV, req.NotesTag, "encrypted_notes", "notes_iv", "notes_tag", "EncryptedNotes", "NotesIV", "NotesTag"},
+		{req.EncryptedName, req.NameIV, req.NameTag, "encrypted_name", "name_iv", "name_tag", "EncryptedName", "NameIV", "NameTag"},
+		{req.EncryptedData, req.DataIV, req.DataTag, "encrypted_data", "data_iv", "data_tag", "EncryptedData", "DataIV", "DataTag"},
+	}
+	fields = append(fields, customFieldsEncrypted(req.Fields)...)
+	fields = append(fields, urisEncrypted(req.URIs)...)
+	for _, field := range fields {
+		// Fehlende Pflichtwerte wurden oben bereits gemeldet
+		if reported[field.field] {
+			continue
+		}
+		validateEncrypted(sl, field)
+	}
+}
+
+// validateUpdatePassword prüft, dass jeder neu übergebene Ciphertext einen IV mitbringt
+// (außer in der versionierten Darstellung) und gültig formatiert ist.
+func validateUpdatePassword(sl validator.StructLevel) {
+	req := sl.Current().Interface().(schemas.UpdatePasswordRequest)
+	pairs := []struct {
+		value, iv, tag *string
+		encryptedField
+	}{
+		{req.EncryptedURL, req.URLIV, req.URLTag, encryptedField{
+			field: "encrypted_url", ivField: "url_iv", tagField: "url_tag", name: "EncryptedURL", ivName: "URLIV", tagName: "URLTag"}},
+		{req.EncryptedUsername, req.UsernameIV, req.UsernameTag, encryptedField{
+			field: "encrypted_username", ivField: "username_iv", tagField: "username_tag", name: "EncryptedUsername", ivName: "UsernameIV", tagName: "UsernameTag"}},
+		{req.EncryptedPassword, req.PasswordIV, req.PasswordTag, encryptedField{
+			field: "encrypted_password", ivField: "password_iv", tagField: "password_tag", name: "EncryptedPassword", ivName: "PasswordIV", tagName: "PasswordTag"}},
+		{req.EncryptedNotes, req.NotesIV, req.NotesTag, encryptedField{
+			field: "encrypted_notes", ivField: "notes_iv", tagField: "notes_tag", name: "EncryptedNotes", ivName: "NotesIV", tagName: "NotesTag"}},
+		{req.EncryptedName, req.NameIV, req.NameTag, encryptedField{
+			field: "encrypted_name", ivField: "name_iv", tagField: "name_tag", name: "EncryptedName", ivName: "NameIV", tagName: "NameTag"}},
+		{req.EncryptedData, req.DataIV, req.DataTag, encryptedField{
+			field: "encrypted_data", ivField: "data_iv", tagField: "data_tag", name: "EncryptedData", ivName: "DataIV", tagName: "DataTag"}},
+	}
+	for _, pair := range pairs {
+		if pair.value == nil || *pair.value == "" {
+			continue
+		}
+		field := pair.encryptedField
+		field.value, field.iv, field.tag = *pair.value, deref(pair.iv), deref(pair.tag)
+		if field.iv == "" && !schemas.IsVersionedEncryptedString(field.value) {
+			sl.ReportError(pair.iv, field.ivField, field.ivName, "required", "")
+			continue
+		}
+		validateEncrypted(sl, field)
+	}
+
+	var fields []encryptedField
+	if req.Fields != nil {
+		fields = append(fields, customFieldsEncrypted(*req.Fields)...)
+	}
+	if req.URIs != nil {
+		fields = append(fields, urisEncrypted(*req.URIs)...)
+	}
+	for _, field := range fields {
+		validateEncrypted(sl, field)
+	}
+}
+
+// customFieldsEncrypted liefert die verschlüsselten Werte benutzerdefinierter Felder (Name und Wert).
+func customFieldsEncrypted(customFields []schemas.CustomFieldRequest) []encryptedField {
+	result := make([]encryptedField, 0, 2*len(customFields))
+	for i, f := range customFields {
+		prefix := fmt.Sprintf("fields[%d].", i)
+		namePrefix := fmt.Sprintf("Fields[%d].", i)
+		result = append(result,
+			encryptedField{f.EncryptedName, f.NameIV, f.NameTag,
+				prefix + "encrypted_name", prefix + "name_iv", prefix + "name_tag",
+				namePrefix + "EncryptedName", namePrefix + "NameIV", namePrefix + "NameTag"},
+			encryptedField{f.EncryptedValue, f.ValueIV, f.ValueTag,
+				prefix + "encrypted_value", prefix + "value_iv", prefix + "value_tag",
+				namePrefix + "EncryptedValue", namePrefix + "ValueIV", namePrefix + "ValueTag"},
+		)
+	}
+	return result
+}
+
+// urisEncrypted liefert die verschlüsselten URIs eines Eintrags.
+func urisEncrypted(uris []schemas.URIRequest) []encryptedField {
+	result := make([]encryptedField, 0, len(uris))
+	for i, u := range uris {
+		prefix := fmt.Sprintf("uris[%d].", i)
+		namePrefix := fmt.Sprintf("URIs[%d].", i)
+		result = append(result, encryptedField{u.EncryptedURI, u.URIIV, u.URITag,
+			prefix + "encrypted_uri", prefix + "uri_iv", prefix + "uri_tag",
+			namePrefix + "EncryptedURI", namePrefix + "URIIV", namePrefix + "URITag"})
+	}
+	return result
+}
+
+// deref liefert den Wert eines optionalen Strings oder "" für nil.
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
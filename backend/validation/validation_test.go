@@ -0,0 +1,270 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	"backend/models"
+	"backend/schemas"
+)
+
+// b64 kodiert n Bytes mit dem Wert fill als Standard-Base64.
+func b64(fill byte, n int) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, n))
+}
+
+// ptr liefert einen Zeiger auf value (für optionale Felder von Aktualisierungen).
+func ptr(value string) *string {
+	return &value
+}
+
+// violations liefert die gemeldeten Verstöße als "Feld/Regel" in Meldereihenfolge.
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Fehler = %v, erwartet *Error", err)
+	}
+	result := make([]string, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		result[i] = field.Field + "/" + field.Rule
+	}
+	return result
+}
+
+var (
+	iv         = b64(0x01, schemas.GCMIVSize)
+	ciphertext = b64(0x02, 5)
+	tag        = b64(0x03, schemas.GCMTagSize)
+	versioned  = "1." + iv + "|" + ciphertext + "|" + tag
+)
+
+func TestValidateCreatePassword(t *testing.T) {
+	tests := []struct {
+		name string
+		req  schemas.CreatePasswordRequest
+		want []string
+	}{
+		{
+			name: "gültiger Login",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+				EncryptedPassword: ciphertext, PasswordIV: iv, PasswordTag: tag,
+			},
+		},
+		{
+			name: "versionierte Darstellung ohne IV",
+			req:  schemas.CreatePasswordRequest{EncryptedUsername: versioned, EncryptedPassword: versioned},
+		},
+		{
+			name: "angehängter Tag",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: tag, UsernameIV: iv,
+				EncryptedPassword: tag, PasswordIV: iv,
+			},
+		},
+		{
+			// Ein fehlender Wert wird nur als required gemeldet, nicht zusätzlich als encrypted
+			name: "Passwort fehlt",
+			req: schemas.CreatePasswordRequest{
+				Type:              models.ItemTypeLogin,
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+			},
+			want: []string{"encrypted_password/required"},
+		},
+		{
+			name: "Benutzername und Passwort fehlen",
+			req:  schemas.CreatePasswordRequest{},
+			want: []string{"encrypted_username/required", "encrypted_password/required"},
+		},
+		{
+			// Ohne IV wird das Format des Wertes nicht mehr geprüft
+			name: "IV fehlt",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+				EncryptedPassword: "kein Base64", PasswordTag: tag,
+			},
+			want: []string{"password_iv/required"},
+		},
+		{
+			name: "ungültiger IV",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: ciphertext, UsernameIV: b64(0x01, 8), UsernameTag: tag,
+				EncryptedPassword: ciphertext, PasswordIV: iv, PasswordTag: tag,
+			},
+			want: []string{"username_iv/encrypted"},
+		},
+		{
+			name: "ungültiger Tag",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+				EncryptedPassword: ciphertext, PasswordIV: iv, PasswordTag: b64(0x03, 12),
+			},
+			want: []string{"password_tag/encrypted"},
+		},
+		{
+			name: "angehängter Tag zu kurz",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: ciphertext, UsernameIV: iv, UsernameTag: tag,
+				EncryptedPassword: ciphertext, PasswordIV: iv,
+			},
+			want: []string{"encrypted_password/encrypted"},
+		},
+		{
+			// Andere Eintragstypen brauchen weder Benutzername noch Passwort
+			name: "Notiz ohne Zugangsdaten",
+			req: schemas.CreatePasswordRequest{
+				Type:          models.ItemTypeNote,
+				EncryptedData: ciphertext, DataIV: iv, DataTag: tag, DataVersion: 1,
+			},
+		},
+		{
+			name: "Notiz mit ungültigen Notizen",
+			req: schemas.CreatePasswordRequest{
+				Type:          models.ItemTypeNote,
+				EncryptedData: ciphertext, DataIV: iv, DataTag: tag, DataVersion: 1,
+				EncryptedNotes: ciphertext, NotesIV: "!!", NotesTag: tag,
+			},
+			want: []string{"notes_iv/encrypted"},
+		},
+		{
+			name: "ungültiges benutzerdefiniertes Feld und ungültige URI",
+			req: schemas.CreatePasswordRequest{
+				EncryptedUsername: versioned, EncryptedPassword: versioned,
+				Fields: []schemas.CustomFieldRequest{{Type: "text", EncryptedName: ciphertext, NameIV: iv, NameTag: b64(0x03, 4)}},
+				URIs:   []schemas.URIRequest{{EncryptedURI: "2." + iv + "|" + ciphertext + "|" + tag}},
+			},
+			want: []string{"fields[0].name_tag/encrypted", "uris[0].encrypted_uri/encrypted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violations(t, Struct(&tt.req))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verstöße = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUpdatePassword(t *testing.T) {
+	tests := []struct {
+		name string
+		req  schemas.UpdatePasswordRequest
+		want []string
+	}{
+		{name: "leere Aktualisierung"},
+		{
+			name: "neues Passwort",
+			req:  schemas.UpdatePasswordRequest{EncryptedPassword: ptr(ciphertext), PasswordIV: ptr(iv), PasswordTag: ptr(tag)},
+		},
+		{
+			name: "versionierte Darstellung ohne IV",
+			req:  schemas.UpdatePasswordRequest{EncryptedNotes: ptr(versioned)},
+		},
+		{
+			// Ein leerer Wert entfernt das Feld und wird nicht geprüft
+			name: "leerer Wert",
+			req:  schemas.UpdatePasswordRequest{EncryptedNotes: ptr(""), NotesIV: ptr("!!")},
+		},
+		{
+			// Ohne IV wird das Format des Wertes nicht mehr geprüft
+			name: "IV fehlt",
+			req:  schemas.UpdatePasswordRequest{EncryptedPassword: ptr("kein Base64"), PasswordTag: ptr(tag)},
+			want: []string{"password_iv/required"},
+		},
+		{
+			name: "leerer IV",
+			req:  schemas.UpdatePasswordRequest{EncryptedUsername: ptr(ciphertext), UsernameIV: ptr(""), UsernameTag: ptr(tag)},
+			want: []string{"username_iv/required"},
+		},
+		{
+			name: "ungültiger Tag",
+			req:  schemas.UpdatePasswordRequest{EncryptedName: ptr(ciphertext), NameIV: ptr(iv), NameTag: ptr(b64(0x03, 8))},
+			want: []string{"name_tag/encrypted"},
+		},
+		{
+			name: "mehrere Fehler",
+			req: schemas.UpdatePasswordRequest{
+				EncryptedURL: ptr(ciphertext), URLTag: ptr(tag),
+				EncryptedData: ptr(ciphertext), DataIV: ptr(b64(0x01, 16)), DataTag: ptr(tag),
+			},
+			want: []string{"url_iv/required", "data_iv/encrypted"},
+		},
+		{
+			name: "ungültige URI",
+			req: schemas.UpdatePasswordRequest{
+				URIs: &[]schemas.URIRequest{{EncryptedURI: ciphertext, URIIV: iv, URITag: tag}, {EncryptedURI: ciphertext, URITag: tag}},
+			},
+			want: []string{"uris[1].uri_iv/encrypted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violations(t, Struct(&tt.req))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verstöße = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type item struct {
+		Count int `json:"count"`
+	}
+	type request struct {
+		Name  string `json:"name"`
+		Items []item `json:"items"`
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		want  []string // Erwartete Feldfehler als "Feld/Regel"
+		plain bool     // Fehler ohne Feldbezug (nur ErrInvalidRequest)
+	}{
+		{name: "gültig", body: `{"name":"a","items":[{"count":1}]}`},
+		{name: "nachfolgende Leerzeichen", body: "{\"name\":\"a\"}\n\t "},
+		{name: "unbekanntes Feld", body: `{"name":"a","extra":true}`, want: []string{"extra/unknown"}},
+		{name: "unbekanntes verschachteltes Feld", body: `{"items":[{"count":1,"size":2}]}`, want: []string{"size/unknown"}},
+		{name: "falscher Typ", body: `{"name":5}`, want: []string{"name/type"}},
+		{name: "falscher verschachtelter Typ", body: `{"items":[{"count":"1"}]}`, want: []string{"items[0].count/type"}},
+		{name: "falscher Typ in verschachtelter Liste", body: `{"items":[{"count":1},{"count":true}]}`, want: []string{"items[1].count/type"}},
+		{name: "Objekt statt Liste", body: `{"items":{}}`, want: []string{"items/type"}},
+		{name: "weiteres Objekt", body: `{"name":"a"}{"name":"b"}`, plain: true},
+		{name: "nachfolgende Daten", body: `{"name":"a"} x`, plain: true},
+		{name: "leer", body: ``, plain: true},
+		{name: "nur Leerzeichen", body: "  \n", plain: true},
+		{name: "ungültiges JSON", body: `{"name":}`, plain: true},
+		{name: "abgeschnitten", body: `{"name":"a"`, plain: true},
+		{name: "Liste statt Objekt", body: `[]`, want: []string{"/type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req request
+			err := DecodeJSON([]byte(tt.body), &req)
+			if tt.plain {
+				var validationErr *Error
+				if !errors.Is(err, ErrInvalidRequest) || errors.As(err, &validationErr) {
+					t.Fatalf("Fehler = %v, erwartet ErrInvalidRequest ohne Feldfehler", err)
+				}
+				return
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRequest) {
+				t.Fatalf("errors.Is(%v, ErrInvalidRequest) = false", err)
+			}
+			if got := violations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verstöße = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}